@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const activitySize = 50
+
+// activityRecord is one action handled by the plugin.
+type activityRecord struct {
+	At       time.Time
+	Action   string
+	Image    string
+	Cluster  string
+	Duration time.Duration
+	// Size is the size of a loaded image, when it is known.
+	Size int64
+	// Phases are the timings of operations made of several steps.
+	Phases string
+	Err    error
+}
+
+// activityLog keeps the most recent actions, successful or not. Actions and
+// renders run concurrently, so it is guarded by a mutex.
+type activityLog struct {
+	mu      sync.Mutex
+	records []activityRecord
+}
+
+func (l *activityLog) Add(record activityRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.records = append(l.records, record)
+	if len(l.records) > activitySize {
+		l.records = l.records[len(l.records)-activitySize:]
+	}
+}
+
+// List returns the records, most recent first.
+func (l *activityLog) List() []activityRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	records := make([]activityRecord, 0, len(l.records))
+	for n := len(l.records) - 1; n >= 0; n-- {
+		records = append(records, l.records[n])
+	}
+	return records
+}
+
+// newActivityRecord describes an action request. The image is whichever of
+// the payload keys the action uses, and copies record their target cluster.
+func newActivityRecord(request *service.ActionRequest, started time.Time, err error) activityRecord {
+	record := activityRecord{
+		At:       started,
+		Action:   request.ActionName[strings.LastIndex(request.ActionName, "/")+1:],
+		Cluster:  kindCluster,
+		Duration: time.Since(started),
+		Err:      err,
+	}
+	for _, key := range []string{"imageID", "source", "image", "path"} {
+		if value, _ := request.Payload.OptionalString(key); value != "" {
+			record.Image = value
+			break
+		}
+	}
+	if cluster, _ := request.Payload.OptionalString("cluster"); cluster != "" {
+		record.Cluster = cluster
+	}
+	return record
+}
+
+// recordJob returns a jobQueue Finished hook that adds the outcome of queued
+// operations to the log once the worker completes them, since the action
+// itself only records that the job was queued.
+func (i *imagePlugin) recordJob(name string) func(job queuedJob, err error) {
+	return func(job queuedJob, err error) {
+		i.activity.Add(jobRecord(name, job, err))
+	}
+}
+
+// recordPhasedJob is recordJob for operations that report their phases to
+// progress, so the single log entry carries each phase's timing.
+func (i *imagePlugin) recordPhasedJob(name string, progress *operationProgress) func(job queuedJob, err error) {
+	return func(job queuedJob, err error) {
+		record := jobRecord(name, job, err)
+		record.Phases = progress.PhaseTimings()
+		i.activity.Add(record)
+	}
+}
+
+func jobRecord(name string, job queuedJob, err error) activityRecord {
+	record := activityRecord{
+		At:       job.StartedAt,
+		Action:   name + " finished",
+		Image:    job.ImageID,
+		Cluster:  kindCluster,
+		Duration: time.Since(job.StartedAt),
+		Err:      err,
+	}
+	// Copies go to another cluster, named by the target.
+	if name == "copy" {
+		record.Cluster = job.Target
+	}
+	return record
+}
+
+// activityView renders the activity log. Octant has no collapsible component,
+// so it is its own tab of the overview.
+func activityView(records []activityRecord, stats *sessionStats) *component.FlexLayout {
+	table := component.NewTable("Recent Activity", "No actions yet",
+		component.NewTableCols("Time", "Action", "Image", "Cluster", "Duration", "Outcome"))
+	for _, record := range records {
+		outcome := component.NewText("OK")
+		outcome.SetStatus(component.TextStatusOK)
+		if record.Err != nil {
+			outcome = component.NewText(record.Err.Error())
+			outcome.SetStatus(component.TextStatusError)
+		}
+		duration := record.Duration.Round(time.Millisecond).String()
+		if record.Phases != "" {
+			duration += " (" + record.Phases + ")"
+		}
+		if record.Size > 0 && record.Duration > 0 {
+			rate := loadSample{Size: record.Size, Duration: record.Duration}.Rate()
+			duration += fmt.Sprintf(", %s at %.1f MB/s", formatBytes(record.Size), rate)
+		}
+		table.Add(component.TableRow{
+			"Time":     component.NewTimestamp(record.At),
+			"Action":   component.NewText(record.Action),
+			"Image":    component.NewText(record.Image),
+			"Cluster":  component.NewText(record.Cluster),
+			"Duration": component.NewText(duration),
+			"Outcome":  outcome,
+		})
+	}
+
+	layout := flexlayout.New()
+	statsSection := layout.AddSection()
+	statsSection.Add(stats.Summary(), component.WidthFull)
+	activitySection := layout.AddSection()
+	activitySection.Add(table, component.WidthFull)
+	view := layout.ToComponent("Recent Activity")
+	view.SetAccessor("activity")
+	return view
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// apiListen is the address of the read-only JSON API, set with
+// --api-listen. Empty disables it. Only loopback addresses are accepted.
+var apiListen string
+
+// checkAPIListen rejects an API address that isn't on the loopback
+// interface, since the API has no authentication.
+func checkAPIListen(addr string) error {
+	hostname, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("must be host:port, got %q", addr)
+	}
+	if !isLoopbackHost(hostname) {
+		return fmt.Errorf("must be a loopback address such as 127.0.0.1:0, got %q", addr)
+	}
+	return nil
+}
+
+// isLoopbackHost reports whether hostname is localhost or a loopback IP.
+func isLoopbackHost(hostname string) bool {
+	if hostname == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
+
+// apiHeader must be sent with every API request. Browsers only send custom
+// headers cross-origin after a preflight the API never answers, so a web
+// page the user visits can't make requests to it.
+const apiHeader = "X-Kind-Registry"
+
+// checkAPIRequest refuses requests a browser could have been tricked into
+// sending: a Host that isn't loopback, as with DNS rebinding, an Origin
+// other than the API itself, a method other than GET, or a missing
+// apiHeader. It returns the status to refuse with.
+func checkAPIRequest(r *http.Request) (int, error) {
+	hostname := r.Host
+	if h, _, err := net.SplitHostPort(r.Host); err == nil {
+		hostname = h
+	}
+	if !isLoopbackHost(hostname) {
+		return http.StatusForbidden, fmt.Errorf("host %q is not a loopback address", r.Host)
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			return http.StatusForbidden, fmt.Errorf("cross-origin requests are not allowed, got origin %q", origin)
+		}
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return http.StatusMethodNotAllowed, fmt.Errorf("only GET is allowed, got %s", r.Method)
+	}
+	if r.Header.Get(apiHeader) == "" {
+		return http.StatusForbidden, fmt.Errorf("send the %s header with any value, e.g. curl -H '%s: 1'", apiHeader, apiHeader)
+	}
+	return 0, nil
+}
+
+// guardAPI answers only the requests checkAPIRequest lets through.
+func guardAPI(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status, err := checkAPIRequest(r); err != nil {
+			writeJSON(w, status, nil, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// hostListing is the last successful host image listing, kept for the API.
+type hostListing struct {
+	mu      sync.Mutex
+	images  []dockerImage
+	checked time.Time
+}
+
+var lastHostListing = &hostListing{}
+
+// Record keeps images as the latest listing.
+func (l *hostListing) Record(images []dockerImage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.images, l.checked = images, time.Now()
+}
+
+// Get returns the latest listing and when it was made, zero when the host
+// images were never listed.
+func (l *hostListing) Get() ([]dockerImage, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.images, l.checked
+}
+
+// apiHostImages is the response of /images/docker.
+type apiHostImages struct {
+	Checked time.Time            `json:"checked"`
+	Runtime string               `json:"runtime"`
+	Images  []hostInventoryImage `json:"images"`
+}
+
+// apiKindImages is the response of /images/kind.
+type apiKindImages struct {
+	Checked time.Time            `json:"checked"`
+	Cluster string               `json:"cluster"`
+	Backend string               `json:"backend"`
+	Images  []kindInventoryImage `json:"images"`
+	// Nodes maps image IDs to the nodes that have the image.
+	Nodes map[string][]string `json:"nodes"`
+}
+
+// apiDiff is the response of /diff: the tagged host images by whether kind
+// has them. Stale tags are in kind, but as a different image than the host's,
+// so the host image was rebuilt since it was loaded.
+type apiDiff struct {
+	HostChecked time.Time `json:"hostChecked"`
+	KindChecked time.Time `json:"kindChecked"`
+	Cluster     string    `json:"cluster"`
+	Loaded      []string  `json:"loaded"`
+	NotLoaded   []string  `json:"notLoaded"`
+	Stale       []string  `json:"stale"`
+}
+
+// apiServer serves the cached listings as JSON for scripts. It never runs a
+// command: responses come from what the views last listed, and say when
+// that was.
+type apiServer struct {
+	plugin *imagePlugin
+	server *http.Server
+	// Address is where the API listens, for the diagnostics.
+	Address string
+}
+
+// startAPI listens on apiListen and serves the API in the background. It
+// returns nil when the API is disabled or can't listen.
+func (i *imagePlugin) startAPI() *apiServer {
+	if apiListen == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", apiListen)
+	if err != nil {
+		log.Printf("unable to start the JSON API: %s", err)
+		return nil
+	}
+	a := &apiServer{plugin: i, Address: "http://" + listener.Addr().String()}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/images/docker", a.hostImages)
+	mux.HandleFunc("/images/kind", a.kindImages)
+	mux.HandleFunc("/diff", a.diff)
+	a.server = &http.Server{Handler: guardAPI(mux), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := a.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("JSON API stopped: %s", err)
+		}
+	}()
+	log.Printf("serving the JSON API on %s", a.Address)
+	return a
+}
+
+// URL returns where the API listens, or empty when it is disabled.
+func (a *apiServer) URL() string {
+	if a == nil {
+		return ""
+	}
+	return a.Address
+}
+
+// Close stops the API, letting requests being answered finish.
+func (a *apiServer) Close() {
+	if a == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := a.server.Shutdown(ctx); err != nil {
+		log.Printf("unable to stop the JSON API: %s", err)
+	}
+}
+
+// writeJSON writes v, or an error object with status when err is set.
+func writeJSON(w http.ResponseWriter, status int, v interface{}, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		v = map[string]string{"error": err.Error()}
+	}
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Printf("unable to write API response: %s", err)
+	}
+}
+
+func (a *apiServer) hostImages(w http.ResponseWriter, r *http.Request) {
+	images, checked := lastHostListing.Get()
+	if checked.IsZero() {
+		writeJSON(w, http.StatusServiceUnavailable, nil, fmt.Errorf("%s images were not listed yet, open the plugin in Octant first", host.Name()))
+		return
+	}
+	kind := a.plugin.nodeImages.Cached()
+	grouped, tags := groupByID(images)
+	response := apiHostImages{Checked: checked.UTC(), Runtime: host.Name(), Images: []hostInventoryImage{}}
+	for _, image := range grouped {
+		entry := hostInventoryImage{ID: image.ID, Tags: tags[image.ID], Created: image.CreatedAt, Size: image.Size}
+		for _, tag := range entry.Tags {
+			if _, ok := findNormalized(kind.kindImages, tag); ok {
+				entry.LoadedIntoKind = true
+			}
+		}
+		response.Images = append(response.Images, entry)
+	}
+	writeJSON(w, http.StatusOK, response, nil)
+}
+
+// cachedKind returns the cached kind listing, or an HTTP status and error
+// when the cluster asked for isn't the cached one or nothing is cached.
+func (a *apiServer) cachedKind(r *http.Request) (clusterImages, int, error) {
+	if cluster := r.URL.Query().Get("cluster"); cluster != "" && cluster != kindCluster {
+		return clusterImages{}, http.StatusNotFound, fmt.Errorf("only the images of cluster %s are cached", kindCluster)
+	}
+	kind := a.plugin.nodeImages.Cached()
+	if kind.Checked.IsZero() {
+		return kind, http.StatusServiceUnavailable, fmt.Errorf("kind images were not listed yet, open the plugin in Octant first")
+	}
+	return kind, http.StatusOK, nil
+}
+
+func (a *apiServer) kindImages(w http.ResponseWriter, r *http.Request) {
+	kind, status, err := a.cachedKind(r)
+	if err != nil {
+		writeJSON(w, status, nil, err)
+		return
+	}
+	response := apiKindImages{
+		Checked: kind.Checked.UTC(),
+		Cluster: kindCluster,
+		Backend: kind.Backend,
+		Images:  []kindInventoryImage{},
+		Nodes:   kind.Nodes,
+	}
+	for _, image := range kind.Images {
+		response.Images = append(response.Images, kindInventoryImage{
+			ID:          image.ID,
+			RepoTags:    image.RepoTags,
+			RepoDigests: image.RepoDigests,
+			Size:        image.Size,
+		})
+	}
+	writeJSON(w, http.StatusOK, response, nil)
+}
+
+func (a *apiServer) diff(w http.ResponseWriter, r *http.Request) {
+	images, checked := lastHostListing.Get()
+	if checked.IsZero() {
+		writeJSON(w, http.StatusServiceUnavailable, nil, fmt.Errorf("%s images were not listed yet, open the plugin in Octant first", host.Name()))
+		return
+	}
+	kind, status, err := a.cachedKind(r)
+	if err != nil {
+		writeJSON(w, status, nil, err)
+		return
+	}
+	response := apiDiff{
+		HostChecked: checked.UTC(),
+		KindChecked: kind.Checked.UTC(),
+		Cluster:     kindCluster,
+		Loaded:      []string{},
+		NotLoaded:   []string{},
+		Stale:       []string{},
+	}
+	// The same rule as the Sync Status page and the navigation counts sorts
+	// the tags, only without hiding system images.
+	diff := diffImageSets(images, kind.kindImages, "", false)
+	sorted := map[string]bool{}
+	for _, image := range diff.OnlyHost {
+		response.NotLoaded = append(response.NotLoaded, image.Reference())
+		sorted[normalizeReference(image.Reference())] = true
+	}
+	for _, tag := range diff.Stale {
+		response.Stale = append(response.Stale, tag.Host.Reference())
+		sorted[normalizeReference(tag.Host.Reference())] = true
+	}
+	for _, image := range images {
+		ref := image.Reference()
+		if ref == image.ID || sorted[normalizeReference(ref)] {
+			continue
+		}
+		sorted[normalizeReference(ref)] = true
+		response.Loaded = append(response.Loaded, ref)
+	}
+	writeJSON(w, http.StatusOK, response, nil)
+}
+
+// findNormalized returns the kind image tagged ref, comparing normalized
+// references like notInKind does.
+func findNormalized(images kindImages, ref string) (kindImage, bool) {
+	want := normalizeReference(ref)
+	for _, image := range images.Images {
+		for _, repoTag := range image.RepoTags {
+			if normalizeReference(repoTag) == want {
+				return image, true
+			}
+		}
+	}
+	return kindImage{}, false
+}
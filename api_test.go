@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGuardAPI(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		host   string
+		header map[string]string
+		status int
+	}{
+		{
+			name:   "script",
+			method: http.MethodGet,
+			host:   "127.0.0.1:8123",
+			header: map[string]string{apiHeader: "1"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "localhost",
+			method: http.MethodGet,
+			host:   "localhost:8123",
+			header: map[string]string{apiHeader: "1"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "same origin",
+			method: http.MethodGet,
+			host:   "127.0.0.1:8123",
+			header: map[string]string{apiHeader: "1", "Origin": "http://127.0.0.1:8123"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "missing header",
+			method: http.MethodGet,
+			host:   "127.0.0.1:8123",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "rebound host",
+			method: http.MethodGet,
+			host:   "attacker.example:8123",
+			header: map[string]string{apiHeader: "1"},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "cross origin",
+			method: http.MethodGet,
+			host:   "127.0.0.1:8123",
+			header: map[string]string{apiHeader: "1", "Origin": "https://attacker.example"},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "post",
+			method: http.MethodPost,
+			host:   "127.0.0.1:8123",
+			header: map[string]string{apiHeader: "1"},
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	handler := guardAPI(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, "/diff", nil)
+			r.Host = test.host
+			for key, value := range test.header {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != test.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, test.status, w.Body.String())
+			}
+		})
+	}
+}
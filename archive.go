@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// expandPath resolves a leading ~/ to the home directory and makes path absolute.
+func expandPath(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	return filepath.Abs(path)
+}
+
+// checkArchive rejects paths that cannot be a docker save tarball before
+// shelling out to kind, and returns the path made absolute.
+func checkArchive(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("an archive path is required")
+	}
+	path, err := expandPath(path)
+	if err != nil {
+		return "", fmt.Errorf("checkArchive %s: %w", path, err)
+	}
+	if err := validatePayloadPath(path); err != nil {
+		return "", fmt.Errorf("checkArchive: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("checkArchive %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("checkArchive %s: is a directory, not an image archive", path)
+	}
+	if filepath.Ext(path) != ".tar" {
+		return "", fmt.Errorf("checkArchive %s: image archives must be .tar files created by docker save", path)
+	}
+	return path, nil
+}
+
+func (i *imagePlugin) loadArchive(ctx context.Context, path string) (err error) {
+	i.progress.Start(fmt.Sprintf("Loading archive %s into kind", path))
+	// kind load image-archive {{path}} --name {{kindCluster}}
+	cmd := exec.Command("kind", "load", "image-archive", path, "--name", kindCluster)
+	defer func() {
+		success := fmt.Sprintf("Loaded archive %s into kind in %s", path, i.progress.Elapsed())
+		if dryRun {
+			success = fmt.Sprintf("Dry run: would have run %s", commandLine(cmd))
+		}
+		i.progress.Finish(err, success)
+	}()
+
+	// The archive may have been removed since it was queued.
+	if _, err := checkArchive(path); err != nil {
+		return fmt.Errorf("loadArchive %s: %w", path, err)
+	}
+
+	if err := requireKind(kindLoadImageArchive); err != nil {
+		return fmt.Errorf("loadArchive %s: %w", path, err)
+	}
+	if dryRun {
+		log.Printf("dry run: %s", commandLine(cmd))
+		return nil
+	}
+	if err := streamCommand(ctx, cmd, i.progress.Write); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("loadArchive %s: %w", path, err)
+		}
+		return fmt.Errorf("loadArchive %s: %w: %s", path, err, strings.Join(i.progress.Output(), "\n"))
+	}
+
+	return nil
+}
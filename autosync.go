@@ -0,0 +1,182 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+var (
+	// autoSync starts the plugin with auto-sync on, set with
+	// KIND_REGISTRY_AUTO_SYNC. The UI toggle overrides it once saved.
+	autoSync = false
+
+	// autoSyncInterval is how often auto-sync compares the host and kind
+	// images, set with KIND_REGISTRY_AUTO_SYNC_INTERVAL.
+	autoSyncInterval = 15 * time.Second
+)
+
+// autoSyncer loads host images into kind as they are built or tagged. The
+// images missing from kind when it is turned on are left alone, so turning it
+// on never loads a host's whole image history; a rebuilt tag has a new image
+// ID and counts as new.
+type autoSyncer struct {
+	mu      sync.Mutex
+	enabled bool
+	since   time.Time
+	// seen holds the ref@ID of every image that was missing or stale when
+	// auto-sync was turned on, or that it has queued since.
+	seen map[string]bool
+	// baseline is set until the first check after turning it on has
+	// recorded the images to leave alone.
+	baseline bool
+	err      error
+}
+
+func newAutoSyncer(enabled bool) *autoSyncer {
+	s := &autoSyncer{}
+	if enabled {
+		s.Toggle()
+	}
+	return s
+}
+
+// Toggle turns auto-sync on or off. Turning it on starts from the images
+// present at that point.
+func (s *autoSyncer) Toggle() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.enabled = !s.enabled
+	s.since = time.Now()
+	s.seen = map[string]bool{}
+	s.baseline = s.enabled
+	s.err = nil
+}
+
+// Status reports whether auto-sync is on, since when, and the error of its
+// last check.
+func (s *autoSyncer) Status() (bool, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.enabled, s.since, s.err
+}
+
+// Run checks for new host images every autoSyncInterval until the plugin
+// stops.
+func (s *autoSyncer) Run(i *imagePlugin) {
+	ticker := time.NewTicker(autoSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-shutdownContext.Done():
+			return
+		case <-ticker.C:
+			s.check(i)
+		}
+	}
+}
+
+func (s *autoSyncer) check(i *imagePlugin) {
+	if enabled, _, _ := s.Status(); !enabled {
+		return
+	}
+	// Nothing can be loaded while the cluster is missing or stopped.
+	if !i.clusters.Exists() || i.health.Get().NodeState != "running" {
+		return
+	}
+	candidates, err := syncCandidates()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The toggle may have flipped while the images were listed.
+	if !s.enabled {
+		return
+	}
+	s.err = err
+	if err != nil {
+		return
+	}
+	if s.baseline {
+		for _, image := range candidates {
+			s.seen[image.Reference()+"@"+image.ID] = true
+		}
+		s.baseline = false
+		return
+	}
+	for _, image := range candidates {
+		key := image.Reference() + "@" + image.ID
+		if s.seen[key] {
+			continue
+		}
+		// A full queue or a load already queued by hand is retried on the
+		// next check, so auto-sync never goes past the queue size.
+		if err := i.queue.Enqueue(queuedJob{ImageID: image.Reference(), Auto: true}); err != nil {
+			log.Printf("auto-sync: %s", err)
+			continue
+		}
+		log.Printf("auto-sync queued %s for loading into kind", image.Reference())
+		s.seen[key] = true
+	}
+}
+
+// syncCandidates lists the tagged host images that are missing from kind, or
+// whose tag points to a different image in kind.
+func syncCandidates() ([]dockerImage, error) {
+	images, err := listDockerImages()
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := listKindImages()
+	if err != nil {
+		return nil, err
+	}
+	candidates := notInKind(images, loaded)
+	// ctr lists manifest digests, which can't be compared with docker image
+	// IDs, so rebuilt tags are only noticed with crictl.
+	if loaded.Backend == backendCtr {
+		return candidates, nil
+	}
+	for _, image := range images {
+		ref := image.Reference()
+		if ref == image.ID {
+			continue
+		}
+		if inKind, ok := loaded.Find(normalizeReference(ref)); ok && !sameImageID(inKind.ID, image.ID) {
+			candidates = append(candidates, image)
+		}
+	}
+	return candidates, nil
+}
+
+// autoSyncLabel is the text of the button that flips auto-sync.
+func autoSyncLabel(enabled bool) string {
+	if enabled {
+		return "Turn auto-sync off"
+	}
+	return "Turn auto-sync on"
+}
+
+// addAutoSyncSection explains what auto-sync is doing while it is on, and
+// adds the button that flips it.
+func (i *imagePlugin) addAutoSyncSection(layout *flexlayout.FlexLayout) {
+	enabled, since, err := i.autoSync.Status()
+	if enabled {
+		text := component.NewTextf("Auto-sync is on: host images built or tagged since %s are loaded into kind every %s. "+
+			"Auto-loads are listed on the Recent Activity tab.", since.Format(time.Kitchen), autoSyncInterval)
+		if err != nil {
+			text = component.NewTextf("Auto-sync is on but unable to compare images: %s", err)
+			text.SetStatus(component.TextStatusWarning)
+		}
+		section := layout.AddSection()
+		section.Add(text, component.WidthFull)
+	}
+	layout.AddButton(autoSyncLabel(enabled), action.Payload{
+		"action": names.AutoSync,
+	})
+}
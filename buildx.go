@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// buildxBuilder is a docker buildx builder instance.
+type buildxBuilder struct {
+	Name   string
+	Driver string
+}
+
+// Isolated reports whether the builder keeps what it builds in its own
+// cache. Only the docker driver builds into the docker image store; images
+// from the others need --load to show up in docker image ls.
+func (b buildxBuilder) Isolated() bool {
+	return b.Driver != "docker"
+}
+
+// buildxBuild is a build a get job runs with buildx --load before loading
+// the image into kind.
+type buildxBuild struct {
+	Builder    string
+	Context    string
+	Dockerfile string
+}
+
+// listBuildxBuilders returns the buildx builders. docker buildx ls prints a
+// line per builder followed by indented lines for its nodes, e.g.
+//
+//	NAME/NODE        DRIVER/ENDPOINT             STATUS  BUILDKIT PLATFORMS
+//	multi *          docker-container
+//	  multi0         unix:///var/run/docker.sock running v0.12.5  linux/amd64
+//	default          docker
+//	  default        default                     running v0.11.7  linux/amd64
+//
+// Newer releases draw the node lines with " \_ " instead.
+func listBuildxBuilders() ([]buildxBuilder, error) {
+	// docker buildx ls
+	cmd := exec.Command(host.Name(), "buildx", "ls")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, commandError(host.Name()+" buildx ls", err, stderr.String())
+	}
+
+	var builders []buildxBuilder
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(line, "NAME/NODE") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 1 && fields[1] == "*" {
+			fields = append(fields[:1], fields[2:]...)
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		builders = append(builders, buildxBuilder{Name: strings.TrimSuffix(fields[0], "*"), Driver: fields[1]})
+	}
+	return builders, nil
+}
+
+// buildxCache keeps the buildx builders for healthTTL, like the probes.
+// Only docker has buildx; other runtimes have no builders.
+type buildxCache struct {
+	mu       sync.Mutex
+	checked  time.Time
+	builders []buildxBuilder
+	err      error
+}
+
+// Get returns the builders whose builds don't land in the docker image
+// store.
+func (c *buildxCache) Get() ([]buildxBuilder, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if host.Name() != "docker" {
+		return nil, nil
+	}
+	if time.Since(c.checked) <= healthTTL {
+		return c.builders, c.err
+	}
+	c.checked = time.Now()
+	builders, err := listBuildxBuilders()
+	c.builders, c.err = nil, err
+	for _, builder := range builders {
+		if builder.Isolated() {
+			c.builders = append(c.builders, builder)
+		}
+	}
+	return c.builders, c.err
+}
+
+// Reset drops the cached builders, e.g. when a refresh is asked for.
+func (c *buildxCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checked = time.Time{}
+}
+
+// buildxHint explains why ref may be missing from docker when a buildx
+// builder keeps its builds to itself, or is empty otherwise.
+func (i *imagePlugin) buildxHint(ref string) string {
+	builders, _ := i.buildx.Get()
+	if len(builders) == 0 {
+		return ""
+	}
+	var described []string
+	for _, builder := range builders {
+		described = append(described, fmt.Sprintf("%s (%s driver)", builder.Name, builder.Driver))
+	}
+	return fmt.Sprintf("If %s was built with docker buildx builder %s without --load, it is only in the builder's cache, "+
+		"not in docker, and can't be loaded into kind. Rebuild it with --load, or with the Build and Load form.",
+		ref, strings.Join(described, ", "))
+}
+
+// withBuildxHint adds the buildx hint for ref to err, if there is one.
+func (i *imagePlugin) withBuildxHint(err error, ref string) error {
+	if hint := i.buildxHint(ref); hint != "" {
+		return fmt.Errorf("%w. %s", err, hint)
+	}
+	return err
+}
+
+// checkBuildContext checks the build context directory and Dockerfile of
+// the Build and Load form.
+func checkBuildContext(build buildxBuild) (buildxBuild, error) {
+	var err error
+	if build.Context, err = expandPath(strings.TrimSpace(build.Context)); err != nil {
+		return build, err
+	}
+	if err := validatePayloadPath(build.Context); err != nil {
+		return build, err
+	}
+	info, err := os.Stat(build.Context)
+	if err != nil {
+		return build, fmt.Errorf("build context: %w", err)
+	}
+	if !info.IsDir() {
+		return build, fmt.Errorf("build context %s is not a directory", build.Context)
+	}
+	if build.Dockerfile = strings.TrimSpace(build.Dockerfile); build.Dockerfile != "" {
+		if build.Dockerfile, err = expandPath(build.Dockerfile); err != nil {
+			return build, err
+		}
+		if err := validatePayloadPath(build.Dockerfile); err != nil {
+			return build, err
+		}
+	}
+	return build, nil
+}
+
+// buildxLoadCommand builds ref with builder and loads the result into the
+// docker image store. With the build cache still warm it only exports.
+func buildxLoadCommand(ref string, build buildxBuild) *exec.Cmd {
+	// docker buildx build --builder {{builder}} --load -t {{ref}} [-f {{dockerfile}}] {{context}}
+	args := []string{"buildx", "build", "--builder", build.Builder, "--load", "-t", ref}
+	if build.Dockerfile != "" {
+		args = append(args, "-f", build.Dockerfile)
+	}
+	return exec.Command(host.Name(), append(args, build.Context)...)
+}
+
+// buildImage runs a buildx build with --load and loads the image into kind,
+// as one get operation with a build and a load phase.
+func (i *imagePlugin) buildImage(ctx context.Context, job queuedJob) (err error) {
+	ref := job.ImageID
+
+	i.getProgress.Start(fmt.Sprintf("Building %s with %s and loading it into kind", ref, job.Build.Builder))
+	build := buildxLoadCommand(ref, *job.Build)
+	defer func() {
+		success := fmt.Sprintf("Built and loaded %s into %d node(s) in %s (%s)",
+			ref, i.getProgress.Nodes(), i.getProgress.Elapsed(), i.getProgress.PhaseTimings())
+		if dryRun {
+			success = fmt.Sprintf("Dry run: would have run %s and %s", commandLine(build), host.LoadCommand(ref))
+		}
+		i.getProgress.Finish(err, success)
+	}()
+
+	if dryRun {
+		log.Printf("dry run: %s && %s", commandLine(build), host.LoadCommand(ref))
+		return nil
+	}
+
+	i.getProgress.Phase("build")
+	if err := streamCommand(ctx, build, i.getProgress.Write); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("buildImage %s: build: %w", ref, err)
+		}
+		return fmt.Errorf("buildImage %s: build: %w: %s", ref, err, strings.Join(i.getProgress.Output(), "\n"))
+	}
+
+	i.getProgress.Phase("load")
+	if err := checkNodeCapacity(ref); err != nil {
+		return fmt.Errorf("buildImage %s: load: %w", ref, err)
+	}
+	if err := host.Load(ctx, ref, i.getProgress.Write); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("buildImage %s: load: %w", ref, err)
+		}
+		return fmt.Errorf("buildImage %s: load: %w: %s", ref, err, strings.Join(i.getProgress.Output(), "\n"))
+	}
+	return nil
+}
+
+// addBuildxSection warns that images built with an isolated builder are
+// missing from the table, with the form to rebuild one with --load.
+func addBuildxSection(layout *flexlayout.FlexLayout, builders []buildxBuilder, formErr error) {
+	if len(builders) == 0 {
+		return
+	}
+	var described []string
+	var choices []component.InputChoice
+	for n, builder := range builders {
+		described = append(described, fmt.Sprintf("%s (%s driver)", builder.Name, builder.Driver))
+		choices = append(choices, component.InputChoice{Label: builder.Name, Value: builder.Name, Checked: n == 0})
+	}
+	text := component.NewTextf("Images built with buildx builder %s without --load stay in the builder's cache "+
+		"and are not listed here. docker buildx du shows the cache, but it holds layers, not tagged images, "+
+		"so they can only be exported by building again with --load.", strings.Join(described, ", "))
+	text.SetStatus(component.TextStatusWarning)
+	section := layout.AddSection()
+	section.Add(text, component.WidthFull)
+
+	card := component.NewCard(component.TitleFromString("Build and Load"))
+	card.SetBody(component.NewText("Run docker buildx build --load for a build context and load the image into kind. " +
+		"With the builder's cache still warm the build only exports the cached result. Progress is shown on the Kind Images tab."))
+	card.AddAction(component.Action{
+		Name:  "Build",
+		Title: "Build with buildx and load into kind",
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.BuildxLoad),
+				component.NewFormFieldRadio("Builder", "builder", choices),
+				component.NewFormFieldText("Image", "image", ""),
+				component.NewFormFieldText("Build context", "context", ""),
+				component.NewFormFieldText("Dockerfile (optional)", "dockerfile", ""),
+			},
+		},
+		Modal: true,
+	})
+	if formErr != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, formErr.Error()))
+	}
+	formSection := layout.AddSection()
+	formSection.Add(card, component.WidthHalf)
+}
+
+// buildxLoadAction reads the Build and Load form.
+func (i *imagePlugin) buildxLoadAction(payload action.Payload) error {
+	ref, _ := payload.OptionalString("image")
+	builder, _ := payload.OptionalString("builder")
+	dir, _ := payload.OptionalString("context")
+	dockerfile, _ := payload.OptionalString("dockerfile")
+	ref = strings.TrimSpace(ref)
+
+	build := buildxBuild{Builder: builder, Context: dir, Dockerfile: dockerfile}
+	err := validateReference(ref)
+	if err == nil {
+		err = checkBuilder(i.buildx, builder)
+	}
+	if err == nil {
+		build, err = checkBuildContext(build)
+	}
+	i.buildError.Set(err)
+	if err != nil {
+		return err
+	}
+	if err := i.gets.Enqueue(queuedJob{ImageID: ref, Build: &build}); err != nil {
+		return err
+	}
+	log.Printf("queued %s for building with %s and loading into kind", ref, builder)
+	return nil
+}
+
+// checkBuilder rejects a builder that is not an isolated buildx builder.
+func checkBuilder(cache *buildxCache, name string) error {
+	builders, err := cache.Get()
+	if err != nil {
+		return err
+	}
+	for _, builder := range builders {
+		if builder.Name == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%q is not a buildx builder with its own cache", name)
+}
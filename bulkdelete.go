@@ -0,0 +1,474 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// bulkDeleteJob is the maintenance job that deletes the selected kind images.
+const bulkDeleteJob = "delete selected"
+
+// bulkDeleteWorkers is how many deletions of a bulk delete run at once.
+const bulkDeleteWorkers = 3
+
+// kindSelection holds the kind images picked for a bulk delete, by the
+// target their row's Delete would remove. Octant tables have no row
+// selection, so images are picked with a form and held by the plugin so the
+// selection survives refreshes.
+type kindSelection struct {
+	mu   sync.Mutex
+	refs []string
+}
+
+// Set replaces the selection; no refs clears it.
+func (s *kindSelection) Set(refs []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refs = append([]string(nil), refs...)
+}
+
+func (s *kindSelection) Get() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.refs...)
+}
+
+// selectImages stores the images picked in the selection form.
+func (i *imagePlugin) selectImages(payload action.Payload) error {
+	refs, _ := payload.StringSlice("images")
+	for _, ref := range refs {
+		if err := validateReference(ref); err != nil {
+			return err
+		}
+	}
+	i.selection.Set(refs)
+	return nil
+}
+
+// queueBulkDelete queues the deletion of the selected images and clears
+// the selection, whose images are listed by the job from then on.
+func (i *imagePlugin) queueBulkDelete() error {
+	refs := i.selection.Get()
+	if len(refs) == 0 {
+		return fmt.Errorf("select the kind images to delete first")
+	}
+	err := i.queueMaintenance(queuedJob{
+		ImageID: fmt.Sprintf("Deleting %d selected kind image(s)", len(refs)),
+		Target:  bulkDeleteJob,
+		Batch:   refs,
+	})
+	if err != nil {
+		return err
+	}
+	i.selection.Set(nil)
+	return nil
+}
+
+// bulkDeletion is one deletion of a bulk delete: an image, or one of its
+// tags, on one node.
+type bulkDeletion struct {
+	Node    string
+	Target  string
+	Refs    []string
+	Image   kindImage
+	Backend string
+	// Skipped is why the image is kept, empty when it is deleted.
+	Skipped string
+}
+
+// bulkDeleteResult is the outcome of a bulkDeletion.
+type bulkDeleteResult struct {
+	Node      string
+	Refs      []string
+	Skipped   string
+	Err       error
+	Reclaimed int64
+}
+
+func (r bulkDeleteResult) String() string {
+	refs := strings.Join(r.Refs, ", ")
+	switch {
+	case r.Node == "":
+		return fmt.Sprintf("skipped %s: %s", refs, r.Skipped)
+	case r.Skipped != "":
+		return fmt.Sprintf("skipped %s on %s: %s", refs, r.Node, r.Skipped)
+	case r.Err != nil:
+		return fmt.Sprintf("failed to delete %s on %s: %s", refs, r.Node, r.Err)
+	}
+	return fmt.Sprintf("deleted %s on %s, reclaiming %s", refs, r.Node, formatBytes(r.Reclaimed))
+}
+
+// skipReason returns why a bulk delete keeps image, or empty when target
+// may be deleted. Removing one of several tags leaves the image for its
+// containers, like deleteImage.
+func skipReason(target string, image kindImage, consumers map[string][]kindContainer) string {
+	if image.Pinned {
+		return "pinned by the kubelet, e.g. as the pod sandbox image"
+	}
+	if keepsOtherTags(target, image) {
+		return ""
+	}
+	users := consumers[image.ID]
+	for _, repoTag := range image.RepoTags {
+		users = append(users, consumers[repoTag]...)
+	}
+	if len(users) > 0 {
+		return fmt.Sprintf("in use by %d container(s), which Force delete on its row removes too: %s",
+			len(users), describeConsumers(users))
+	}
+	return ""
+}
+
+// planDeletions turns the selected refs found on a node into deletions.
+// Selecting every tag of an image deletes the image; selecting some of them
+// deletes only those tags.
+func planDeletions(node string, refs []string, images kindImages, consumers map[string][]kindContainer, found map[string]bool) []bulkDeletion {
+	var order []string
+	selected := map[string][]string{}
+	byID := map[string]kindImage{}
+	for _, ref := range refs {
+		image, ok := images.Find(ref)
+		if !ok {
+			continue
+		}
+		found[ref] = true
+		if _, ok := byID[image.ID]; !ok {
+			order = append(order, image.ID)
+		}
+		byID[image.ID] = image
+		selected[image.ID] = append(selected[image.ID], ref)
+	}
+
+	var deletions []bulkDeletion
+	for _, id := range order {
+		image, refs := byID[id], selected[id]
+		targets := refs
+		if wholeImage(image, refs) {
+			targets = []string{image.ID}
+		}
+		for _, target := range targets {
+			deletion := bulkDeletion{Node: node, Target: target, Refs: []string{target}, Image: image, Backend: images.Backend}
+			if target == image.ID {
+				deletion.Refs = refs
+			}
+			deletion.Skipped = skipReason(target, image, consumers)
+			deletions = append(deletions, deletion)
+		}
+	}
+	return deletions
+}
+
+// wholeImage reports whether refs select the image itself rather than some
+// of its tags.
+func wholeImage(image kindImage, refs []string) bool {
+	for _, ref := range refs {
+		if !contains(image.RepoTags, ref) {
+			return true
+		}
+	}
+	for _, repoTag := range image.RepoTags {
+		if !contains(refs, repoTag) {
+			return false
+		}
+	}
+	return true
+}
+
+// deleteSelected deletes the selected images from every node that has them,
+// bulkDeleteWorkers at a time. Pinned and in-use images are skipped rather
+// than failing the batch, and every image's outcome is kept for the report.
+func (i *imagePlugin) deleteSelected(ctx context.Context, refs []string) (err error) {
+	i.maintProgress.Start(fmt.Sprintf("Deleting %d selected kind image(s)", len(refs)))
+	var results []bulkDeleteResult
+	defer func() {
+		i.bulkDeletes.Set(results)
+		deleted, skipped, reclaimed, failed := summarizeBulkDelete(results)
+		success := fmt.Sprintf("Deleted %d selected image(s), reclaiming %s; skipped %d", deleted, formatBytes(reclaimed), skipped)
+		if dryRun {
+			success = fmt.Sprintf("Dry run: would have deleted %d selected image(s), reclaiming %s; skipped %d", deleted, formatBytes(reclaimed), skipped)
+		}
+		if err == nil && len(failed) > 0 {
+			err = fmt.Errorf("deleteSelected: deleted %d image(s), reclaiming %s, but could not delete %s",
+				deleted, formatBytes(reclaimed), strings.Join(failed, ", "))
+		}
+		i.maintProgress.Finish(err, success)
+	}()
+
+	nodes, err := listKindNodes()
+	if err != nil {
+		return fmt.Errorf("deleteSelected: %w", err)
+	}
+	found := map[string]bool{}
+	var deletions []bulkDeletion
+	for _, node := range nodes {
+		images, err := listNodeImages(node)
+		if err != nil {
+			return fmt.Errorf("deleteSelected %s: %w", node, err)
+		}
+		var consumers map[string][]kindContainer
+		if images.Backend == backendCtr {
+			log.Printf("warning: not checking whether the selected images are in use, crictl is not available on %s", node)
+		} else {
+			containers, err := crictlContainers(node, "ps", "--all", "--output=json")
+			if err != nil {
+				return fmt.Errorf("deleteSelected %s: %w", node, err)
+			}
+			consumers = imageConsumers(containers)
+		}
+		deletions = append(deletions, planDeletions(node, refs, images, consumers, found)...)
+	}
+	for _, ref := range refs {
+		if !found[ref] {
+			results = append(results, bulkDeleteResult{Refs: []string{ref}, Skipped: "no longer on any node"})
+		}
+	}
+
+	outcomes := make([]bulkDeleteResult, len(deletions))
+	workers := make(chan struct{}, bulkDeleteWorkers)
+	var wg sync.WaitGroup
+	for n, deletion := range deletions {
+		if deletion.Skipped == "" && ctx.Err() != nil {
+			deletion.Skipped = "stopped before it ran"
+		}
+		if deletion.Skipped != "" {
+			outcomes[n] = bulkDeleteResult{Node: deletion.Node, Refs: deletion.Refs, Skipped: deletion.Skipped}
+			i.maintProgress.Write(outcomes[n].String())
+			continue
+		}
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(n int, deletion bulkDeletion) {
+			defer wg.Done()
+			defer func() { <-workers }()
+			outcomes[n] = i.runDeletion(ctx, deletion)
+			i.maintProgress.Write(outcomes[n].String())
+		}(n, deletion)
+	}
+	wg.Wait()
+	results = append(results, outcomes...)
+
+	i.specs.Reset()
+	i.details.Reset()
+	i.nodeImages.Reset()
+	i.diskUsage.Reset()
+	i.strays.Reset()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("deleteSelected: %w", err)
+	}
+	return nil
+}
+
+// runDeletion deletes one image or tag from its node.
+func (i *imagePlugin) runDeletion(ctx context.Context, deletion bulkDeletion) bulkDeleteResult {
+	result := bulkDeleteResult{Node: deletion.Node, Refs: deletion.Refs}
+	// Only removing the image frees its layers.
+	if !keepsOtherTags(deletion.Target, deletion.Image) {
+		result.Reclaimed, _ = strconv.ParseInt(deletion.Image.Size, 10, 64)
+	}
+	cmd := nodeDeleteCommand(deletion.Node, deletion.Target, deletion.Image, deletion.Backend)
+	if dryRun {
+		log.Printf("dry run: %s", commandLine(cmd))
+		return result
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := runContext(ctx, cmd); err != nil {
+		result.Err = commandError("delete "+deletion.Target, err, stderr.String())
+		if isImageInUse(stderr.String()) {
+			result.Err = fmt.Errorf("still referenced by containers, use Force delete on its row: %s", strings.TrimSpace(stderr.String()))
+		}
+		result.Reclaimed = 0
+	}
+	i.stats.RecordDelete(result.Err)
+	return result
+}
+
+// summarizeBulkDelete counts the outcomes of a bulk delete and names the
+// failed deletions.
+func summarizeBulkDelete(results []bulkDeleteResult) (deleted, skipped int, reclaimed int64, failed []string) {
+	for _, r := range results {
+		switch {
+		case r.Skipped != "":
+			skipped++
+		case r.Err != nil:
+			failed = append(failed, fmt.Sprintf("%s on %s", strings.Join(r.Refs, ", "), r.Node))
+		default:
+			deleted++
+			reclaimed += r.Reclaimed
+		}
+	}
+	return deleted, skipped, reclaimed, failed
+}
+
+// bulkDeleteReport keeps the outcome of every image of the last bulk delete
+// while it is recent, like the status of an operation.
+type bulkDeleteReport struct {
+	mu       sync.Mutex
+	results  []bulkDeleteResult
+	finished time.Time
+}
+
+func (r *bulkDeleteReport) Set(results []bulkDeleteResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results, r.finished = results, time.Now()
+}
+
+// Get returns the outcomes of the last bulk delete, or nil once it is older
+// than progressRetention.
+func (r *bulkDeleteReport) Get() []bulkDeleteResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.finished) > progressRetention {
+		return nil
+	}
+	return r.results
+}
+
+// addBulkDeleteReportSection lists the outcome of every image of the last
+// bulk delete.
+func addBulkDeleteReportSection(layout *flexlayout.FlexLayout, results []bulkDeleteResult) {
+	if len(results) == 0 {
+		return
+	}
+	table := component.NewTable("Deleted Selection", "No images were selected",
+		component.NewTableCols("Image", "Node", "Result", "Reclaimed"))
+	for _, r := range results {
+		node, reclaimed := r.Node, "—"
+		if node == "" {
+			node = "—"
+		}
+		var outcome *component.Text
+		switch {
+		case r.Skipped != "":
+			outcome = component.NewTextf("Skipped: %s", r.Skipped)
+			outcome.SetStatus(component.TextStatusWarning)
+		case r.Err != nil:
+			outcome = component.NewTextf("Failed: %s", r.Err)
+			outcome.SetStatus(component.TextStatusError)
+		default:
+			outcome = component.NewText("Deleted")
+			if dryRun {
+				outcome = component.NewText("Dry run")
+			}
+			outcome.SetStatus(component.TextStatusOK)
+			reclaimed = formatBytes(r.Reclaimed)
+		}
+		table.Add(component.TableRow{
+			"Image":     component.NewText(strings.Join(r.Refs, ", ")),
+			"Node":      component.NewText(node),
+			"Result":    outcome,
+			"Reclaimed": component.NewText(reclaimed),
+		})
+	}
+	section := layout.AddSection()
+	section.Add(table, component.WidthFull)
+}
+
+// selectionChoice is a kind table row offered in the selection form.
+func selectionChoice(image kindImage, repoTag string, selected []string) component.InputChoice {
+	target := deleteTarget(image, repoTag)
+	label := image.Reference(repoTag)
+	if size, err := strconv.ParseInt(image.Size, 10, 64); err == nil {
+		label = fmt.Sprintf("%s (%s)", label, formatBytes(size))
+	}
+	if image.Pinned {
+		label += " (pinned)"
+	}
+	return component.InputChoice{Label: label, Value: target, Checked: contains(selected, target)}
+}
+
+// describeSelection groups the selected images by the nodes they would be
+// deleted from, and lists the ones that would be skipped and why, as
+// checked against the cached listing. The job checks every node again.
+func describeSelection(selected []string, images clusterImages, consumers map[string][]kindContainer) string {
+	byNode := map[string][]string{}
+	var skipped []string
+	for _, ref := range selected {
+		image, ok := images.Find(ref)
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("%s (no longer in kind)", ref))
+			continue
+		}
+		if reason := skipReason(ref, image, consumers); reason != "" {
+			skipped = append(skipped, fmt.Sprintf("%s (%s)", ref, reason))
+			continue
+		}
+		nodes := images.Nodes[image.ID]
+		if len(nodes) == 0 {
+			nodes = []string{kindNode}
+		}
+		for _, node := range nodes {
+			byNode[node] = append(byNode[node], ref)
+		}
+	}
+
+	var nodes []string
+	for node := range byNode {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	var parts []string
+	for _, node := range nodes {
+		parts = append(parts, fmt.Sprintf("From %s: %s.", node, strings.Join(byNode[node], ", ")))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "Nothing selected can be deleted.")
+	}
+	if len(skipped) > 0 {
+		parts = append(parts, fmt.Sprintf("Skipped: %s.", strings.Join(skipped, "; ")))
+	}
+	return strings.Join(parts, " ")
+}
+
+// addSelectionSection renders the form that selects kind images and, once
+// some are, the button that deletes them after one confirmation listing
+// everything it removes.
+func addSelectionSection(layout *flexlayout.FlexLayout, selected []string, choices []component.InputChoice,
+	images clusterImages, consumers map[string][]kindContainer) {
+	card := component.NewCard(component.TitleFromString("Select Kind Images"))
+	body := "Select kind images to delete them together."
+	if len(selected) > 0 {
+		body = fmt.Sprintf("%d image(s) selected. %s", len(selected), describeSelection(selected, images, consumers))
+	}
+	card.SetBody(component.NewText(body))
+	card.AddAction(component.Action{
+		Name:  "Select",
+		Title: "Select kind images",
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.Select),
+				component.NewFormFieldCheckBox("Images", "images", choices),
+			},
+		},
+		Modal: true,
+	})
+	section := layout.AddSection()
+	section.Add(card, component.WidthFull)
+
+	if len(selected) == 0 {
+		return
+	}
+	layout.AddButton(fmt.Sprintf("Delete selected (%d)", len(selected)), action.Payload{
+		"action": names.BulkDelete,
+	}, component.WithButtonConfirmation("Delete selected images?",
+		describeSelection(selected, images, consumers)+" Pods scheduled later must pull deleted images again. Do you want to continue?"))
+	layout.AddButton("Clear selection", action.Payload{
+		"action": names.Select,
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+// busyQueue is a queue whose jobs mark their image as busy.
+type busyQueue struct {
+	queue *jobQueue
+	// verb describes the running job, noun the queued ones.
+	verb string
+	noun string
+}
+
+// busyImages returns what each host image with a running or queued
+// operation is waiting for, keyed by the reference or ID the operation was
+// queued with, e.g. "loading into kind" or "queued for load (2 of 3)". It is
+// built from the queues on every render, so it can't disagree with them.
+func (i *imagePlugin) busyImages() map[string]string {
+	return busyJobs([]busyQueue{
+		{i.queue, "loading into kind", "load"},
+		{i.pushes, "pushing", "push"},
+		{i.saves, "saving", "save"},
+		{i.scanQueue, "scanning", "scan"},
+	}, false)
+}
+
+// busyKindImages is busyImages for the operations that change kind images,
+// keyed by normalized reference since kind lists docker.io/library/nginx
+// for a load of nginx.
+func (i *imagePlugin) busyKindImages() map[string]string {
+	return busyJobs([]busyQueue{
+		{i.queue, "loading into kind", "load"},
+		{i.gets, "getting into kind", "get"},
+		{i.pulls, "pulling", "pull"},
+	}, true)
+}
+
+func busyJobs(queues []busyQueue, normalize bool) map[string]string {
+	busy := map[string]string{}
+	key := func(ref string) string {
+		if normalize {
+			return normalizeReference(ref)
+		}
+		return ref
+	}
+	// Earlier queues win, a load is the most interesting thing to show.
+	for n := len(queues) - 1; n >= 0; n-- {
+		q := queues[n]
+		current, pending := q.queue.Snapshot()
+		for position, job := range pending {
+			busy[key(job.ImageID)] = fmt.Sprintf("queued for %s (%d of %d)", q.noun, position+1, len(pending))
+		}
+		if current != nil {
+			busy[key(current.ImageID)] = q.verb
+		}
+	}
+	return busy
+}
+
+// imageBusy returns the status of the first of refs that is busy.
+func imageBusy(busy map[string]string, refs ...string) (string, bool) {
+	for _, ref := range refs {
+		if status, ok := busy[ref]; ok {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+// markBusy shows status next to the reference of a table row.
+func markBusy(row component.TableRow, ref, status string) {
+	text := component.NewTextf("%s (%s…)", ref, status)
+	text.SetStatus(component.TextStatusWarning)
+	row["Reference"] = text
+}
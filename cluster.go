@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// addClusterStateSection explains why the kind cluster can't be listed and
+// offers the action that fixes it. It reports whether the cluster is usable.
+func (i *imagePlugin) addClusterStateSection(layout *flexlayout.FlexLayout, h health) bool {
+	// Jobs for other clusters are shown on the Kind Clusters tab.
+	if current, _ := i.clusterOps.Snapshot(); current != nil && current.Cluster.Name == kindCluster {
+		clusterSection := layout.AddSection()
+		clusterSection.Add(component.NewTextf("%s (%s elapsed)...", current.ImageID, i.setupProgress.Elapsed()), component.WidthFull)
+		if output := i.setupProgress.Output(); len(output) > 0 {
+			clusterSection.Add(component.NewCodeBlock(strings.Join(output, "\n")), component.WidthFull)
+		}
+		return false
+	}
+	addStatusSection(layout, i.setupProgress)
+
+	switch {
+	case h.DaemonErr != nil:
+		// The status summary already explains this.
+		return false
+	case h.NodeErr != nil && i.clusters.Exists() && i.clusterStarting():
+		addClusterStartingSection(layout, "is being created")
+		return false
+	case h.NodeErr != nil:
+		body := fmt.Sprintf("The kind cluster %s does not exist, so there are no kind images to show. "+
+			"Create it here or with kind create cluster --name %s.", kindCluster, kindCluster)
+		if clusters, err := listKindClusters(); err == nil && len(clusters) > 0 {
+			body += fmt.Sprintf(" Existing clusters: %s; select one with --kind-cluster.", strings.Join(clusters, ", "))
+		}
+		card := component.NewCard(component.TitleFromString("No Kind Cluster"))
+		card.SetBody(component.NewText(body))
+		clusterSection := layout.AddSection()
+		clusterSection.Add(card, component.WidthFull)
+		layout.AddButton("Create cluster", action.Payload{
+			"action": names.NewCluster,
+		}, component.WithButtonConfirmation("Create cluster",
+			fmt.Sprintf("Do you want to run kind create cluster --name %s? It takes a minute or two.", kindCluster)))
+		return false
+	case contains(startingStates, h.NodeState):
+		addClusterStartingSection(layout, "is starting")
+		return false
+	case h.NodeState != "running":
+		card := component.NewCard(component.TitleFromString("Kind Cluster Stopped"))
+		card.SetBody(component.NewTextf("The node %s of cluster %s is %s, so its images can't be listed. "+
+			"Start the node containers to use the cluster again.", kindNode, kindCluster, h.NodeState))
+		clusterSection := layout.AddSection()
+		clusterSection.Add(card, component.WidthFull)
+		layout.AddButton("Start cluster containers", action.Payload{
+			"action": names.StartCluster,
+		})
+		return false
+	}
+	return true
+}
+
+// clusterStarting reports whether kind knows the cluster but its control
+// plane node is not there yet, which is the case while kind create cluster
+// runs elsewhere.
+func (i *imagePlugin) clusterStarting() bool {
+	clusters, polled := i.clusters.Clusters()
+	if !polled || !contains(clusters, kindCluster) {
+		return false
+	}
+	nodes, err := listKindNodes()
+	return err == nil && !contains(nodes, kindNode)
+}
+
+// addClusterStartingSection explains that the cluster will be usable shortly
+// instead of showing the errors of listing a node that is not ready.
+func addClusterStartingSection(layout *flexlayout.FlexLayout, state string) {
+	text := component.NewTextf("Kind cluster %s %s… Its images are listed once %s is running.", kindCluster, state, kindNode)
+	text.SetStatus(component.TextStatusWarning)
+	clusterSection := layout.AddSection()
+	clusterSection.Add(text, component.WidthFull)
+}
+
+// createCluster creates a kind cluster, by default the configured one. It
+// runs on the cluster queue since it takes a while.
+func (i *imagePlugin) createCluster(ctx context.Context, job queuedJob) (err error) {
+	spec := *job.Cluster
+	i.setupProgress.Start(job.ImageID)
+	defer func() {
+		i.setupProgress.Finish(err, fmt.Sprintf("Created kind cluster %s in %s", spec.Name, i.setupProgress.Elapsed()))
+		i.health.Reset()
+		// Picks up the new cluster now rather than on the next poll.
+		i.clusters.poll()
+	}()
+
+	config := spec.Config
+	if spec.Workers > 0 {
+		file, err := ioutil.TempFile("", "kind-config-*.yaml")
+		if err != nil {
+			return fmt.Errorf("createCluster %s: %w", spec.Name, err)
+		}
+		defer os.Remove(file.Name())
+		_, err = file.WriteString(workersConfig(spec.Workers))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("createCluster %s: %w", spec.Name, err)
+		}
+		config = file.Name()
+	}
+	cmd := createClusterCommand(spec, config)
+	if dryRun {
+		i.setupProgress.Write(fmt.Sprintf("Dry run: would have run %s", commandLine(cmd)))
+		return nil
+	}
+	if err := streamCommand(ctx, cmd, i.setupProgress.Write); err != nil {
+		return fmt.Errorf("createCluster %s: %w", spec.Name, err)
+	}
+	return nil
+}
+
+// startCluster starts the stopped node containers of the configured cluster.
+func (i *imagePlugin) startCluster(ctx context.Context, job queuedJob) (err error) {
+	i.setupProgress.Start(job.ImageID)
+	defer func() {
+		i.setupProgress.Finish(err, fmt.Sprintf("Started kind cluster %s in %s", kindCluster, i.setupProgress.Elapsed()))
+		i.health.Reset()
+	}()
+
+	nodes, err := listKindNodes()
+	if err != nil {
+		return fmt.Errorf("startCluster %s: %w", kindCluster, err)
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("startCluster %s: the cluster has no node containers", kindCluster)
+	}
+
+	// docker start {{nodes}}
+	cmd := exec.Command(nodeRuntime(), append([]string{"start"}, nodes...)...)
+	if dryRun {
+		i.setupProgress.Write(fmt.Sprintf("Dry run: would have run %s", commandLine(cmd)))
+		return nil
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := runContext(ctx, cmd); err != nil {
+		return fmt.Errorf("startCluster %s: %w: %s", kindCluster, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// refreshKind forgets everything cached about the kind node and queries it
+// again, retrying while containerd comes back up, e.g. after the node
+// container was restarted.
+func (i *imagePlugin) refreshKind() error {
+	i.specs.Reset()
+	i.details.Reset()
+	i.nodeImages.Reset()
+	i.health.Reset()
+	i.diskUsage.Reset()
+	i.strays.Reset()
+
+	err := withRetry("refreshKind", func() error {
+		// docker exec {{kindNode}} crictl info
+		cmd := crictlCommand(kindNode, "info")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := runCommand(cmd); err != nil {
+			return commandError("crictl info", err, stderr.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("refreshKind %s: %w", kindNode, err)
+	}
+	_, err = listKindImages()
+	return err
+}
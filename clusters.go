@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const clustersPath = "clusters"
+
+// clusterNamePattern matches the cluster names kind accepts.
+var clusterNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+
+// maxClusterWorkers bounds the workers the create form accepts, since every
+// node is a container running its own kubelet.
+const maxClusterWorkers = 10
+
+// clusterSpec is a cluster to create or delete on the cluster queue.
+type clusterSpec struct {
+	Name string
+	// Image is the kindest/node image, empty for the kind release's default.
+	Image   string
+	Workers int
+	// Config is the path of a kind config file, which then sets the nodes.
+	Config string
+}
+
+// clusterSpecFromPayload reads and checks the create cluster form. An empty
+// name is the configured cluster, and a bare version such as v1.27.3 is a
+// kindest/node tag.
+func (i *imagePlugin) clusterSpecFromPayload(payload action.Payload) (clusterSpec, error) {
+	name, _ := payload.OptionalString("name")
+	image, _ := payload.OptionalString("image")
+	workers, _ := payload.OptionalString("workers")
+	config, _ := payload.OptionalString("config")
+	spec := clusterSpec{
+		Name:   strings.TrimSpace(name),
+		Image:  strings.TrimSpace(image),
+		Config: strings.TrimSpace(config),
+	}
+	if spec.Name == "" {
+		spec.Name = kindCluster
+	}
+	if !clusterNamePattern.MatchString(spec.Name) {
+		return spec, fmt.Errorf("%q is not a valid cluster name, use lowercase letters, digits, dots and dashes", spec.Name)
+	}
+	if clusters, _ := i.clusters.Clusters(); contains(clusters, spec.Name) {
+		return spec, fmt.Errorf("kind cluster %s already exists", spec.Name)
+	}
+	if strings.HasPrefix(spec.Image, "v") && !strings.ContainsAny(spec.Image, "/:") {
+		spec.Image = "kindest/node:" + spec.Image
+	}
+	if spec.Image != "" {
+		if err := validateReference(spec.Image); err != nil {
+			return spec, err
+		}
+	}
+	if workers = strings.TrimSpace(workers); workers != "" {
+		n, err := strconv.Atoi(workers)
+		if err != nil || n < 0 || n > maxClusterWorkers {
+			return spec, fmt.Errorf("workers must be a number from 0 to %d, got %q", maxClusterWorkers, workers)
+		}
+		spec.Workers = n
+	}
+	if spec.Config != "" {
+		if err := validatePayloadPath(spec.Config); err != nil {
+			return spec, err
+		}
+		if spec.Workers > 0 {
+			return spec, fmt.Errorf("set the workers in %s, kind ignores the worker count when a config file is used", spec.Config)
+		}
+		info, err := os.Stat(spec.Config)
+		if err != nil {
+			return spec, fmt.Errorf("config file: %w", err)
+		}
+		if info.IsDir() {
+			return spec, fmt.Errorf("config file %s is a directory", spec.Config)
+		}
+	}
+	return spec, nil
+}
+
+// workersConfig is a kind config with a control plane and n workers, for
+// clusters created without a config file of their own.
+func workersConfig(n int) string {
+	config := "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n- role: control-plane\n"
+	for w := 0; w < n; w++ {
+		config += "- role: worker\n"
+	}
+	return config
+}
+
+// createClusterCommand creates the cluster of spec with the given config file.
+func createClusterCommand(spec clusterSpec, config string) *exec.Cmd {
+	// kind create cluster --name {{name}} --image {{image}} --config {{config}}
+	args := []string{"create", "cluster", "--name", spec.Name}
+	if spec.Image != "" {
+		args = append(args, "--image", spec.Image)
+	}
+	if config != "" {
+		args = append(args, "--config", config)
+	}
+	return exec.Command("kind", args...)
+}
+
+// deleteCluster deletes a kind cluster. It runs on the cluster queue so it
+// never overlaps with creating the same cluster.
+func (i *imagePlugin) deleteCluster(ctx context.Context, job queuedJob) (err error) {
+	name := job.Cluster.Name
+	i.setupProgress.Start(job.ImageID)
+	defer func() {
+		i.setupProgress.Finish(err, fmt.Sprintf("Deleted kind cluster %s in %s", name, i.setupProgress.Elapsed()))
+		i.health.Reset()
+		// Picks up the deletion now rather than on the next poll.
+		i.clusters.poll()
+	}()
+
+	// kind delete cluster --name {{name}}
+	cmd := exec.Command("kind", "delete", "cluster", "--name", name)
+	if dryRun {
+		i.setupProgress.Write(fmt.Sprintf("Dry run: would have run %s", commandLine(cmd)))
+		return nil
+	}
+	if err := streamCommand(ctx, cmd, i.setupProgress.Write); err != nil {
+		return fmt.Errorf("deleteCluster %s: %w", name, err)
+	}
+	return nil
+}
+
+// queueDeleteCluster checks a delete cluster request and queues it.
+func (i *imagePlugin) queueDeleteCluster(name string) error {
+	clusters, _ := i.clusters.Clusters()
+	if !contains(clusters, name) {
+		return fmt.Errorf("%q is not a kind cluster", name)
+	}
+	if name == kindCluster {
+		for _, q := range []*jobQueue{i.queue, i.gets, i.pulls, i.maintenance} {
+			if q.Busy() {
+				return fmt.Errorf("a %s into kind cluster %s is running, cancel it or wait for it before deleting the cluster", q.name, name)
+			}
+		}
+	}
+	return i.clusterOps.Enqueue(queuedJob{ImageID: "Deleting kind cluster " + name, Target: "delete", Cluster: &clusterSpec{Name: name}})
+}
+
+// deleteClusterWarning lists what deleting a cluster destroys.
+func (i *imagePlugin) deleteClusterWarning(cluster string, nodes []string) string {
+	body := fmt.Sprintf("This deletes kind cluster %s: ", cluster)
+	if len(nodes) > 0 {
+		body += fmt.Sprintf("its node containers %s, with every pod, volume and image on them, ", strings.Join(nodes, ", "))
+	} else {
+		body += "its node containers, with every pod, volume and image on them, "
+	}
+	body += fmt.Sprintf("and the kind-%s context of your kubeconfig.", cluster)
+	if cluster == kindCluster {
+		images := i.nodeImages.List(nodes)
+		body += fmt.Sprintf(" It is the cluster this plugin manages: the %d image(s) loaded into it are lost "+
+			"and the Kind Images tab stays empty until it is created again.", len(images.Images))
+	}
+	return body + " Do you want to continue?"
+}
+
+// clustersView lists the kind clusters, with the form to create one and an
+// action to delete each.
+func (i *imagePlugin) clustersView() *component.FlexLayout {
+	layout := flexlayout.New()
+	if current, pending := i.clusterOps.Snapshot(); current != nil {
+		section := layout.AddSection()
+		section.Add(component.NewTextf("%s (%s elapsed, %d queued)...",
+			current.ImageID, time.Since(current.StartedAt).Round(time.Second), len(pending)), component.WidthFull)
+		if output := i.setupProgress.Output(); len(output) > 0 {
+			section.Add(component.NewCodeBlock(strings.Join(output, "\n")), component.WidthFull)
+		}
+	} else {
+		addStatusSection(layout, i.setupProgress)
+	}
+
+	table := component.NewTable("Kind Clusters", "No kind clusters", component.NewTableCols("Name", "Nodes"))
+	clusters, _ := i.clusters.Clusters()
+	for _, cluster := range clusters {
+		nodes := i.clusters.Nodes(cluster)
+		name := component.NewText(cluster)
+		if cluster == kindCluster {
+			name = component.NewTextf("%s (managed by this plugin)", cluster)
+		}
+		var described []string
+		for _, node := range nodes {
+			if image := i.clusters.NodeImage(node); image != "" {
+				node = fmt.Sprintf("%s (%s)", node, nodeImageName(image))
+			}
+			described = append(described, node)
+		}
+		row := component.TableRow{
+			"Name":  name,
+			"Nodes": component.NewText(strings.Join(described, ", ")),
+		}
+		row.AddAction(component.GridAction{
+			Name:       "Delete",
+			ActionPath: names.Teardown,
+			Payload: action.Payload{
+				"action":  names.Teardown,
+				"cluster": cluster,
+			},
+			Confirmation: &component.Confirmation{
+				Title: "Delete cluster?",
+				Body:  i.deleteClusterWarning(cluster, nodes),
+			},
+			Type: component.GridActionDanger,
+		})
+		table.Add(row)
+	}
+	tableSection := layout.AddSection()
+	tableSection.Add(table, component.WidthFull)
+
+	formSection := layout.AddSection()
+	formSection.Add(createClusterCard(i.clusterError.Get(), contains(clusters, kindCluster)), component.WidthHalf)
+
+	view := layout.ToComponent("Kind Clusters")
+	view.SetAccessor(clustersPath)
+	return view
+}
+
+// createClusterCard renders the create cluster form. The name defaults to
+// the configured cluster while it doesn't exist.
+func createClusterCard(err error, exists bool) *component.Card {
+	name := kindCluster
+	if exists {
+		name = ""
+	}
+	card := component.NewCard(component.TitleFromString("Create Cluster"))
+	card.SetBody(component.NewText("Run kind create cluster. Leave the node image empty for the default of the installed kind, " +
+		"or give a kindest/node tag such as v1.27.3. Workers need no config file; a config file sets the nodes itself. " +
+		"Creating a cluster takes a minute or two; its progress is shown here."))
+	card.AddAction(component.Action{
+		Name:  "Create",
+		Title: "Create kind cluster",
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.NewCluster),
+				component.NewFormFieldText("Name", "name", name),
+				component.NewFormFieldText("Node image", "image", ""),
+				component.NewFormFieldText("Workers", "workers", "0"),
+				component.NewFormFieldText("Config file", "config", ""),
+			},
+		},
+		Modal: true,
+	})
+	if err != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, err.Error()))
+	}
+	return card
+}
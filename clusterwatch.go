@@ -0,0 +1,141 @@
+package main
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clusterPollInterval is how often the set of kind clusters is refreshed.
+const clusterPollInterval = 10 * time.Second
+
+// startingStates are the node container states of a cluster that is being
+// created or restarted rather than stopped.
+var startingStates = []string{"created", "restarting"}
+
+// clusterWatcher keeps the names of the kind clusters up to date, so that a
+// cluster deleted or created while Octant runs shows up on the next render.
+type clusterWatcher struct {
+	mu       sync.Mutex
+	clusters []string
+	polled   bool
+	// Changed is called with the clusters that appeared and disappeared.
+	Changed func(added, removed []string)
+	// nodes maps each cluster seen by the last poll to its node containers.
+	nodes map[string][]string
+	// images maps node containers to the node image they run.
+	images map[string]string
+}
+
+// Run polls kind get clusters. It never returns.
+func (w *clusterWatcher) Run() {
+	for {
+		w.poll()
+		time.Sleep(clusterPollInterval)
+	}
+}
+
+func (w *clusterWatcher) poll() {
+	clusters, err := listKindClusters()
+	if err != nil {
+		// Keep the last known clusters; kind or docker may be briefly unavailable.
+		log.Printf("unable to list kind clusters: %s", err)
+		return
+	}
+	sort.Strings(clusters)
+	nodes := map[string][]string{}
+	var all []string
+	for _, cluster := range clusters {
+		if nodes[cluster], err = listClusterNodes(cluster); err != nil {
+			log.Printf("unable to list nodes of kind cluster %s: %s", cluster, err)
+		}
+		all = append(all, nodes[cluster]...)
+	}
+	images := map[string]string{}
+	if len(all) > 0 {
+		if images, err = inspectNodeImages(all); err != nil {
+			log.Printf("unable to inspect kind nodes: %s", err)
+		}
+	}
+
+	w.mu.Lock()
+	added := difference(clusters, w.clusters)
+	removed := difference(w.clusters, clusters)
+	first := !w.polled
+	w.clusters = clusters
+	w.nodes = nodes
+	w.images = images
+	w.polled = true
+	w.mu.Unlock()
+
+	if !first && (len(added) > 0 || len(removed) > 0) && w.Changed != nil {
+		w.Changed(added, removed)
+	}
+}
+
+// Clusters returns the kind clusters seen by the last poll, and whether
+// there has been one yet.
+func (w *clusterWatcher) Clusters() ([]string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	clusters := make([]string, len(w.clusters))
+	copy(clusters, w.clusters)
+	return clusters, w.polled
+}
+
+// Nodes returns the node containers of a cluster seen by the last poll.
+func (w *clusterWatcher) Nodes(cluster string) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]string(nil), w.nodes[cluster]...)
+}
+
+// NodeImage returns the node image a node container runs, as seen by the
+// last poll.
+func (w *clusterWatcher) NodeImage(node string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.images[node]
+}
+
+// Exists reports whether the configured cluster was seen by the last poll.
+// Before the first poll it is assumed to exist.
+func (w *clusterWatcher) Exists() bool {
+	clusters, polled := w.Clusters()
+	return !polled || contains(clusters, kindCluster)
+}
+
+// difference returns the values of a that are not in b.
+func difference(a, b []string) []string {
+	var values []string
+	for _, value := range a {
+		if !contains(b, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// clustersChanged drops everything cached about the kind node, since a
+// recreated cluster has different images under the same node name.
+func (i *imagePlugin) clustersChanged(added, removed []string) {
+	if len(added) > 0 {
+		log.Printf("kind clusters created: %s", strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		log.Printf("kind clusters deleted: %s", strings.Join(removed, ", "))
+	}
+	i.specs.Reset()
+	i.details.Reset()
+	i.nodeImages.Reset()
+	i.health.Reset()
+	i.diskUsage.Reset()
+	i.strays.Reset()
+	i.registries.Reset()
+	i.digests.Reset()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+// overviewViews are the views of the overview page that list images, which
+// concurrent renders share.
+type overviewViews struct {
+	Docker *component.FlexLayout
+	Kind   *component.FlexLayout
+}
+
+// overviewCall is a build of the overview views that renders wait on.
+type overviewCall struct {
+	done  chan struct{}
+	views overviewViews
+}
+
+// overviewFlight coalesces concurrent renders of the overview. Each open
+// Octant tab renders on its own schedule, and without it every one of them
+// lists the images with its own docker exec calls.
+type overviewFlight struct {
+	mu    sync.Mutex
+	calls map[string]*overviewCall
+}
+
+func newOverviewFlight() *overviewFlight {
+	return &overviewFlight{calls: map[string]*overviewCall{}}
+}
+
+// Do returns the views for key, the cluster they list, building them with
+// build unless another render of key already is, in which case its result
+// is shared. build runs detached from ctx: a render that gives up returns
+// ctx's error but leaves the build running for the others waiting on it.
+func (f *overviewFlight) Do(ctx context.Context, key string, build func() overviewViews) (overviewViews, error) {
+	f.mu.Lock()
+	call, ok := f.calls[key]
+	if !ok {
+		call = &overviewCall{done: make(chan struct{})}
+		f.calls[key] = call
+		go func() {
+			call.views = build()
+			f.mu.Lock()
+			delete(f.calls, key)
+			f.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.views, nil
+	case <-ctx.Done():
+		return overviewViews{}, ctx.Err()
+	}
+}
+
+// detachedRequest is a request whose context no render owns, for work that
+// renders share.
+type detachedRequest struct {
+	service.Request
+	ctx context.Context
+}
+
+func (r detachedRequest) Context() context.Context {
+	return r.ctx
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+// waitingContext tells when a render started waiting on it, which Do does
+// after joining or starting the build.
+type waitingContext struct {
+	context.Context
+	once    sync.Once
+	waiting chan struct{}
+}
+
+func (c *waitingContext) Done() <-chan struct{} {
+	c.once.Do(func() { close(c.waiting) })
+	return c.Context.Done()
+}
+
+func TestOverviewFlightCancelledWaiterLeavesBuildRunning(t *testing.T) {
+	flight := newOverviewFlight()
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	builds := 0
+	want := overviewViews{Docker: component.NewFlexLayout("Docker")}
+	build := func() overviewViews {
+		builds++
+		close(started)
+		<-finish
+		return want
+	}
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := flight.Do(firstCtx, "kind", build)
+		firstErr <- err
+	}()
+	<-started
+
+	type result struct {
+		views overviewViews
+		err   error
+	}
+	second := make(chan result, 1)
+	secondCtx := &waitingContext{Context: context.Background(), waiting: make(chan struct{})}
+	go func() {
+		views, err := flight.Do(secondCtx, "kind", build)
+		second <- result{views, err}
+	}()
+	<-secondCtx.waiting
+
+	cancelFirst()
+	select {
+	case err := <-firstErr:
+		if err != context.Canceled {
+			t.Fatalf("cancelled render returned %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled render kept waiting for the build")
+	}
+
+	close(finish)
+	select {
+	case got := <-second:
+		if got.err != nil {
+			t.Fatalf("waiting render returned %v", got.err)
+		}
+		if got.views.Docker != want.Docker {
+			t.Errorf("waiting render got other views than the shared build's")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting render never got the shared build's views")
+	}
+	if builds != 1 {
+		t.Errorf("built %d times, want 1", builds)
+	}
+}
+
+func TestOverviewFlightBuildsAgainOnceDone(t *testing.T) {
+	flight := newOverviewFlight()
+	builds := 0
+	build := func() overviewViews {
+		builds++
+		return overviewViews{}
+	}
+	for n := 0; n < 2; n++ {
+		if _, err := flight.Do(context.Background(), "kind", build); err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+	}
+	if builds != 2 {
+		t.Errorf("built %d times, want 2", builds)
+	}
+}
+
+func TestOverviewFlightSeparatesKeys(t *testing.T) {
+	flight := newOverviewFlight()
+	release := make(chan struct{})
+	blocked := make(chan struct{})
+	go func() {
+		_, _ = flight.Do(context.Background(), "blocked", func() overviewViews {
+			close(blocked)
+			<-release
+			return overviewViews{}
+		})
+	}()
+	<-blocked
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := flight.Do(ctx, "other", func() overviewViews { return overviewViews{} }); err != nil {
+		t.Fatalf("render of another cluster waited on a running build: %v", err)
+	}
+}
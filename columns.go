@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"strings"
+)
+
+var (
+	// hostColumnNames are the columns the host image tables can show, in
+	// the order they are shown.
+	hostColumnNames = []string{"Tags", "Image ID", "Reference", "Digest", "Created", "Last used", "Size", "Architecture", "Project", "Revision"}
+
+	// kindColumnNames are the columns the kind image table can show.
+	kindColumnNames = []string{"Image", "Image ID", "Reference", "Digest", "Created", "Last used", "Size", "Architecture", "Used by", "Revision"}
+
+	// hostColumns are the host image table columns shown, set with
+	// KIND_REGISTRY_HOST_COLUMNS. Label, vulnerability and command columns
+	// come after them when enabled.
+	hostColumns = hostColumnNames
+
+	// kindColumns are the kind image table columns shown, set with
+	// KIND_REGISTRY_KIND_COLUMNS.
+	kindColumns = kindColumnNames
+)
+
+// parseColumns reads a comma separated column list. Names match known ones
+// regardless of case and are shown in the order given. Unknown names are
+// logged and skipped, and a list without a known name keeps all columns.
+func parseColumns(v, setting string, known []string) []string {
+	var columns []string
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		column, ok := knownColumn(name, known)
+		if !ok {
+			log.Printf("warning: %s: skipping unknown column %q, known columns are %s", setting, name, strings.Join(known, ", "))
+			continue
+		}
+		if !contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		log.Printf("warning: %s: no known columns in %q, showing all columns", setting, v)
+		return known
+	}
+	return columns
+}
+
+func knownColumn(name string, known []string) (string, bool) {
+	for _, column := range known {
+		if strings.EqualFold(column, name) {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+// hostColumnsNeedInspect reports whether the shown host columns come from
+// docker image inspect, which the table otherwise skips.
+func hostColumnsNeedInspect() bool {
+	return len(labelColumns) > 0 || contains(hostColumns, "Digest") || contains(hostColumns, "Architecture") || contains(hostColumns, "Project") ||
+		contains(hostColumns, "Revision")
+}
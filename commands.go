@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os/exec"
+	"sync"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+// commandToggle remembers whether the image tables show the command behind
+// each row's main action, for copying into a terminal or script.
+type commandToggle struct {
+	mu    sync.Mutex
+	shown bool
+}
+
+// Toggle switches the command columns on or off.
+func (c *commandToggle) Toggle() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.shown = !c.shown
+}
+
+// Shown reports whether the command columns are currently shown.
+func (c *commandToggle) Shown() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.shown
+}
+
+// commandToggleLabel is the text of the button that flips the command columns.
+func commandToggleLabel(shown bool) string {
+	if shown {
+		return "Hide commands"
+	}
+	return "Show commands"
+}
+
+// kindDeleteCommand is the command deleteImage runs to remove imageID, listed
+// as image, from the control plane node, and on the other nodes alike,
+// depending on the tool the images were listed with. crictl rmi removes
+// every tag of an image, so one of several tags is removed with ctr, which
+// leaves the image and its other tags in place.
+func kindDeleteCommand(imageID string, image kindImage, backend string) *exec.Cmd {
+	return nodeDeleteCommand(kindNode, imageID, image, backend)
+}
+
+// nodeDeleteCommand is kindDeleteCommand for any node of the cluster.
+func nodeDeleteCommand(node, imageID string, image kindImage, backend string) *exec.Cmd {
+	if keepsOtherTags(imageID, image) {
+		// ctr -n {{containerdNamespace}} images rm {{repoTag}}
+		return nodeCommand(node, "ctr", "-n", containerdNamespace, "images", "rm", imageID)
+	}
+	if backend == backendCtr {
+		// ctr -n {{containerdNamespace}} images rm {{refs}}
+		return nodeCommand(node, append([]string{"ctr", "-n", containerdNamespace, "images", "rm"}, image.Refs()...)...)
+	}
+	// crictl rmi {{imageID}}
+	return crictlCommand(node, "rmi", imageID)
+}
+
+// deleteTarget is what the Delete action of a kind table row removes: the
+// row's tag, or the image ID for an untagged image.
+func deleteTarget(image kindImage, repoTag string) string {
+	if repoTag == "" || repoTag == image.ID {
+		return image.ID
+	}
+	return repoTag
+}
+
+// keepsOtherTags reports whether deleting target removes only one of the
+// tags of image, so the image itself stays.
+func keepsOtherTags(target string, image kindImage) bool {
+	return len(image.RepoTags) > 1 && contains(image.RepoTags, target)
+}
+
+// otherTags returns the tags of image besides repoTag.
+func otherTags(image kindImage, repoTag string) []string {
+	var others []string
+	for _, tag := range image.RepoTags {
+		if tag != repoTag {
+			others = append(others, tag)
+		}
+	}
+	return others
+}
+
+// commandCell renders a command line in a table cell.
+func commandCell(command string) component.Component {
+	return component.NewText(command)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// The labels docker compose puts on the images it builds.
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
+// noComposeProject groups the images without compose labels.
+const noComposeProject = "(none)"
+
+// composeProject returns the compose project of an image, or
+// noComposeProject for images compose didn't build.
+func composeProject(labels map[string]string) string {
+	if project := labels[composeProjectLabel]; project != "" {
+		return project
+	}
+	return noComposeProject
+}
+
+// composeProjectCell renders the compose project of an image and, when
+// known, the service it was built for.
+func composeProjectCell(labels map[string]string) component.Component {
+	project := composeProject(labels)
+	if service := labels[composeServiceLabel]; service != "" && project != noComposeProject {
+		return component.NewTextf("%s (service %s)", project, service)
+	}
+	return component.NewText(project)
+}
+
+// composeProjects returns the compose projects of images, sorted, with
+// noComposeProject last.
+func composeProjects(images []dockerImage, inspects map[string]dockerInspect) []string {
+	seen := map[string]bool{}
+	var projects []string
+	none := false
+	for _, image := range images {
+		project := composeProject(inspects[image.ID].Config.Labels)
+		if project == noComposeProject {
+			none = true
+			continue
+		}
+		if !seen[project] {
+			seen[project] = true
+			projects = append(projects, project)
+		}
+	}
+	sort.Strings(projects)
+	if none {
+		projects = append(projects, noComposeProject)
+	}
+	return projects
+}
+
+// projectFilter restricts the host image table to one compose project. It
+// is held by the plugin so it survives refreshes.
+type projectFilter struct {
+	mu      sync.Mutex
+	project string
+}
+
+// Set stores the project to show; an empty project shows every image.
+func (f *projectFilter) Set(project string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.project = project
+}
+
+func (f *projectFilter) Get() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.project
+}
+
+// matchProject reports whether an image belongs to the filtered project.
+func matchProject(project string, labels map[string]string) bool {
+	return project == "" || composeProject(labels) == project
+}
+
+// projectFilterCard renders the form that picks the compose project shown.
+func projectFilterCard(project string, projects []string) *component.Card {
+	card := component.NewCard(component.TitleFromString("Filter by Compose Project"))
+	body := fmt.Sprintf("Show only the images of a docker compose project, read from their %s label. "+
+		"Images compose didn't build are in %s.", composeProjectLabel, noComposeProject)
+	if project != "" {
+		body = fmt.Sprintf("Showing only the images of compose project %s.", project)
+	}
+	card.SetBody(component.NewText(body))
+
+	choices := []component.InputChoice{{Label: "All images", Value: "", Checked: project == ""}}
+	for _, p := range projects {
+		choices = append(choices, component.InputChoice{Label: p, Value: p, Checked: p == project})
+	}
+	card.AddAction(component.Action{
+		Name:  "Filter",
+		Title: "Filter images by compose project",
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.Project),
+				component.NewFormFieldRadio("Project", "project", choices),
+			},
+		},
+		Modal: true,
+	})
+	return card
+}
+
+// addProjectButton offers to load every image of the filtered compose
+// project. The images without compose labels are no project to load.
+func addProjectButton(layout *flexlayout.FlexLayout, project string, count int) {
+	if project == "" || project == noComposeProject || count == 0 {
+		return
+	}
+	layout.AddButton(fmt.Sprintf("Load project %s into kind", project), action.Payload{
+		"action":  names.LoadProject,
+		"project": project,
+	}, component.WithButtonConfirmation("Load project into kind?",
+		fmt.Sprintf("This queues the %d tagged image(s) of compose project %s for loading into kind. Do you want to continue?", count, project)))
+}
+
+// projectImages returns the tagged references of the images of a compose
+// project, one per image. Untagged images can't be loaded by name.
+func projectImages(project string, images []dockerImage, inspects map[string]dockerInspect) []string {
+	var refs []string
+	for _, image := range images {
+		if composeProject(inspects[image.ID].Config.Labels) != project || image.Reference() == image.ID {
+			continue
+		}
+		refs = append(refs, image.Reference())
+	}
+	return refs
+}
+
+// loadProject queues every tagged image of a compose project for loading
+// into kind. Images already loading or queued are left where they are.
+func (i *imagePlugin) loadProject(project string) error {
+	if project == "" || project == noComposeProject {
+		return fmt.Errorf("choose a compose project to load")
+	}
+	images, err := listDockerImages()
+	if err != nil {
+		return fmt.Errorf("loadProject %s: %w", project, err)
+	}
+	grouped, _ := groupByID(images)
+	var ids []string
+	for _, image := range grouped {
+		ids = append(ids, image.ID)
+	}
+	inspects, err := i.inspects.Get(ids)
+	if err != nil {
+		return fmt.Errorf("loadProject %s: %w", project, err)
+	}
+	refs := projectImages(project, grouped, inspects)
+	if len(refs) == 0 {
+		return fmt.Errorf("compose project %s has no tagged images", project)
+	}
+	queued := 0
+	for _, ref := range refs {
+		if err := i.queue.Enqueue(queuedJob{ImageID: ref}); err != nil {
+			log.Printf("not queueing %s of compose project %s: %s", ref, project, err)
+			continue
+		}
+		queued++
+	}
+	log.Printf("queued %d of %d image(s) of compose project %s for loading into kind", queued, len(refs), project)
+	return nil
+}
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// loadTimeout stops a load that runs longer than this. Zero means no limit.
+	loadTimeout time.Duration
+
+	// hideSystemImages is whether the kind table starts with system images hidden.
+	hideSystemImages bool
+)
+
+// configOption is a setting that can be given as a flag or, when the flag is
+// not set, an environment variable.
+type configOption struct {
+	Flag  string
+	Env   string
+	Usage string
+	// Apply validates a value and stores it in the package variable.
+	Apply func(string) error
+	// Value renders the value of the setting, which is also the default of
+	// its flag.
+	Value func() string
+	// Effective, when set, renders the value in use for a setting whose
+	// default is worked out once every option is applied.
+	Effective func() string
+}
+
+func configOptions() []configOption {
+	return []configOption{
+		{
+			Flag: "kind-cluster", Env: "KIND_REGISTRY_CLUSTER", Usage: "name of the kind cluster to manage",
+			Apply: func(v string) error {
+				if v == "" {
+					return fmt.Errorf("must not be empty")
+				}
+				kindCluster = v
+				kindNode = kindCluster + "-control-plane"
+				return nil
+			},
+			Value: func() string { return kindCluster },
+		},
+		{
+			Flag: "runtime", Env: "KIND_REGISTRY_RUNTIME", Usage: "container CLI for host images: docker, podman or nerdctl",
+			Apply: func(v string) error {
+				if err := runtimeChoice(v); err != nil {
+					return err
+				}
+				if v != host.Name() {
+					host = runtimeNamed(v)
+					requiredTools = []string{host.Name(), "kind"}
+				}
+				return nil
+			},
+			Value: func() string { return host.Name() },
+		},
+		{
+			Flag: "node-runtime", Env: "KIND_REGISTRY_NODE_RUNTIME", Usage: "container CLI that runs commands inside the kind nodes: docker, podman or nerdctl",
+			// Empty follows --runtime, which may not be applied yet.
+			Apply: func(v string) error {
+				if v != "" {
+					if err := runtimeChoice(v); err != nil {
+						return err
+					}
+				}
+				nodeRuntimeName = v
+				return nil
+			},
+			Value:     func() string { return nodeRuntimeName },
+			Effective: nodeRuntime,
+		},
+		{
+			Flag: "namespace", Env: "KIND_REGISTRY_NAMESPACE", Usage: "containerd namespace used inside the kind nodes",
+			Apply: func(v string) error { containerdNamespace = v; return nil },
+			Value: func() string { return containerdNamespace },
+		},
+		{
+			Flag: "cri-endpoint", Env: "KIND_REGISTRY_CRI_ENDPOINT", Usage: "CRI socket crictl uses inside the kind nodes",
+			Apply: func(v string) error { criEndpoint = v; return nil },
+			Value: func() string { return criEndpoint },
+		},
+		{
+			Flag: "registry-container", Env: "KIND_REGISTRY_CONTAINER", Usage: "name of the local registry container",
+			Apply: func(v string) error { registryContainer = v; return nil },
+			Value: func() string { return registryContainer },
+		},
+		{
+			Flag: "large-image-size", Env: "KIND_REGISTRY_LARGE_IMAGE_SIZE", Usage: "size above which loads ask for confirmation",
+			Apply: func(v string) error {
+				threshold, err := parseSize(v)
+				if err != nil {
+					return err
+				}
+				largeImageThreshold = threshold
+				return nil
+			},
+			Value: func() string { return formatBytes(largeImageThreshold) },
+		},
+		{
+			Flag: "max-rows", Env: "KIND_REGISTRY_MAX_ROWS", Usage: "rows rendered per page of a table",
+			Apply: func(v string) error {
+				rows, err := positiveInt(v)
+				if err != nil {
+					return err
+				}
+				maxRows = rows
+				return nil
+			},
+			Value: func() string { return strconv.Itoa(maxRows) },
+		},
+		{
+			Flag: "queue-size", Env: "KIND_REGISTRY_QUEUE_SIZE", Usage: "operations that may wait while another one runs",
+			Apply: func(v string) error {
+				size, err := positiveInt(v)
+				if err != nil {
+					return err
+				}
+				queueSize = size
+				return nil
+			},
+			Value: func() string { return strconv.Itoa(queueSize) },
+		},
+		{
+			Flag: "image-list-format", Env: "KIND_REGISTRY_IMAGE_LIST_FORMAT", Usage: "docker image ls --format template, one JSON object per line",
+			Apply: func(v string) error {
+				if v == "" {
+					return fmt.Errorf("must not be empty")
+				}
+				imageListFormat = v
+				return nil
+			},
+			Value: func() string { return imageListFormat },
+		},
+		{
+			Flag: "crictl-output", Env: "KIND_REGISTRY_CRICTL_OUTPUT", Usage: "crictl images --output format, must produce crictl's JSON",
+			Apply: func(v string) error {
+				if v == "" {
+					return fmt.Errorf("must not be empty")
+				}
+				crictlOutput = v
+				return nil
+			},
+			Value: func() string { return crictlOutput },
+		},
+		{
+			Flag: "label-columns", Env: "KIND_REGISTRY_LABEL_COLUMNS", Usage: "comma separated image label keys shown as columns of the host image table",
+			Apply: func(v string) error {
+				labelColumns = nil
+				for _, key := range strings.Split(v, ",") {
+					if key = strings.TrimSpace(key); key != "" {
+						labelColumns = append(labelColumns, key)
+					}
+				}
+				return nil
+			},
+			Value: func() string { return strings.Join(labelColumns, ",") },
+		},
+		{
+			Flag: "host-columns", Env: "KIND_REGISTRY_HOST_COLUMNS", Usage: "comma separated columns of the host image tables, out of " + strings.Join(hostColumnNames, ", "),
+			Apply: func(v string) error {
+				hostColumns = parseColumns(v, "--host-columns", hostColumnNames)
+				return nil
+			},
+			Value: func() string { return strings.Join(hostColumns, ",") },
+		},
+		{
+			Flag: "kind-columns", Env: "KIND_REGISTRY_KIND_COLUMNS", Usage: "comma separated columns of the kind image table, out of " + strings.Join(kindColumnNames, ", "),
+			Apply: func(v string) error {
+				kindColumns = parseColumns(v, "--kind-columns", kindColumnNames)
+				return nil
+			},
+			Value: func() string { return strings.Join(kindColumns, ",") },
+		},
+		{
+			Flag: "max-processes", Env: "KIND_REGISTRY_MAX_PROCESSES", Usage: "commands such as docker exec run at once, 0 for no limit",
+			Apply: func(v string) error {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 {
+					return fmt.Errorf("must be a number of at least 0, got %q", v)
+				}
+				maxProcesses = n
+				return nil
+			},
+			Value: func() string { return strconv.Itoa(maxProcesses) },
+		},
+		{
+			Flag: "stale-days", Env: "KIND_REGISTRY_STALE_DAYS", Usage: "days after which images no container uses are suggested for cleanup",
+			Apply: func(v string) error {
+				days, err := positiveInt(v)
+				if err != nil {
+					return err
+				}
+				staleDays = days
+				return nil
+			},
+			Value: func() string { return strconv.Itoa(staleDays) },
+		},
+		{
+			Flag: "api-listen", Env: "KIND_REGISTRY_API_LISTEN", Usage: "loopback address, e.g. 127.0.0.1:0, to serve the cached image listings as JSON on, empty to disable",
+			Apply: func(v string) error {
+				if v != "" {
+					if err := checkAPIListen(v); err != nil {
+						return err
+					}
+				}
+				apiListen = v
+				return nil
+			},
+			Value: func() string { return apiListen },
+		},
+		{
+			Flag: "load-timeout", Env: "KIND_REGISTRY_LOAD_TIMEOUT", Usage: "cancel loads that run longer than this, 0 for no limit",
+			Apply: func(v string) error {
+				timeout, err := time.ParseDuration(v)
+				if err != nil || timeout < 0 {
+					return fmt.Errorf("must be a duration such as 10m, got %q", v)
+				}
+				loadTimeout = timeout
+				return nil
+			},
+			Value: func() string { return loadTimeout.String() },
+		},
+		{
+			Flag: "load-strategy", Env: "KIND_REGISTRY_LOAD_STRATEGY", Usage: "how images get into the nodes: kind (kind load), stream (docker save piped into ctr images import, falling back to kind load) or auto (stream large images)",
+			Apply: func(v string) error {
+				switch v {
+				case loadKind, loadStream, loadAuto:
+					loadStrategy = v
+					return nil
+				}
+				return fmt.Errorf("must be %s, %s or %s, got %q", loadKind, loadStream, loadAuto, v)
+			},
+			Value: func() string { return loadStrategy },
+		},
+		{
+			Flag: "load-retries", Env: "KIND_REGISTRY_LOAD_RETRIES", Usage: "how many times a kind load failing with a transient error is retried",
+			Apply: func(v string) error {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 0 {
+					return fmt.Errorf("must be zero or a positive number, got %q", v)
+				}
+				loadRetries = n
+				return nil
+			},
+			Value: func() string { return strconv.Itoa(loadRetries) },
+		},
+		{
+			Flag: "cache-ttl", Env: "KIND_REGISTRY_CACHE_TTL", Usage: "how long daemon and node status probes are reused",
+			Apply: func(v string) error {
+				ttl, err := time.ParseDuration(v)
+				if err != nil || ttl < 0 {
+					return fmt.Errorf("must be a duration such as 5s, got %q", v)
+				}
+				healthTTL = ttl
+				return nil
+			},
+			Value: func() string { return healthTTL.String() },
+		},
+		{
+			Flag: "dry-run", Env: "KIND_REGISTRY_DRY_RUN", Usage: "only report the commands loads and deletes would run",
+			Apply: func(v string) error {
+				enabled, err := parseBool(v)
+				if err != nil {
+					return err
+				}
+				dryRun = enabled
+				return nil
+			},
+			Value: func() string { return strconv.FormatBool(dryRun) },
+		},
+		{
+			Flag: "verbose", Env: "KIND_REGISTRY_VERBOSE", Usage: "log every command run with its duration and exit code",
+			Apply: func(v string) error {
+				enabled, err := parseBool(v)
+				if err != nil {
+					return err
+				}
+				verbose = enabled
+				return nil
+			},
+			Value: func() string { return strconv.FormatBool(verbose) },
+		},
+		{
+			Flag: "auto-sync", Env: "KIND_REGISTRY_AUTO_SYNC", Usage: "start with new host images loaded into kind automatically",
+			Apply: func(v string) error {
+				enabled, err := parseBool(v)
+				if err != nil {
+					return err
+				}
+				autoSync = enabled
+				return nil
+			},
+			Value: func() string { return strconv.FormatBool(autoSync) },
+		},
+		{
+			Flag: "auto-sync-interval", Env: "KIND_REGISTRY_AUTO_SYNC_INTERVAL", Usage: "how often auto-sync looks for new host images",
+			Apply: func(v string) error {
+				interval, err := time.ParseDuration(v)
+				if err != nil || interval <= 0 {
+					return fmt.Errorf("must be a positive duration such as 15s, got %q", v)
+				}
+				autoSyncInterval = interval
+				return nil
+			},
+			Value: func() string { return autoSyncInterval.String() },
+		},
+		{
+			Flag: "collapse-tags", Env: "KIND_REGISTRY_COLLAPSE_TAGS", Usage: "show an image tagged more than once as one host table row",
+			Apply: func(v string) error {
+				enabled, err := parseBool(v)
+				if err != nil {
+					return err
+				}
+				collapseTags = enabled
+				return nil
+			},
+			Value: func() string { return strconv.FormatBool(collapseTags) },
+		},
+		{
+			Flag: "hide-system-images", Env: "KIND_REGISTRY_HIDE_SYSTEM_IMAGES", Usage: "start with system images hidden from the kind table",
+			Apply: func(v string) error {
+				enabled, err := parseBool(v)
+				if err != nil {
+					return err
+				}
+				hideSystemImages = enabled
+				return nil
+			},
+			Value: func() string { return strconv.FormatBool(hideSystemImages) },
+		},
+		{
+			Flag: "nav-counts", Env: "KIND_REGISTRY_NAV_COUNTS", Usage: "show the number of images pending sync in the navigation titles",
+			Apply: func(v string) error {
+				enabled, err := parseBool(v)
+				if err != nil {
+					return err
+				}
+				navCounts = enabled
+				return nil
+			},
+			Value: func() string { return strconv.FormatBool(navCounts) },
+		},
+		{
+			Flag: "scan-images", Env: "KIND_REGISTRY_SCAN_IMAGES", Usage: "offer Trivy vulnerability scans of host images when trivy is on the PATH",
+			Apply: func(v string) error {
+				enabled, err := parseBool(v)
+				if err != nil {
+					return err
+				}
+				scanImages = enabled
+				return nil
+			},
+			Value: func() string { return strconv.FormatBool(scanImages) },
+		},
+		{
+			Flag: "state-file", Env: "KIND_REGISTRY_STATE_FILE", Usage: "file the hidden system images, command columns and label filter are remembered in, empty to disable",
+			Apply: func(v string) error { stateFile = v; return nil },
+			Value: func() string { return stateFile },
+		},
+		{
+			Flag: "persist-state", Env: "KIND_REGISTRY_PERSIST_STATE", Usage: "remember UI state in the state file, false to never read or write it",
+			Apply: func(v string) error {
+				enabled, err := parseBool(v)
+				if err != nil {
+					return err
+				}
+				persistState = enabled
+				return nil
+			},
+			Value: func() string { return strconv.FormatBool(persistState) },
+		},
+		{
+			Flag: "system-repositories", Env: "KIND_REGISTRY_SYSTEM_REPOSITORIES", Usage: "comma separated repository prefixes treated as system images",
+			Apply: func(v string) error {
+				var repositories []string
+				for _, repository := range strings.Split(v, ",") {
+					repository = strings.TrimSpace(repository)
+					if repository == "" {
+						continue
+					}
+					repositories = append(repositories, strings.TrimSuffix(repository, "/")+"/")
+				}
+				systemRepositories = repositories
+				return nil
+			},
+			Value: func() string { return strings.Join(systemRepositories, ",") },
+		},
+	}
+}
+
+// parseConfig applies the flags in args, falling back to the environment for
+// flags that are not given. Invalid values are returned as errors naming both.
+func parseConfig(args []string) error {
+	options := configOptions()
+	flags := flag.NewFlagSet("octant-kind-registry", flag.ContinueOnError)
+	values := make([]*string, len(options))
+	for n, option := range options {
+		values[n] = flags.String(option.Flag, envOrDefault(option.Env, option.Value()), fmt.Sprintf("%s (env %s)", option.Usage, option.Env))
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	for n, option := range options {
+		if err := option.Apply(*values[n]); err != nil {
+			return fmt.Errorf("--%s (%s): %w", option.Flag, option.Env, err)
+		}
+	}
+	return nil
+}
+
+func positiveInt(v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("must be a positive number, got %q", v)
+	}
+	return n, nil
+}
+
+// runtimeChoice accepts the container CLIs the plugin knows how to drive.
+func runtimeChoice(v string) error {
+	switch v {
+	case "docker", "podman", "nerdctl":
+		return nil
+	}
+	return fmt.Errorf("must be docker, podman or nerdctl, got %q", v)
+}
+
+func parseBool(v string) (bool, error) {
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("must be true or false, got %q", v)
+	}
+	return enabled, nil
+}
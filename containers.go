@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+type kindContainers struct {
+	Containers []kindContainer `json:"containers"`
+}
+
+type kindContainer struct {
+	ID       string `json:"id"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Image struct {
+		Image string `json:"image"`
+	} `json:"image"`
+	ImageRef string            `json:"imageRef"`
+	State    string            `json:"state"`
+	Labels   map[string]string `json:"labels"`
+	// CreatedAt is in nanoseconds since the epoch.
+	CreatedAt string `json:"createdAt"`
+	// Node is the kind node the container runs on.
+	Node string `json:"-"`
+}
+
+// Created returns when the container was created, or the zero time when
+// crictl didn't say.
+func (c kindContainer) Created() time.Time {
+	ns, err := strconv.ParseInt(c.CreatedAt, 10, 64)
+	if err != nil || ns <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// PodName returns the namespace/name of the pod the container belongs to.
+func (c kindContainer) PodName() string {
+	return fmt.Sprintf("%s/%s", c.Namespace(), c.Pod())
+}
+
+// listKindContainers returns the running containers on every kind node.
+func listKindContainers() (kindContainers, error) {
+	// docker exec {{node}} crictl ps --output=json
+	return clusterContainers("ps", "--output=json")
+}
+
+// listAllKindContainers returns the containers on every kind node in any
+// state, since exited containers keep a reference to their image too.
+func listAllKindContainers() (kindContainers, error) {
+	// docker exec {{node}} crictl ps --all --output=json
+	return clusterContainers("ps", "--all", "--output=json")
+}
+
+// clusterContainers merges the containers crictl lists on each node of the
+// cluster. The containers of the nodes that answered are returned along with
+// an error naming the ones that did not.
+func clusterContainers(args ...string) (kindContainers, error) {
+	var merged kindContainers
+	var failed []string
+	for _, node := range knownNodes() {
+		containers, err := crictlContainers(node, args...)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", node, err))
+			continue
+		}
+		merged.Containers = append(merged.Containers, containers.Containers...)
+	}
+	if len(failed) > 0 {
+		return merged, fmt.Errorf("clusterContainers: %s", strings.Join(failed, "; "))
+	}
+	return merged, nil
+}
+
+func crictlContainers(node string, args ...string) (kindContainers, error) {
+	cmd := crictlCommand(node, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	var containers kindContainers
+	if err := runCommand(cmd); err != nil {
+		return containers, fmt.Errorf("listKindContainers: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &containers); err != nil {
+		return containers, fmt.Errorf("listKindContainers: %w", err)
+	}
+	for n := range containers.Containers {
+		containers.Containers[n].Node = node
+	}
+	return containers, nil
+}
+
+// Namespace returns the namespace of the pod the container belongs to.
+func (c kindContainer) Namespace() string {
+	return c.Labels["io.kubernetes.pod.namespace"]
+}
+
+// Pod returns the name of the pod the container belongs to.
+func (c kindContainer) Pod() string {
+	return c.Labels["io.kubernetes.pod.name"]
+}
+
+// PodPath returns the Octant content path of the pod the container belongs to.
+func (c kindContainer) PodPath() string {
+	return fmt.Sprintf("/overview/namespace/%s/workloads/pods/%s", c.Namespace(), c.Pod())
+}
+
+// HasPod reports whether the container's pod can be linked to: containers
+// not started by the kubelet have no pod labels, and the pod of an exited
+// container may be gone.
+func (c kindContainer) HasPod() bool {
+	return c.State == "CONTAINER_RUNNING" && c.Namespace() != "" && c.Pod() != ""
+}
+
+// podLink links to the container's pod in Octant, or names it in plain text
+// when the pod can't be linked to.
+func podLink(c kindContainer) component.Component {
+	if !c.HasPod() {
+		return component.NewText(c.PodName())
+	}
+	return component.NewLink("", c.PodName(), c.PodPath())
+}
+
+// imageConsumers maps kind image IDs to the running containers that use them.
+func imageConsumers(containers kindContainers) map[string][]kindContainer {
+	consumers := map[string][]kindContainer{}
+	for _, c := range containers.Containers {
+		imageID := c.ImageRef
+		if !strings.HasPrefix(imageID, "sha256:") {
+			imageID = c.Image.Image
+		}
+		consumers[imageID] = append(consumers[imageID], c)
+	}
+	return consumers
+}
+
+// describeConsumers lists containers as "namespace/pod (container)".
+func describeConsumers(consumers []kindContainer) string {
+	var names []string
+	for _, c := range consumers {
+		names = append(names, fmt.Sprintf("%s (%s)", c.PodName(), c.Metadata.Name))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// copyImage copies an image from the kind node straight into every node of
+// another kind cluster, without going through the docker image store. Nodes
+// are imported one by one so a failing node does not stop the others.
+func (i *imagePlugin) copyImage(ctx context.Context, job queuedJob) (err error) {
+	ref, cluster := job.ImageID, job.Target
+
+	i.copyProgress.Start(fmt.Sprintf("Copying %s to cluster %s", ref, cluster))
+	var copied []string
+	defer func() {
+		i.copyProgress.Finish(err, fmt.Sprintf("Copied %s to %d node(s) of cluster %s in %s: %s",
+			ref, len(copied), cluster, i.copyProgress.Elapsed(), strings.Join(copied, ", ")))
+	}()
+
+	nodes, err := listClusterNodes(cluster)
+	if err != nil {
+		return fmt.Errorf("copyImage %s: %w", ref, err)
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("copyImage %s: cluster %s has no nodes", ref, cluster)
+	}
+
+	archive, err := ioutil.TempFile("", "kind-image-*.tar")
+	if err != nil {
+		return fmt.Errorf("copyImage %s: %w", ref, err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	// docker exec {{kindNode}} ctr -n {{namespace}} images export - {{ref}}
+	i.copyProgress.Write(fmt.Sprintf("Exporting %s from %s", ref, kindNode))
+	var stderr bytes.Buffer
+	cmd := nodeCommand(kindNode, "ctr", "-n", containerdNamespace, "images", "export", "-", ref)
+	cmd.Stdout = archive
+	cmd.Stderr = &stderr
+	if err := runContext(ctx, cmd); err != nil {
+		return fmt.Errorf("copyImage %s: export from %s: %w: %s", ref, kindNode, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var failed []string
+	for _, node := range nodes {
+		if err := importNodeImage(ctx, node, archive, ref); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("copyImage %s: %w", ref, ctx.Err())
+			}
+			i.copyProgress.Write(fmt.Sprintf("%s: failed: %s", node, err))
+			failed = append(failed, node)
+			continue
+		}
+		i.copyProgress.Write(fmt.Sprintf("%s: copied", node))
+		copied = append(copied, node)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("copyImage %s: copied to %d of %d node(s), failed on %s: %s",
+			ref, len(copied), len(nodes), strings.Join(failed, ", "), strings.Join(i.copyProgress.Output(), "\n"))
+	}
+	return nil
+}
+
+// importNodeImage imports the exported archive into a node and checks that the
+// image shows up in the node's crictl listing.
+func importNodeImage(ctx context.Context, node string, archive *os.File, ref string) error {
+	if _, err := archive.Seek(0, 0); err != nil {
+		return err
+	}
+
+	// docker exec -i {{node}} ctr -n {{namespace}} images import --digests -
+	var stderr bytes.Buffer
+	cmd := nodeInputCommand(node, "ctr", "-n", containerdNamespace, "images", "import", "--digests", "-")
+	cmd.Stdin = archive
+	cmd.Stderr = &stderr
+	if err := runContext(ctx, cmd); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	images, err := listNodeImages(node)
+	if err != nil {
+		return err
+	}
+	for _, image := range images.Images {
+		for _, repoTag := range image.RepoTags {
+			if repoTag == ref {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s is missing from the node after import", ref)
+}
+
+// addCopyPromptSection asks which cluster to copy source to.
+func addCopyPromptSection(layout *flexlayout.FlexLayout, source string, err error) {
+	var choices []component.InputChoice
+	clusters, listErr := listKindClusters()
+	for _, cluster := range clusters {
+		if cluster != kindCluster {
+			choices = append(choices, component.InputChoice{Label: cluster, Value: cluster})
+		}
+	}
+	if err == nil && listErr != nil {
+		err = listErr
+	}
+	if err == nil && len(choices) == 0 {
+		err = fmt.Errorf("there are no other kind clusters to copy %s to", source)
+	}
+
+	card := component.NewCard(component.TitleFromString(fmt.Sprintf("Copy %s", source)))
+	card.SetBody(component.NewText("Copy the image from the kind node into every node of another kind cluster."))
+	if len(choices) > 0 {
+		choices[0].Checked = true
+		card.AddAction(component.Action{
+			Name:  "Copy",
+			Title: fmt.Sprintf("Copy %s", source),
+			Form: component.Form{
+				Fields: []component.FormField{
+					component.NewFormFieldHidden("action", names.Copy),
+					component.NewFormFieldHidden("source", source),
+					component.NewFormFieldRadio("Cluster", "cluster", choices),
+				},
+			},
+			Modal: true,
+		})
+	}
+	if err != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, err.Error()))
+	}
+
+	promptSection := layout.AddSection()
+	promptSection.Add(card, component.WidthFull)
+	layout.AddButton("Dismiss copy", action.Payload{
+		"action":  names.CopyPrompt,
+		"imageID": "",
+	})
+}
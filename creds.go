@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+)
+
+// dockerHubConfigKey is the key docker login stores Docker Hub logins under.
+const dockerHubConfigKey = "https://index.docker.io/v1/"
+
+// dockerConfigFile is the part of a docker config.json that holds logins.
+type dockerConfigFile struct {
+	Auths map[string]struct {
+		Auth string `json:"auth"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// defaultDockerConfig returns the config.json docker itself reads, honoring
+// DOCKER_CONFIG.
+func defaultDockerConfig() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to find the docker config: %w", err)
+	}
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// registryHost returns the registry ref is pulled from, e.g. docker.io for nginx.
+func registryHost(ref string) string {
+	return strings.SplitN(normalizeReference(ref), "/", 2)[0]
+}
+
+// registryConfigKeys are the keys a login for registry may be stored under.
+func registryConfigKeys(registry string) []string {
+	if registry == "docker.io" {
+		return []string{dockerHubConfigKey, "index.docker.io", "docker.io", "registry-1.docker.io"}
+	}
+	return []string{registry, "https://" + registry, "http://" + registry}
+}
+
+// dockerConfigCreds returns the user:password of the login for ref's
+// registry in the docker config at path, asking its credential helper when
+// it has one. Errors name the registry and the helper, never the secret.
+func dockerConfigCreds(path, ref string) (string, error) {
+	registry := registryHost(ref)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read docker config %s: %w", path, err)
+	}
+	var config dockerConfigFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("unable to parse docker config %s: %w", path, err)
+	}
+
+	for _, key := range registryConfigKeys(registry) {
+		if helper := config.CredHelpers[key]; helper != "" {
+			return helperCreds(helper, key)
+		}
+	}
+	for _, key := range registryConfigKeys(registry) {
+		auth, ok := config.Auths[key]
+		if !ok {
+			continue
+		}
+		// Logins kept by credsStore leave an empty entry in auths.
+		if auth.Auth == "" && config.CredsStore != "" {
+			return helperCreds(config.CredsStore, key)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil || !strings.Contains(string(decoded), ":") {
+			return "", fmt.Errorf("the login for %s in %s is not a base64 user:password", registry, path)
+		}
+		return string(decoded), nil
+	}
+	if config.CredsStore != "" {
+		return helperCreds(config.CredsStore, registryConfigKeys(registry)[0])
+	}
+	return "", fmt.Errorf("%s has no login for %s, run %s login %s first", path, registry, host.Name(), registry)
+}
+
+// helperCreds asks docker-credential-<helper> for the login of server.
+func helperCreds(helper, server string) (string, error) {
+	// docker-credential-{{helper}} get <<< {{server}}
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(server)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		// Helpers print the reason, never the secret, on failure.
+		return "", commandError("docker-credential-"+helper+" get", err, stderr.String())
+	}
+	var login struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &login); err != nil {
+		return "", fmt.Errorf("docker-credential-%s printed an unreadable login for %s", helper, server)
+	}
+	if login.Username == "<token>" {
+		return "", fmt.Errorf("the login for %s is an identity token, which crictl can't pull with; enter a username and password instead", server)
+	}
+	return login.Username + ":" + login.Secret, nil
+}
+
+// pullCreds returns the user:password the pull form asks to pull ref with:
+// the username and password typed in, or else the login for ref's registry
+// from the docker config path given or, when "use my docker credentials"
+// is checked, from the default one. Empty means an anonymous pull.
+func pullCreds(payload action.Payload, ref string) (string, error) {
+	username, _ := payload.String("username")
+	password, _ := payload.String("password")
+	if username = strings.TrimSpace(username); username != "" {
+		return username + ":" + password, nil
+	}
+	path, _ := payload.String("dockerConfig")
+	if path = strings.TrimSpace(path); path != "" {
+		if err := validatePayloadPath(path); err != nil {
+			return "", err
+		}
+		return dockerConfigCreds(path, ref)
+	}
+	if use, _ := payload.StringSlice("dockerCreds"); len(use) > 0 {
+		path, err := defaultDockerConfig()
+		if err != nil {
+			return "", err
+		}
+		return dockerConfigCreds(path, ref)
+	}
+	return "", nil
+}
+
+// redactCreds removes creds, and the password in it, from output shown to
+// the user, in case a failing command echoes them.
+func redactCreds(output, creds string) string {
+	if creds == "" {
+		return output
+	}
+	output = strings.ReplaceAll(output, creds, "<redacted>")
+	if i := strings.Index(creds, ":"); i >= 0 && len(creds[i+1:]) > 0 {
+		output = strings.ReplaceAll(output, creds[i+1:], "<redacted>")
+	}
+	return output
+}
+
+// isUnauthorized reports whether a pull failed because the registry wants
+// credentials or refused the ones given.
+func isUnauthorized(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	return strings.Contains(stderr, "unauthorized") || strings.Contains(stderr, "401") ||
+		strings.Contains(stderr, "authentication required") || strings.Contains(stderr, "denied")
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	backendCrictl = "crictl"
+	backendCtr    = "ctr"
+)
+
+// crictlMissing reports whether a docker exec failed because the node image
+// has no crictl binary.
+func crictlMissing(stderr string) bool {
+	return strings.Contains(stderr, "executable file not found") && strings.Contains(stderr, "crictl")
+}
+
+// listCtrImages lists the node images with ctr, for node images that do not
+// ship crictl. ctr prints one row per reference, so references are grouped by
+// manifest digest, which stands in for the image ID.
+func listCtrImages(node string) (kindImages, error) {
+	return listNamespaceImages(node, containerdNamespace)
+}
+
+// listNamespaceImages lists the images of one containerd namespace on a node
+// with ctr.
+func listNamespaceImages(node, namespace string) (kindImages, error) {
+	// docker exec {{node}} ctr -n {{namespace}} images ls
+	cmd := nodeCommand(node, "ctr", "-n", namespace, "images", "ls")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(cmd); err != nil {
+		return kindImages{}, commandError("ctr images ls", err, stderr.String())
+	}
+
+	images := kindImages{Backend: backendCtr}
+	byDigest := map[string]*kindImage{}
+	var order []string
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		// REF TYPE DIGEST SIZE PLATFORMS LABELS, where SIZE is e.g. "27.1 MiB".
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] == "REF" {
+			continue
+		}
+		ref, digest := fields[0], fields[2]
+
+		image, ok := byDigest[digest]
+		if !ok {
+			image = &kindImage{ID: digest}
+			if size, err := parseSize(fields[3] + fields[4]); err == nil {
+				image.Size = fmt.Sprint(size)
+			}
+			byDigest[digest] = image
+			order = append(order, digest)
+		}
+		switch {
+		case strings.HasPrefix(ref, "sha256:"):
+		case strings.Contains(ref, "@"):
+			image.RepoDigests = append(image.RepoDigests, ref)
+		default:
+			image.RepoTags = append(image.RepoTags, ref)
+		}
+	}
+
+	for _, digest := range order {
+		images.Images = append(images.Images, *byDigest[digest])
+	}
+	return images, nil
+}
+
+// Refs returns every reference ctr knows the image by, which is what ctr
+// images rm needs.
+func (k kindImage) Refs() []string {
+	refs := append([]string{}, k.RepoTags...)
+	return append(refs, k.RepoDigests...)
+}
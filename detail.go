@@ -0,0 +1,363 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const (
+	dockerImagePath = "image"
+	kindImagePath   = "kind-image"
+)
+
+// imageConfig is the part of the image config shared by docker image inspect
+// and the OCI image spec crictl reports.
+type imageConfig struct {
+	User         string              `json:"User"`
+	Env          []string            `json:"Env"`
+	Entrypoint   []string            `json:"Entrypoint"`
+	Cmd          []string            `json:"Cmd"`
+	WorkingDir   string              `json:"WorkingDir"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+	Labels       map[string]string   `json:"Labels"`
+}
+
+// Ports returns the exposed ports, e.g. 80/tcp, in order.
+func (c imageConfig) Ports() []string {
+	ports := make([]string, 0, len(c.ExposedPorts))
+	for port := range c.ExposedPorts {
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	return ports
+}
+
+// imageDetailCache caches detail pages' inspect results by page and image
+// ID, so revisiting an image or the periodic page refresh doesn't inspect it
+// again. Only the visited images are inspected.
+type imageDetailCache struct {
+	mu      sync.Mutex
+	details map[string]imageDetail
+}
+
+func newImageDetailCache() *imageDetailCache {
+	return &imageDetailCache{details: map[string]imageDetail{}}
+}
+
+// Get returns the cached detail for id on page, running inspect on a miss.
+// Failed inspects are not cached.
+func (c *imageDetailCache) Get(page, id string, inspect func(string) (imageDetail, error)) (imageDetail, error) {
+	key := page + "/" + id
+	c.mu.Lock()
+	detail, ok := c.details[key]
+	c.mu.Unlock()
+	if ok {
+		return detail, nil
+	}
+
+	detail, err := inspect(id)
+	if err != nil {
+		return detail, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.details[key] = detail
+	return detail, nil
+}
+
+// Reset drops every cached detail, e.g. after images were deleted.
+func (c *imageDetailCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.details = map[string]imageDetail{}
+}
+
+// imageDetail is everything the detail page shows about an image.
+type imageDetail struct {
+	ID           string
+	RepoTags     []string
+	RepoDigests  []string
+	Created      time.Time
+	Size         int64
+	Architecture string
+	OS           string
+	Config       imageConfig
+	Layers       []string
+}
+
+type dockerImageDetail struct {
+	ID           string    `json:"Id"`
+	RepoTags     []string  `json:"RepoTags"`
+	RepoDigests  []string  `json:"RepoDigests"`
+	Created      time.Time `json:"Created"`
+	Size         int64     `json:"Size"`
+	Architecture string    `json:"Architecture"`
+	OS           string    `json:"Os"`
+	Config       imageConfig
+	RootFS       struct {
+		Layers []string `json:"Layers"`
+	} `json:"RootFS"`
+}
+
+type kindImageDetail struct {
+	Status kindImage `json:"status"`
+	Info   struct {
+		ImageSpec struct {
+			imageSpec
+			Config imageConfig `json:"config"`
+			RootFS struct {
+				DiffIDs []string `json:"diff_ids"`
+			} `json:"rootfs"`
+		} `json:"imageSpec"`
+	} `json:"info"`
+}
+
+// inspectDockerImage returns the details of a single docker image.
+func inspectDockerImage(id string) (imageDetail, error) {
+	// docker image inspect {{id}}
+	cmd := exec.Command(host.Name(), "image", "inspect", id)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return imageDetail{}, fmt.Errorf("inspectDockerImage %s: %w: %s", id, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var inspected []dockerImageDetail
+	if err := json.Unmarshal(stdout.Bytes(), &inspected); err != nil {
+		return imageDetail{}, fmt.Errorf("inspectDockerImage %s: %w", id, err)
+	}
+	if len(inspected) == 0 {
+		return imageDetail{}, fmt.Errorf("inspectDockerImage %s: image not found", id)
+	}
+
+	d := inspected[0]
+	return imageDetail{
+		ID:           d.ID,
+		RepoTags:     d.RepoTags,
+		RepoDigests:  d.RepoDigests,
+		Created:      d.Created,
+		Size:         d.Size,
+		Architecture: d.Architecture,
+		OS:           d.OS,
+		Config:       d.Config,
+		Layers:       d.RootFS.Layers,
+	}, nil
+}
+
+// inspectKindImage returns the details of a single image on the kind node.
+func inspectKindImage(id string) (imageDetail, error) {
+	// docker exec {{kindNode}} crictl inspecti --output=json {{id}}
+	cmd := crictlCommand(kindNode, "inspecti", "--output=json", id)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return imageDetail{}, fmt.Errorf("inspectKindImage %s: %w: %s", id, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var d kindImageDetail
+	if err := json.Unmarshal(stdout.Bytes(), &d); err != nil {
+		return imageDetail{}, fmt.Errorf("inspectKindImage %s: %w", id, err)
+	}
+
+	spec := d.Info.ImageSpec
+	size, _ := strconv.ParseInt(d.Status.Size, 10, 64)
+	return imageDetail{
+		ID:           d.Status.ID,
+		RepoTags:     d.Status.RepoTags,
+		RepoDigests:  d.Status.RepoDigests,
+		Created:      spec.Created,
+		Size:         size,
+		Architecture: spec.Architecture,
+		OS:           spec.OS,
+		Config:       spec.Config,
+		Layers:       spec.RootFS.DiffIDs,
+	}, nil
+}
+
+// pluginPath returns the link to a page of this plugin.
+func pluginPath(parts ...string) string {
+	escaped := []string{names.Plugin}
+	for _, part := range parts {
+		// Repository names keep their slashes as path segments, anything
+		// else, such as the colon of a registry port, is escaped.
+		for _, segment := range strings.Split(part, "/") {
+			escaped = append(escaped, url.PathEscape(segment))
+		}
+	}
+	return "/" + path.Join(escaped...)
+}
+
+// routeValue returns the value a plugin page was requested for, everything
+// after the page name, unescaped.
+func routeValue(request service.Request, page string) (string, error) {
+	value := strings.TrimPrefix(strings.TrimPrefix(request.Path(), "/"), page+"/")
+	unescaped, err := url.PathUnescape(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s path %q: %w", page, value, err)
+	}
+	return unescaped, nil
+}
+
+// imageIDLink links an image ID to its detail page.
+func imageIDLink(page, id string) component.Component {
+	return component.NewLink("", id, pluginPath(page, id))
+}
+
+func (i *imagePlugin) handleDockerImage(request service.Request) (component.ContentResponse, error) {
+	id, err := routeImageID(request, dockerImagePath)
+	if err != nil {
+		return imageDetailResponse(id, imageDetail{}, nil, err), nil
+	}
+	detail, err := i.details.Get(dockerImagePath, id, inspectDockerImage)
+	response := imageDetailResponse(id, detail, dockerCommands(detail), err)
+	if err == nil {
+		history := flexlayout.New()
+		historySection := history.AddSection()
+		historySection.Add(imageHistoryLink("Show how each layer was built and its size", id), component.WidthFull)
+		response.Add(history.ToComponent("History"))
+	}
+	if sample, ok := i.loadTimes.Latest(detail.ID, detail.RepoTags...); ok && err == nil {
+		response.Add(loadTimeView(sample))
+	}
+	if result, ok := i.scans.Get(id); ok {
+		response.Add(scanFindingsView(result))
+	}
+	return response, nil
+}
+
+func (i *imagePlugin) handleKindImage(request service.Request) (component.ContentResponse, error) {
+	id, err := routeImageID(request, kindImagePath)
+	if err != nil {
+		return imageDetailResponse(id, imageDetail{}, nil, err), nil
+	}
+	detail, err := i.details.Get(kindImagePath, id, inspectKindImage)
+	response := imageDetailResponse(id, detail, kindCommands(detail), err)
+	if err == nil {
+		pulls, _ := i.policies.Get(request.Context(), request.DashboardClient())
+		if p := imagePulls(pulls, detail.RepoTags); len(p) > 0 {
+			response.Add(pullPolicyView(p))
+		}
+	}
+	return response, nil
+}
+
+// routeImageID returns the image a detail page was requested for, refusing
+// anything that is not an image reference before it is passed to a command.
+func routeImageID(request service.Request, page string) (string, error) {
+	id, err := routeValue(request, page)
+	if err != nil {
+		return "", err
+	}
+	return id, validateReference(id)
+}
+
+// dockerCommands are the CLI equivalents of the actions on a host image, for
+// the configured runtime and cluster.
+func dockerCommands(detail imageDetail) []string {
+	refs := detail.RepoTags
+	if len(refs) == 0 {
+		refs = []string{detail.ID}
+	}
+	var commands []string
+	for _, ref := range refs {
+		commands = append(commands, host.LoadCommand(ref))
+	}
+	return append(commands, commandLine(exec.Command(host.Name(), append([]string{"image", "rm"}, refs...)...)))
+}
+
+// kindCommands are the CLI equivalents of pulling and deleting a kind image
+// on the configured node.
+func kindCommands(detail imageDetail) []string {
+	var commands []string
+	for _, repoTag := range detail.RepoTags {
+		commands = append(commands, commandLine(crictlCommand(kindNode, "pull", repoTag)))
+	}
+	return append(commands, commandLine(crictlCommand(kindNode, "rmi", detail.ID)))
+}
+
+func imageDetailResponse(id string, detail imageDetail, commands []string, err error) component.ContentResponse {
+	contentResponse := component.NewContentResponse(component.TitleFromString(fmt.Sprintf("Image %s", id)))
+
+	layout := flexlayout.New()
+	if err != nil {
+		addErrorSection(layout, err)
+		contentResponse.Add(layout.ToComponent("Image"))
+		return *contentResponse
+	}
+
+	summary := component.NewSummary("Details")
+	summary.AddSection("ID", component.NewText(detail.ID))
+	summary.AddSection("Repo Tags", textList(detail.RepoTags))
+	summary.AddSection("Repo Digests", textList(detail.RepoDigests))
+	if !detail.Created.IsZero() {
+		summary.AddSection("Created", component.NewTimestamp(detail.Created))
+	}
+	summary.AddSection("Size", component.NewText(formatBytes(detail.Size)))
+	summary.AddSection("Platform", component.NewText(detail.OS+"/"+detail.Architecture))
+	summary.AddSection("Entrypoint", component.NewText(strings.Join(detail.Config.Entrypoint, " ")))
+	summary.AddSection("Command", component.NewText(strings.Join(detail.Config.Cmd, " ")))
+	summary.AddSection("Working Directory", component.NewText(detail.Config.WorkingDir))
+	summary.AddSection("User", component.NewText(detail.Config.User))
+	summary.AddSection("Exposed Ports", textList(detail.Config.Ports()))
+	summary.AddSection(fmt.Sprintf("Environment (%d)", len(detail.Config.Env)), textList(detail.Config.Env))
+
+	labels := make([]string, 0, len(detail.Config.Labels))
+	for key, value := range detail.Config.Labels {
+		labels = append(labels, key+"="+value)
+	}
+	sort.Strings(labels)
+	summary.AddSection("Labels", textList(labels))
+	if found := provenance(detail.Config.Labels); len(found) > 0 {
+		summary.AddSection("Provenance", textList(found))
+	}
+
+	layers := component.NewTable("Layers", "No layers", component.NewTableCols("#", "Digest"))
+	for n, layer := range detail.Layers {
+		layers.Add(component.TableRow{
+			"#":      component.NewText(strconv.Itoa(n + 1)),
+			"Digest": component.NewText(layer),
+		})
+	}
+
+	detailSection := layout.AddSection()
+	detailSection.Add(summary, component.WidthFull)
+	commandSection := layout.AddSection()
+	commandSection.Add(component.NewText("Commands"), component.WidthFull)
+	commandSection.Add(component.NewCodeBlock(strings.Join(commands, "\n")), component.WidthFull)
+
+	// Shown as a code block so the references can be selected and copied into manifests.
+	if len(detail.RepoDigests) > 0 {
+		digestSection := layout.AddSection()
+		digestSection.Add(component.NewText("Pull by digest"), component.WidthFull)
+		digestSection.Add(component.NewCodeBlock(strings.Join(detail.RepoDigests, "\n")), component.WidthFull)
+	}
+	layerSection := layout.AddSection()
+	layerSection.Add(layers, component.WidthFull)
+
+	contentResponse.Add(layout.ToComponent("Image"))
+	return *contentResponse
+}
+
+// textList renders values one per line, or a dash when there are none.
+func textList(values []string) component.Component {
+	if len(values) == 0 {
+		return component.NewText("—")
+	}
+	return component.NewText(strings.Join(values, "\n"))
+}
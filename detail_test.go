@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+)
+
+// pathRequest is a page request for a path, as Octant scopes it to the plugin.
+type pathRequest string
+
+func (r pathRequest) Context() context.Context           { return context.Background() }
+func (r pathRequest) DashboardClient() service.Dashboard { return nil }
+func (r pathRequest) Path() string                       { return string(r) }
+
+// handlerPointer identifies the method value h, to tell which route matched.
+func handlerPointer(h service.HandleFunc) uintptr {
+	return reflect.ValueOf(h).Pointer()
+}
+
+func TestImageRoutesRoundTrip(t *testing.T) {
+	p := &imagePlugin{}
+	router := service.NewRouter()
+	p.initRoutes(router)
+
+	tests := []struct {
+		page    string
+		handler service.HandleFunc
+	}{
+		{page: dockerImagePath, handler: p.handleDockerImage},
+		{page: kindImagePath, handler: p.handleKindImage},
+		{page: imageHistoryPath, handler: p.handleImageHistory},
+	}
+	refs := []string{
+		"localhost:5001/team/app:v1.2",
+		"nginx",
+		"registry.example.com/team/app@" + testDigest,
+		testImageID,
+	}
+	for _, test := range tests {
+		for _, ref := range refs {
+			link := pluginPath(test.page, ref)
+			path := strings.TrimPrefix(link, "/"+names.Plugin)
+			handler, ok := router.Match(path)
+			if !ok {
+				t.Errorf("%s: no route matches %s", ref, path)
+				continue
+			}
+			if handlerPointer(handler) != handlerPointer(test.handler) {
+				t.Errorf("%s: %s is not routed to the %s page", ref, path, test.page)
+			}
+			got, err := routeImageID(pathRequest(path), test.page)
+			if err != nil {
+				t.Errorf("%s: routeImageID(%s): %s", ref, path, err)
+			} else if got != ref {
+				t.Errorf("%s: routeImageID(%s) = %q", ref, path, got)
+			}
+		}
+	}
+}
+
+func TestImageRoutesRefuseShellCharacters(t *testing.T) {
+	for _, ref := range []string{
+		"nginx;rm -rf ~",
+		"$(reboot)",
+		"app:v1`id`",
+		"app:v1 && curl evil.example",
+		"app|sh",
+		"app:v1\nrm",
+	} {
+		path := strings.TrimPrefix(pluginPath(dockerImagePath, ref), "/"+names.Plugin)
+		if got, err := routeImageID(pathRequest(path), dockerImagePath); err == nil {
+			t.Errorf("routeImageID(%s) = %q, want an error", path, got)
+		}
+	}
+
+	if _, err := routeImageID(pathRequest("/"+dockerImagePath+"/nginx%zz"), dockerImagePath); err == nil {
+		t.Errorf("routeImageID accepted a path that isn't escaped properly")
+	}
+
+	for _, path := range []string{"/tmp/app;rm -rf ~.tar", "/tmp/$(id).tar", "/tmp/app`id`.tar", "/tmp/app\n.tar"} {
+		if err := validatePayloadPath(path); err == nil {
+			t.Errorf("validatePayloadPath(%q) accepted it", path)
+		}
+	}
+	if err := validatePayloadPath("/tmp/my images/app.tar"); err != nil {
+		t.Errorf("validatePayloadPath refused a path with spaces: %s", err)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const notInKindPath = "not-in-kind"
+
+// notInKind returns the tagged host images whose repo:tag is not present in
+// kind, in the order docker lists them.
+func notInKind(images []dockerImage, loaded kindImages) []dockerImage {
+	inKind := map[string]bool{}
+	for _, image := range loaded.Images {
+		for _, repoTag := range image.RepoTags {
+			inKind[normalizeReference(repoTag)] = true
+		}
+	}
+
+	var missing []dockerImage
+	for _, image := range images {
+		// Untagged images can't be compared by repo:tag.
+		if image.Reference() == image.ID {
+			continue
+		}
+		if !inKind[normalizeReference(image.Reference())] {
+			missing = append(missing, image)
+		}
+	}
+	return missing
+}
+
+func (i *imagePlugin) handleNotInKind(request service.Request) (component.ContentResponse, error) {
+	contentResponse := component.NewContentResponse(component.TitleFromString("Not in Kind"))
+	contentResponse.Add(i.notInKindView())
+	return *contentResponse, nil
+}
+
+// notInKindView lists the host images that still need to be loaded into the
+// cluster, so a build can be followed by loading just the changes.
+func (i *imagePlugin) notInKindView() *component.FlexLayout {
+	table := component.NewTable("Not in Kind", "Every tagged image is loaded into kind",
+		component.NewTableCols("Reference", "Image ID", "Created", "Size", "Architecture"))
+
+	layout := flexlayout.New()
+	i.addMissingToolsSection(layout)
+	addDryRunSection(layout)
+	if err := i.feedback.Get(); err != nil {
+		addErrorSection(layout, err)
+	}
+
+	if i.hasTool(host.Name()) {
+		images, err := listDockerImages()
+		if err != nil {
+			addErrorSection(layout, err)
+		}
+		loaded, err := listKindImages()
+		if err != nil {
+			addErrorSection(layout, err)
+		}
+
+		// Without the kind listing every image would look missing.
+		if loaded.Backend != "" {
+			missing := notInKind(images, loaded)
+
+			var ids []string
+			for _, image := range missing {
+				ids = append(ids, image.ID)
+			}
+			inspects, err := i.inspects.Get(ids)
+			if err != nil {
+				log.Printf("unable to inspect docker images: %s", err)
+			}
+			nodeArch, err := nodeArchitecture(kindNode)
+			if err != nil {
+				log.Printf("unable to determine kind node architecture: %s", err)
+			}
+
+			for _, image := range missing {
+				table.Add(notInKindPrinter(image, inspects[image.ID], nodeArch))
+			}
+		}
+	}
+
+	if current, pending := i.queue.Snapshot(); current != nil {
+		loadingSection := layout.AddSection()
+		loadingSection.Add(component.NewTextf("Loading %s into kind (%d queued)...", current.ImageID, len(pending)), component.WidthFull)
+	} else {
+		addStatusSection(layout, i.progress)
+	}
+
+	diffSection := layout.AddSection()
+	diffSection.Add(table, component.WidthFull)
+
+	view := layout.ToComponent("Not in Kind")
+	view.SetAccessor(notInKindPath)
+	return view
+}
+
+func notInKindPrinter(image dockerImage, inspect dockerInspect, nodeArch string) component.TableRow {
+	row := component.TableRow{}
+	row["Reference"] = component.NewText(image.Reference())
+	row["Image ID"] = imageIDLink(dockerImagePath, image.ID)
+	if created, err := image.Created(); err == nil {
+		row["Created"] = component.NewTimestamp(created)
+	} else {
+		row["Created"] = component.NewText(fmt.Sprintf("%s", image.CreatedSince))
+	}
+	row["Size"] = component.NewText(displaySize(image.Size))
+
+	loadGridAction := component.GridAction{
+		Name:       "Load into Kind",
+		ActionPath: names.Load,
+		Payload: action.Payload{
+			"action":  names.Load,
+			"imageID": image.Reference(),
+		},
+		Type: component.GridActionPrimary,
+	}
+
+	arch := component.NewText(inspect.Platform())
+	if inspect.Architecture != "" && nodeArch != "" && inspect.Architecture != nodeArch {
+		arch = component.NewTextf("%s (kind node is %s)", inspect.Platform(), nodeArch)
+		arch.SetStatus(component.TextStatusWarning)
+		loadGridAction.Confirmation = &component.Confirmation{
+			Title: "Load into Kind?",
+			Body: fmt.Sprintf("%s is built for %s but the kind node is %s, so containers using it will fail with exec format errors. "+
+				"Do you want to continue?", image.Reference(), inspect.Architecture, nodeArch),
+		}
+	}
+	row["Architecture"] = arch
+
+	row.AddAction(loadGridAction)
+	return row
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// filesystemUsage is one filesystem in crictl imagefsinfo output. The numbers
+// are protobuf uint64 values, which crictl prints as strings.
+type filesystemUsage struct {
+	UsedBytes struct {
+		Value json.Number `json:"value"`
+	} `json:"usedBytes"`
+	InodesUsed struct {
+		Value json.Number `json:"value"`
+	} `json:"inodesUsed"`
+}
+
+// imageFsInfo accepts both crictl imagefsinfo formats: older releases print
+// a single filesystem as the status, newer ones a list of image filesystems.
+type imageFsInfo struct {
+	Status struct {
+		filesystemUsage
+		ImageFilesystems []filesystemUsage `json:"imageFilesystems"`
+	} `json:"status"`
+}
+
+// nodeUsage is the image disk usage of a kind node.
+type nodeUsage struct {
+	Node      string
+	UsedBytes int64
+	Inodes    int64
+	FreeBytes int64
+	Err       error
+}
+
+// nodeImageUsage returns the bytes and inodes used by a node's images.
+func nodeImageUsage(node string) (int64, int64, error) {
+	// docker exec {{node}} crictl imagefsinfo
+	cmd := crictlCommand(node, "imagefsinfo")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return 0, 0, commandError("crictl imagefsinfo", err, stderr.String())
+	}
+
+	var info imageFsInfo
+	decoder := json.NewDecoder(&stdout)
+	decoder.UseNumber()
+	if err := decoder.Decode(&info); err != nil {
+		return 0, 0, fmt.Errorf("nodeImageUsage %s: could not parse crictl imagefsinfo output: %w", node, err)
+	}
+
+	filesystems := info.Status.ImageFilesystems
+	if len(filesystems) == 0 {
+		filesystems = []filesystemUsage{info.Status.filesystemUsage}
+	}
+	var used, inodes int64
+	for _, fs := range filesystems {
+		// Missing values decode as empty numbers and count as zero.
+		n, _ := strconv.ParseInt(fs.UsedBytes.Value.String(), 10, 64)
+		used += n
+		n, _ = strconv.ParseInt(fs.InodesUsed.Value.String(), 10, 64)
+		inodes += n
+	}
+	return used, inodes, nil
+}
+
+// diskUsageCache keeps the per node usage for healthTTL, like the status probes.
+type diskUsageCache struct {
+	mu      sync.Mutex
+	checked time.Time
+	usage   []nodeUsage
+}
+
+func (c *diskUsageCache) Get() []nodeUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked) <= healthTTL {
+		return c.usage
+	}
+
+	nodes, err := listKindNodes()
+	if err != nil {
+		nodes = []string{kindNode}
+	}
+	var usage []nodeUsage
+	for _, node := range nodes {
+		u := nodeUsage{Node: node}
+		u.UsedBytes, u.Inodes, u.Err = nodeImageUsage(node)
+		if u.Err == nil {
+			u.FreeBytes, u.Err = nodeAvailableBytes(node)
+		}
+		usage = append(usage, u)
+	}
+	c.usage = usage
+	c.checked = time.Now()
+	return usage
+}
+
+// Reset makes the next Get query the nodes again.
+func (c *diskUsageCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checked = time.Time{}
+}
+
+// addDiskUsageSection shows how much space images take on each node and how
+// much is left, e.g. "kind-worker: 6.2 GiB images / 58.0 GiB free".
+func addDiskUsageSection(layout *flexlayout.FlexLayout, usage []nodeUsage) {
+	if len(usage) == 0 {
+		return
+	}
+
+	summary := component.NewSummary("Node Disk Usage")
+	for _, u := range usage {
+		if u.Err != nil {
+			summary.AddSection(u.Node, component.NewText("unavailable"))
+			continue
+		}
+		text := component.NewTextf("%s images (%d inodes) / %s free", formatBytes(u.UsedBytes), u.Inodes, formatBytes(u.FreeBytes))
+		if u.FreeBytes < largeImageThreshold {
+			text.SetStatus(component.TextStatusWarning)
+		}
+		summary.AddSection(u.Node, text)
+	}
+	usageSection := layout.AddSection()
+	usageSection.Add(summary, component.WidthFull)
+}
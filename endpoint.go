@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// dockerEndpoint is the daemon the docker CLI talks to.
+type dockerEndpoint struct {
+	// Context is the docker context name, or empty when DOCKER_HOST is set.
+	Context string
+	Host    string
+}
+
+// endpoint is resolved once at startup; see pinDockerEndpoint.
+var endpoint *dockerEndpoint
+
+// pinDockerEndpoint resolves the active docker context and pins it with
+// DOCKER_CONTEXT, so every docker and kind command the plugin runs talks to the
+// same daemon even if the default context is switched while Octant runs.
+func pinDockerEndpoint() (*dockerEndpoint, error) {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return &dockerEndpoint{Host: host}, nil
+	}
+
+	// docker context inspect
+	cmd := exec.Command("docker", "context", "inspect")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, fmt.Errorf("pinDockerEndpoint: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var contexts []struct {
+		Name      string
+		Endpoints map[string]struct {
+			Host string
+		}
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &contexts); err != nil {
+		return nil, fmt.Errorf("pinDockerEndpoint: %w", err)
+	}
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("pinDockerEndpoint: no docker context found")
+	}
+
+	current := contexts[0]
+	if err := os.Setenv("DOCKER_CONTEXT", current.Name); err != nil {
+		return nil, fmt.Errorf("pinDockerEndpoint: %w", err)
+	}
+	return &dockerEndpoint{Context: current.Name, Host: current.Endpoints["docker"].Host}, nil
+}
+
+// Remote reports whether the daemon is on another machine. Unix sockets and
+// named pipes are always local.
+func (e dockerEndpoint) Remote() bool {
+	u, err := url.Parse(e.Host)
+	if err != nil || u.Scheme == "unix" || u.Scheme == "npipe" {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return false
+	}
+	return true
+}
+
+func (e dockerEndpoint) String() string {
+	if e.Context == "" {
+		return fmt.Sprintf("DOCKER_HOST %s", e.Host)
+	}
+	return fmt.Sprintf("context %s (%s)", e.Context, e.Host)
+}
+
+// addEndpointSection shows which docker daemon is in use, and warns when it
+// is remote but the kind cluster cannot be found on it, which is what makes
+// kind load fail.
+func addEndpointSection(layout *flexlayout.FlexLayout) {
+	if endpoint == nil {
+		return
+	}
+
+	text := component.NewTextf("Docker endpoint: %s", endpoint)
+	if endpoint.Remote() {
+		if nodes, err := listKindNodes(); err != nil || len(nodes) == 0 {
+			text = component.NewTextf("Docker endpoint: %s is a remote daemon, but kind cluster %s was not found on it. "+
+				"kind load copies images into node containers on the same daemon, so loads will fail. "+
+				"Switch to the context your kind cluster runs on and restart Octant.", endpoint, kindCluster)
+			text.SetStatus(component.TextStatusWarning)
+		}
+	}
+	endpointSection := layout.AddSection()
+	endpointSection.Add(text, component.WidthFull)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const environmentPath = "environment"
+
+// minKindVersion is the oldest kind release the plugin is known to work with.
+var minKindVersion = [3]int{0, 8, 0}
+
+// toolVersion is one row of the environment diagnostics.
+type toolVersion struct {
+	Tool    string
+	Version string
+	Problem string
+}
+
+// environment runs the version checks once and keeps the results, since
+// tool versions do not change while Octant runs.
+type environment struct {
+	once     sync.Once
+	versions []toolVersion
+}
+
+func (e *environment) Versions() []toolVersion {
+	e.once.Do(func() {
+		e.versions = checkEnvironment()
+	})
+	return e.versions
+}
+
+func checkEnvironment() []toolVersion {
+	var versions []toolVersion
+
+	runtime := toolVersion{Tool: host.Name()}
+	if client, server, err := runtimeVersion(); err != nil {
+		runtime.Problem = err.Error()
+	} else {
+		runtime.Version = fmt.Sprintf("client %s, server %s", client, server)
+	}
+	versions = append(versions, runtime)
+
+	kind := toolVersion{Tool: "kind"}
+	if version, err := detectKindVersion(); err != nil {
+		kind.Problem = err.Error()
+	} else {
+		kind.Version = version
+		if older(version, minKindVersion) {
+			kind.Problem = fmt.Sprintf("kind v%d.%d.%d or newer is recommended", minKindVersion[0], minKindVersion[1], minKindVersion[2])
+		}
+	}
+	versions = append(versions, kind)
+
+	clusters := toolVersion{Tool: "kind clusters"}
+	if names, err := listKindClusters(); err != nil {
+		clusters.Problem = err.Error()
+	} else if len(names) == 0 {
+		clusters.Problem = "no kind clusters exist, create one with kind create cluster"
+	} else {
+		clusters.Version = strings.Join(names, ", ")
+	}
+	versions = append(versions, clusters)
+
+	crictl := toolVersion{Tool: "crictl on " + kindNode}
+	if version, err := crictlVersion(); err != nil {
+		crictl.Problem = err.Error()
+	} else {
+		crictl.Version = version
+	}
+	versions = append(versions, crictl)
+
+	return versions
+}
+
+func runtimeVersion() (string, string, error) {
+	// docker version --format={{json .}}
+	cmd := exec.Command(host.Name(), "version", "--format={{json .}}")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := runCommand(cmd)
+
+	// The client version is printed even when the daemon is unreachable.
+	var version struct {
+		Client *struct{ Version string }
+		Server *struct{ Version string }
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &version); err != nil || version.Client == nil {
+		if runErr != nil {
+			return "", "", commandError(host.Name()+" version", runErr, stderr.String())
+		}
+		return "", "", fmt.Errorf("could not parse %s version output", host.Name())
+	}
+	if version.Server == nil {
+		return version.Client.Version, "", fmt.Errorf("client %s cannot reach the daemon: %s", version.Client.Version, strings.TrimSpace(stderr.String()))
+	}
+	return version.Client.Version, version.Server.Version, nil
+}
+
+func kindVersion() (string, error) {
+	// kind version
+	cmd := exec.Command("kind", "version")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return "", commandError("kind version", err, stderr.String())
+	}
+
+	// e.g. "kind v0.8.1 go1.14.2 linux/amd64"
+	fields := strings.Fields(stdout.String())
+	if len(fields) < 2 {
+		return "", fmt.Errorf("could not parse kind version %q", stdout.String())
+	}
+	return fields[1], nil
+}
+
+func crictlVersion() (string, error) {
+	// docker exec {{kindNode}} crictl --version
+	cmd := crictlCommand(kindNode, "--version")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return "", commandError("crictl --version", err, stderr.String())
+	}
+	return strings.TrimSpace(strings.TrimPrefix(stdout.String(), "crictl version")), nil
+}
+
+// older reports whether a version such as v0.7.0 or v0.8.0-alpha is older
+// than min. Unparseable versions are not reported as older.
+func older(version string, min [3]int) bool {
+	parsed, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+	for n := range parsed {
+		if parsed[n] != min[n] {
+			return parsed[n] < min[n]
+		}
+	}
+	return false
+}
+
+// parseVersion parses a version such as v1.27.3 or 0.8.0-alpha, ignoring
+// pre-release and build suffixes.
+func parseVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return parsed, false
+	}
+	for n, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, false
+		}
+		parsed[n] = v
+	}
+	return parsed, true
+}
+
+// environmentView renders the diagnostics. missing are the tools not found
+// on the PATH, which get install instructions, warnings are problems found
+// with the kind clusters, and state describes the UI state file.
+func environmentView(versions []toolVersion, missing, warnings []string, state, api string) *component.FlexLayout {
+	layout := flexlayout.New()
+	if len(missing) > 0 {
+		text := component.NewTextf("Required tools not found on the PATH Octant was started from: %s. "+
+			"Install them (see https://docs.docker.com/get-docker/ and https://kind.sigs.k8s.io/docs/user/quick-start/#installation), "+
+			"and make sure they are on the PATH. The plugin checks again every %s.", strings.Join(missing, ", "), healthTTL)
+		text.SetStatus(component.TextStatusError)
+		missingSection := layout.AddSection()
+		missingSection.Add(text, component.WidthFull)
+	}
+	for _, warning := range warnings {
+		text := component.NewText(warning)
+		text.SetStatus(component.TextStatusWarning)
+		warningSection := layout.AddSection()
+		warningSection.Add(text, component.WidthFull)
+	}
+
+	table := component.NewTable("Environment", "No checks run", component.NewTableCols("Tool", "Version", "Status"))
+	for _, v := range versions {
+		status := component.NewText("OK")
+		status.SetStatus(component.TextStatusOK)
+		if v.Problem != "" {
+			status = component.NewText(v.Problem)
+			status.SetStatus(component.TextStatusWarning)
+		}
+		table.Add(component.TableRow{
+			"Tool":    component.NewText(v.Tool),
+			"Version": component.NewText(v.Version),
+			"Status":  status,
+		})
+	}
+	environmentSection := layout.AddSection()
+	environmentSection.Add(table, component.WidthFull)
+
+	config := component.NewTable("Configuration", "No settings", component.NewTableCols("Flag", "Environment Variable", "Value"))
+	for _, option := range configOptions() {
+		value := option.Value()
+		if option.Effective != nil {
+			value = option.Effective()
+		}
+		config.Add(component.TableRow{
+			"Flag":                 component.NewText("--" + option.Flag),
+			"Environment Variable": component.NewText(option.Env),
+			"Value":                component.NewText(value),
+		})
+	}
+	configSection := layout.AddSection()
+	configSection.Add(config, component.WidthFull)
+
+	stateSummary := component.NewSummary("UI State")
+	stateSummary.AddSection("State File", component.NewText(state))
+	stateSection := layout.AddSection()
+	stateSection.Add(stateSummary, component.WidthFull)
+
+	if api == "" {
+		api = "disabled, enable it with --api-listen=127.0.0.1:0"
+	}
+	apiSummary := component.NewSummary("JSON API")
+	apiSummary.AddSection("Address", component.NewText(api))
+	apiSection := layout.AddSection()
+	apiSection.Add(apiSummary, component.WidthFull)
+
+	view := layout.ToComponent("Environment")
+	view.SetAccessor(environmentPath)
+	return view
+}
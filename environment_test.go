@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    [3]int
+		ok      bool
+	}{
+		{version: "v1.27.3", want: [3]int{1, 27, 3}, ok: true},
+		{version: "1.27.3", want: [3]int{1, 27, 3}, ok: true},
+		{version: "v0.8.0-alpha", want: [3]int{0, 8, 0}, ok: true},
+		{version: "v1.30.0+k3s1", want: [3]int{1, 30, 0}, ok: true},
+		{version: "24.0.7", want: [3]int{24, 0, 7}, ok: true},
+		{version: "v1.27"},
+		{version: "latest"},
+		{version: "v1.x.3"},
+		{version: ""},
+	}
+	for _, test := range tests {
+		got, ok := parseVersion(test.version)
+		if ok != test.ok || (ok && got != test.want) {
+			t.Errorf("parseVersion(%q) = %v, %t, want %v, %t", test.version, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestOlder(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "v0.7.0", want: true},
+		{version: "v0.8.0-alpha", want: false},
+		{version: "v0.8.0", want: false},
+		{version: "v0.20.0", want: false},
+		{version: "unknown", want: false},
+	}
+	for _, test := range tests {
+		if got := older(test.version, [3]int{0, 8, 0}); got != test.want {
+			t.Errorf("older(%q, v0.8.0) = %t, want %t", test.version, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shellSafePattern matches arguments that need no quoting in a shell.
+var shellSafePattern = regexp.MustCompile(`^[a-zA-Z0-9_@%+=:,./-]+$`)
+
+// verbose logs every command the plugin runs with its duration and exit
+// code, set with KIND_REGISTRY_VERBOSE.
+var verbose bool
+
+// secretFlags are the flags whose value is a credential, such as crictl
+// pull's --creds and --auth.
+var secretFlags = []string{"--creds", "--auth"}
+
+// redactedArgs returns the arguments of cmd with the values of secretFlags
+// left out, for logs and messages.
+func redactedArgs(cmd *exec.Cmd) []string {
+	args := make([]string, len(cmd.Args))
+	copy(args, cmd.Args)
+	for n := range args {
+		if n > 0 && contains(secretFlags, args[n-1]) {
+			args[n] = "<redacted>"
+		}
+	}
+	return args
+}
+
+// commandLine renders cmd for logs and messages. Arguments a shell would
+// split or interpret are single quoted, so the line can be pasted into one.
+// Credentials are left out.
+func commandLine(cmd *exec.Cmd) string {
+	args := redactedArgs(cmd)
+	for n, arg := range args {
+		args[n] = shellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes arg for a POSIX shell unless it only has characters no
+// shell treats specially.
+func shellQuote(arg string) string {
+	if arg != "" && shellSafePattern.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// maxProcesses caps how many commands run at once, set with
+// --max-processes. Zero removes the cap. Loads and other operations that
+// stream their output are not counted, so a render never waits for them.
+var maxProcesses = 4
+
+var (
+	processSlots     chan struct{}
+	processSlotsOnce sync.Once
+)
+
+// acquireProcess waits until fewer than maxProcesses commands run and
+// returns the func that frees the slot again.
+func acquireProcess() func() {
+	processSlotsOnce.Do(func() {
+		if maxProcesses > 0 {
+			processSlots = make(chan struct{}, maxProcesses)
+		}
+	})
+	if processSlots == nil {
+		return func() {}
+	}
+	processSlots <- struct{}{}
+	return func() { <-processSlots }
+}
+
+// runCommand runs cmd like cmd.Run, logging it when verbose is set. It
+// waits for a slot when maxProcesses commands are already running.
+func runCommand(cmd *exec.Cmd) error {
+	release := acquireProcess()
+	defer release()
+	started := time.Now()
+	err := cmd.Run()
+	logCommand(cmd, started)
+	return err
+}
+
+// logCommand logs a finished command when verbose is set. Credentials
+// passed with secretFlags are left out.
+func logCommand(cmd *exec.Cmd, started time.Time) {
+	if !verbose {
+		return
+	}
+	args := redactedArgs(cmd)
+	code := -1
+	if cmd.ProcessState != nil {
+		code = cmd.ProcessState.ExitCode()
+	}
+	log.Printf("exec %s: exit code %d after %s", strings.Join(args, " "), code, time.Since(started).Round(time.Millisecond))
+}
+
+// streamCommand runs cmd, passing each line of its combined output to onLine.
+// Cancelling ctx kills the command's whole process group, since tools like
+// kind shell out to docker themselves.
+func streamCommand(ctx context.Context, cmd *exec.Cmd, onLine func(string)) error {
+	setProcessGroup(cmd)
+	started := time.Now()
+	defer func() { logCommand(cmd, started) }()
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	inflight.AddProcess(ctx, cmd.Process.Pid)
+
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.Close()
+		done <- err
+	}()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			if err := killProcessGroup(cmd); err != nil {
+				log.Printf("failed to kill %s: %s", cmd.Path, err)
+			}
+		case <-stop:
+		}
+	}()
+
+	scanner := bufio.NewScanner(pr)
+	for scanner.Scan() {
+		onLine(scanner.Text())
+	}
+	// Drain anything left if the scanner gave up on an overly long line.
+	io.Copy(ioutil.Discard, pr)
+
+	if err := <-done; err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// runContext runs cmd like cmd.Run, killing its process group when ctx is
+// cancelled. It is for commands built without exec.CommandContext.
+func runContext(ctx context.Context, cmd *exec.Cmd) error {
+	setProcessGroup(cmd)
+	started := time.Now()
+	defer func() { logCommand(cmd, started) }()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	inflight.AddProcess(ctx, cmd.Process.Pid)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if err := killProcessGroup(cmd); err != nil {
+			log.Printf("failed to kill %s: %s", cmd.Path, err)
+		}
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// resetProcessSlots makes the next acquireProcess size the slots for max,
+// returning the func that restores the configured cap.
+func resetProcessSlots(max int) func() {
+	saved := maxProcesses
+	maxProcesses = max
+	processSlots, processSlotsOnce = nil, sync.Once{}
+	return func() {
+		maxProcesses = saved
+		processSlots, processSlotsOnce = nil, sync.Once{}
+	}
+}
+
+func TestAcquireProcessCapsRunningCommands(t *testing.T) {
+	defer resetProcessSlots(2)()
+	first, second := acquireProcess(), acquireProcess()
+
+	acquired := make(chan func(), 1)
+	go func() { acquired <- acquireProcess() }()
+	select {
+	case <-acquired:
+		t.Fatal("a third command started while two were running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	first()
+	select {
+	case third := <-acquired:
+		third()
+	case <-time.After(5 * time.Second):
+		t.Fatal("freeing a slot did not let the waiting command start")
+	}
+	second()
+}
+
+func TestAcquireProcessZeroIsUnlimited(t *testing.T) {
+	defer resetProcessSlots(0)()
+	done := make(chan struct{})
+	go func() {
+		var releases []func()
+		for n := 0; n < 100; n++ {
+			releases = append(releases, acquireProcess())
+		}
+		for _, release := range releases {
+			release()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("acquireProcess waited although maxProcesses is 0")
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+// systemRepositories are the registries and repositories kind and Kubernetes
+// pull their own images from, e.g. pause, coredns and kindnetd.
+var systemRepositories = []string{
+	"registry.k8s.io/",
+	"k8s.gcr.io/",
+	"docker.io/kindest/",
+}
+
+// isSystemImage reports whether repoTag comes from one of the systemRepositories.
+func isSystemImage(repoTag string) bool {
+	ref := normalizeReference(repoTag)
+	for _, repository := range systemRepositories {
+		if strings.HasPrefix(ref, repository) {
+			return true
+		}
+	}
+	return false
+}
+
+// systemFilter remembers whether system images are hidden from the kind table.
+type systemFilter struct {
+	mu     sync.Mutex
+	hidden bool
+}
+
+// Toggle switches between hiding and showing system images.
+func (f *systemFilter) Toggle() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.hidden = !f.hidden
+}
+
+// Hidden reports whether system images are currently hidden.
+func (f *systemFilter) Hidden() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.hidden
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// kindInUseErrors are what crictl rmi prints when containers on the node,
+// running or exited, still reference the image.
+var kindInUseErrors = []string{
+	"image is in use",
+	"is being used by",
+	"in use by container",
+}
+
+func isImageInUse(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	for _, message := range kindInUseErrors {
+		if strings.Contains(stderr, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// forceDeleteImage removes the containers on every kind node that has an
+// image that reference it, in any state, and then the image. The kubelet
+// recreates the containers of running pods, which then need to pull the
+// image again.
+func (i *imagePlugin) forceDeleteImage(imageID string) error {
+	found, err := findDeletable(imageID)
+	if err != nil {
+		return fmt.Errorf("forceDeleteImage %s: %w", imageID, err)
+	}
+
+	for _, n := range found {
+		containers, err := crictlContainers(n.Node, "ps", "--all", "--output=json")
+		if err != nil {
+			return fmt.Errorf("forceDeleteImage %s: %s: %w", imageID, n.Node, err)
+		}
+		for _, c := range imageConsumers(containers)[n.Image.ID] {
+			// docker exec {{node}} crictl rm --force {{container}}
+			cmd := crictlCommand(n.Node, "rm", "--force", c.ID)
+			if dryRun {
+				log.Printf("dry run: %s", commandLine(cmd))
+				continue
+			}
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+			if err := runCommand(cmd); err != nil {
+				err = fmt.Errorf("forceDeleteImage %s: removing container %s of %s on %s: %w: %s",
+					imageID, c.Metadata.Name, c.PodName(), n.Node, err, strings.TrimSpace(stderr.String()))
+				i.deleteProgress.Start(fmt.Sprintf("Force deleting %s", imageID))
+				i.deleteProgress.Finish(err, "")
+				return err
+			}
+			log.Printf("removed container %s of %s on %s to delete %s", c.Metadata.Name, c.PodName(), n.Node, imageID)
+		}
+	}
+
+	if err := i.deleteImage(imageID, true); err != nil {
+		return err
+	}
+	i.forcePrompt.Set("")
+	return nil
+}
+
+// addForceDeletePromptSection explains why imageID could not be deleted and
+// offers removing the containers that reference it first.
+func addForceDeletePromptSection(layout *flexlayout.FlexLayout, imageID string) {
+	card := component.NewCard(component.TitleFromString(fmt.Sprintf("%s is still in use", imageID)))
+	card.SetBody(component.NewTextf("Containers on the nodes of %s still reference %s, so crictl refused to delete it. "+
+		"Force delete removes those containers, running or exited, on every node, and then the image. "+
+		"Pods running them are restarted by the kubelet and fail if they cannot pull the image again.", kindCluster, imageID))
+	promptSection := layout.AddSection()
+	promptSection.Add(card, component.WidthFull)
+
+	layout.AddButton(fmt.Sprintf("Force delete %s", imageID), action.Payload{
+		"action":  names.ForceDelete,
+		"imageID": imageID,
+	}, component.WithButtonConfirmation("Force delete?",
+		fmt.Sprintf("This removes every container using %s, which restarts the pods they belong to. Do you want to continue?", imageID)))
+	layout.AddButton("Dismiss force delete", action.Payload{
+		"action":  names.ForceDelete,
+		"imageID": "",
+	})
+}
+
+// deleteUnusedImages deletes, on every node, the images no container
+// references in any state. Pinned and system images are kept, since the
+// sandbox image is used by pods without appearing as a container image.
+// When before is set only images created before it are deleted, which are
+// the stale images.
+func (i *imagePlugin) deleteUnusedImages(ctx context.Context, before time.Time) (err error) {
+	kind := "unused"
+	if !before.IsZero() {
+		kind = "stale"
+	}
+	i.maintProgress.Start(fmt.Sprintf("Deleting %s kind images", kind))
+	var deleted int
+	var reclaimed int64
+	defer func() {
+		success := fmt.Sprintf("Deleted %d %s image(s), reclaiming %s", deleted, kind, formatBytes(reclaimed))
+		if dryRun {
+			success = fmt.Sprintf("Dry run: would have deleted %d %s image(s), reclaiming %s", deleted, kind, formatBytes(reclaimed))
+		}
+		// Reset even when stopped, since some images may be gone.
+		i.specs.Reset()
+		i.details.Reset()
+		i.nodeImages.Reset()
+		i.diskUsage.Reset()
+		i.strays.Reset()
+		i.maintProgress.Finish(err, success)
+	}()
+
+	nodes, err := listKindNodes()
+	if err != nil {
+		return fmt.Errorf("deleteUnusedImages: %w", err)
+	}
+	var failed []string
+	for _, node := range nodes {
+		images, err := listNodeImages(node)
+		if err != nil {
+			return fmt.Errorf("deleteUnusedImages %s: %w", node, err)
+		}
+		if images.Backend == backendCtr {
+			return fmt.Errorf("deleteUnusedImages %s: crictl is needed to tell which images containers use", node)
+		}
+		containers, err := crictlContainers(node, "ps", "--all", "--output=json")
+		if err != nil {
+			return fmt.Errorf("deleteUnusedImages %s: %w", node, err)
+		}
+		consumers := imageConsumers(containers)
+
+		candidates := images.Images
+		if !before.IsZero() {
+			var ids []string
+			for _, image := range images.Images {
+				ids = append(ids, image.ID)
+			}
+			// Images the specs are missing for have no known age and are kept.
+			specs, err := i.specs.Get(ids)
+			if err != nil {
+				log.Printf("unable to inspect images on %s: %s", node, err)
+			}
+			candidates = staleKindImages(images.Images, specs, consumers, before)
+		}
+
+		for _, image := range candidates {
+			if image.Pinned || len(consumers[image.ID]) > 0 || usedByTag(image, consumers) || isSystem(image) {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("deleteUnusedImages: stopped after deleting %d image(s): %w", deleted, err)
+			}
+			// docker exec {{node}} crictl rmi {{id}}
+			cmd := crictlCommand(node, "rmi", image.ID)
+			size, _ := strconv.ParseInt(image.Size, 10, 64)
+			if dryRun {
+				log.Printf("dry run: %s", commandLine(cmd))
+				deleted++
+				reclaimed += size
+				continue
+			}
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+			if err := runContext(ctx, cmd); err != nil {
+				if ctx.Err() != nil {
+					return fmt.Errorf("deleteUnusedImages: stopped after deleting %d image(s): %w", deleted, ctx.Err())
+				}
+				log.Printf("unable to delete %s on %s: %s", image.ID, node, strings.TrimSpace(stderr.String()))
+				failed = append(failed, fmt.Sprintf("%s on %s", shortDigest("@"+image.ID), node))
+				continue
+			}
+			deleted++
+			reclaimed += size
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("deleteUnusedImages: deleted %d image(s), reclaiming %s, but could not delete %s",
+			deleted, formatBytes(reclaimed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// usedByTag reports whether a container refers to the image by one of its
+// tags rather than its ID.
+func usedByTag(image kindImage, consumers map[string][]kindContainer) bool {
+	for _, repoTag := range image.RepoTags {
+		if len(consumers[repoTag]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isSystem(image kindImage) bool {
+	for _, repoTag := range image.RepoTags {
+		if isSystemImage(repoTag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+// queueGet queues ref to be pulled on the host and loaded into kind.
+func (i *imagePlugin) queueGet(ref string) error {
+	if err := i.gets.Enqueue(queuedJob{ImageID: ref}); err != nil {
+		return err
+	}
+	log.Printf("queued %s for pulling and loading into kind", ref)
+	return nil
+}
+
+// getImage pulls an image with the host runtime and loads it into kind, as
+// one operation with a pull and a load phase.
+func (i *imagePlugin) getImage(ctx context.Context, job queuedJob) (err error) {
+	ref := job.ImageID
+
+	i.getProgress.Start(fmt.Sprintf("Getting %s into kind", ref))
+	// docker pull {{ref}}
+	pull := exec.Command(host.Name(), "pull", ref)
+	defer func() {
+		success := fmt.Sprintf("Pulled and loaded %s into %d node(s) in %s (%s)",
+			ref, i.getProgress.Nodes(), i.getProgress.Elapsed(), i.getProgress.PhaseTimings())
+		if dryRun {
+			success = fmt.Sprintf("Dry run: would have run %s and %s", commandLine(pull), host.LoadCommand(ref))
+		}
+		i.getProgress.Finish(err, success)
+	}()
+
+	if dryRun {
+		log.Printf("dry run: %s && %s", commandLine(pull), host.LoadCommand(ref))
+		return nil
+	}
+
+	i.getProgress.Phase("pull")
+	if err := streamCommand(ctx, pull, i.getProgress.Write); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("getImage %s: pull: %w", ref, err)
+		}
+		return i.withBuildxHint(fmt.Errorf("getImage %s: pull: %w: %s", ref, err, strings.Join(i.getProgress.Output(), "\n")), ref)
+	}
+
+	i.getProgress.Phase("load")
+	if err := checkNodeCapacity(ref); err != nil {
+		return fmt.Errorf("getImage %s: load: %w", ref, err)
+	}
+	if err := host.Load(ctx, ref, i.getProgress.Write); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("getImage %s: load: %w", ref, err)
+		}
+		return fmt.Errorf("getImage %s: load: %w: %s", ref, err, strings.Join(i.getProgress.Output(), "\n"))
+	}
+	return nil
+}
+
+// getCard renders the form to pull an image on the host and load it into kind.
+func getCard(err error) *component.Card {
+	card := component.NewCard(component.TitleFromString("Get Into Kind"))
+	card.SetBody(component.NewTextf("Pull an image with %s and load it into kind in one step. "+
+		"The pull uses your %s login credentials.", host.Name(), host.Name()))
+	card.AddAction(component.Action{
+		Name:  "Get",
+		Title: "Get image into kind",
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.Get),
+				component.NewFormFieldText("Image", "image", ""),
+			},
+		},
+		Modal: true,
+	})
+	if err != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, err.Error()))
+	}
+	return card
+}
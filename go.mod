@@ -2,4 +2,8 @@ module github.com/wwitzel3/octant-kind-registry
 
 go 1.13
 
-require github.com/vmware-tanzu/octant v0.13.0
+require (
+	github.com/vmware-tanzu/octant v0.13.0
+	k8s.io/api v0.19.0-alpha.3
+	k8s.io/apimachinery v0.19.0-alpha.3
+)
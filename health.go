@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// healthTTL is how long probe results are reused, set with --cache-ttl. Both
+// views render the status and refresh every few seconds, so this avoids
+// probing for each one.
+var healthTTL = 5 * time.Second
+
+// health is the result of the connectivity probes.
+type health struct {
+	ServerVersion string
+	DaemonErr     error
+	NodeState     string
+	NodeErr       error
+}
+
+// healthProbe runs the connectivity probes and caches the result for healthTTL.
+type healthProbe struct {
+	mu      sync.Mutex
+	checked time.Time
+	last    health
+}
+
+func (p *healthProbe) Get() health {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if time.Since(p.checked) > healthTTL {
+		p.last = probeHealth()
+		p.checked = time.Now()
+	}
+	return p.last
+}
+
+// Reset makes the next Get probe again, e.g. after the cluster was started.
+func (p *healthProbe) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.checked = time.Time{}
+}
+
+func probeHealth() health {
+	var h health
+	h.ServerVersion, h.DaemonErr = probeCommand(host.Name()+" version", host.Name(), "version", "--format", "{{.Server.Version}}")
+	// The node can't be inspected without the daemon, and the error would only repeat it.
+	if h.DaemonErr == nil {
+		h.NodeState, h.NodeErr = probeCommand(nodeRuntime()+" inspect", nodeRuntime(), "inspect", "--format", "{{.State.Status}}", kindNode)
+	}
+	return h
+}
+
+// probeCommand runs a command and returns its trimmed output.
+func probeCommand(command, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return "", commandError(command, err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// addHealthSection summarizes whether the daemon and the kind node are up.
+// When something is down it explains why the tables below are empty.
+func addHealthSection(layout *flexlayout.FlexLayout, h health, versions []toolVersion) {
+	summary := component.NewSummary("Status")
+
+	daemon := component.NewTextf("reachable, server %s", h.ServerVersion)
+	daemon.SetStatus(component.TextStatusOK)
+	if h.DaemonErr != nil {
+		daemon = component.NewTextf("unreachable: %s", h.DaemonErr)
+		daemon.SetStatus(component.TextStatusError)
+	}
+	summary.AddSection(host.Title(), daemon)
+
+	summary.AddSection("Cluster", component.NewText(kindCluster))
+
+	var node *component.Text
+	switch {
+	case h.DaemonErr != nil:
+		node = component.NewTextf("%s: unknown", kindNode)
+	case h.NodeErr != nil:
+		node = component.NewTextf("%s: not found, create the cluster with kind create cluster --name %s", kindNode, kindCluster)
+		node.SetStatus(component.TextStatusError)
+	case contains(startingStates, h.NodeState):
+		node = component.NewTextf("%s: %s, cluster starting…", kindNode, h.NodeState)
+		node.SetStatus(component.TextStatusWarning)
+	case h.NodeState != "running":
+		node = component.NewTextf("%s: %s", kindNode, h.NodeState)
+		node.SetStatus(component.TextStatusError)
+	default:
+		node = component.NewTextf("%s: running", kindNode)
+		node.SetStatus(component.TextStatusOK)
+	}
+	summary.AddSection("Node", node)
+
+	for _, v := range versions {
+		if v.Tool != "kind" {
+			continue
+		}
+		kind := component.NewText(v.Version)
+		if v.Problem != "" {
+			kind = component.NewText(strings.TrimSpace(fmt.Sprintf("%s %s", v.Version, v.Problem)))
+			kind.SetStatus(component.TextStatusWarning)
+		}
+		summary.AddSection("kind", kind)
+	}
+
+	healthSection := layout.AddSection()
+	healthSection.Add(summary, component.WidthFull)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const imageHistoryPath = "image-history"
+
+// imageLayer is one line of docker image history --format={{json .}} output,
+// listed with --human=false so sizes are bytes and times are RFC 3339.
+type imageLayer struct {
+	ID        string `json:"ID"`
+	CreatedAt string `json:"CreatedAt"`
+	CreatedBy string `json:"CreatedBy"`
+	Size      string `json:"Size"`
+	Comment   string `json:"Comment"`
+}
+
+// Bytes returns the size the layer adds to the image.
+func (l imageLayer) Bytes() int64 {
+	size, _ := strconv.ParseInt(l.Size, 10, 64)
+	return size
+}
+
+// imageHistory returns the layers of a host image, newest first.
+func imageHistory(id string) ([]imageLayer, error) {
+	// docker image history --no-trunc --human=false --format={{json .}} {{id}}
+	cmd := exec.Command(host.Name(), "image", "history", "--no-trunc", "--human=false", "--format={{json .}}", id)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, fmt.Errorf("imageHistory %s: %w: %s", id, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var layers []imageLayer
+	scanner := bufio.NewScanner(&stdout)
+	// CreatedBy holds whole RUN commands, which can exceed the default token size.
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var layer imageLayer
+		if err := json.Unmarshal([]byte(line), &layer); err != nil {
+			return layers, fmt.Errorf("imageHistory %s: %w", id, err)
+		}
+		layers = append(layers, layer)
+	}
+	if err := scanner.Err(); err != nil {
+		return layers, fmt.Errorf("imageHistory %s: %w", id, err)
+	}
+	return layers, nil
+}
+
+// imageHistoryLink links text to the layer history page of a host image.
+func imageHistoryLink(text, id string) component.Component {
+	return component.NewLink("", text, pluginPath(imageHistoryPath, id))
+}
+
+func (i *imagePlugin) handleImageHistory(request service.Request) (component.ContentResponse, error) {
+	id, err := routeImageID(request, imageHistoryPath)
+	contentResponse := component.NewContentResponse(component.TitleFromString(fmt.Sprintf("History of %s", id)))
+
+	layout := flexlayout.New()
+	var layers []imageLayer
+	if err == nil {
+		layers, err = imageHistory(id)
+	}
+	if err != nil {
+		addErrorSection(layout, err)
+	}
+
+	var total int64
+	table := component.NewTable("Layers", "No history found", component.NewTableCols("#", "Created", "Size", "Created By", "Comment"))
+	for n, layer := range layers {
+		total += layer.Bytes()
+		created := component.Component(component.NewText(layer.CreatedAt))
+		if t, err := time.Parse(time.RFC3339, layer.CreatedAt); err == nil {
+			created = component.NewTimestamp(t)
+		}
+		table.Add(component.TableRow{
+			"#":          component.NewText(strconv.Itoa(len(layers) - n)),
+			"Created":    created,
+			"Size":       component.NewText(formatBytes(layer.Bytes())),
+			"Created By": component.NewText(layer.CreatedBy),
+			"Comment":    component.NewText(layer.Comment),
+		})
+	}
+
+	summary := component.NewSummary("Image")
+	summary.AddSection("Image", imageIDLink(dockerImagePath, id))
+	summary.AddSection("Layers", component.NewText(strconv.Itoa(len(layers))))
+	summary.AddSection("Total Size", component.NewText(formatBytes(total)))
+	summarySection := layout.AddSection()
+	summarySection.Add(summary, component.WidthFull)
+	tableSection := layout.AddSection()
+	tableSection.Add(table, component.WidthFull)
+
+	contentResponse.Add(layout.ToComponent("History"))
+	return *contentResponse, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// collapseTags shows an image tagged into several repositories as one host
+// table row listing all its tags, set with KIND_REGISTRY_COLLAPSE_TAGS.
+var collapseTags = true
+
+// rowTags returns the tags shown in the row of image, which are all of the
+// image's tags when rows are collapsed and only its own otherwise.
+func rowTags(image dockerImage, tags map[string][]string) []string {
+	if collapseTags {
+		return tags[image.ID]
+	}
+	if ref := image.Reference(); ref != image.ID {
+		return []string{ref}
+	}
+	return nil
+}
+
+// uniqueSize sums the size of the listed images, counting an image listed
+// once per tag only once.
+func uniqueSize(images []dockerImage) int64 {
+	seen := map[string]bool{}
+	var total int64
+	for _, image := range images {
+		if seen[image.ID] {
+			continue
+		}
+		seen[image.ID] = true
+		total += sizeOf(image.Size)
+	}
+	return total
+}
+
+// addImageTotalsSection sums up the host images. Images tagged more than
+// once are counted, and sized, once.
+func addImageTotalsSection(layout *flexlayout.FlexLayout, images []dockerImage, tags map[string][]string) {
+	tagged := 0
+	for _, refs := range tags {
+		tagged += len(refs)
+	}
+	summary := component.NewSummary("Totals")
+	summary.AddSection("Images", component.NewText(fmt.Sprint(len(tags))))
+	summary.AddSection("Tags", component.NewText(fmt.Sprint(tagged)))
+	summary.AddSection("Total Size", component.NewText(formatBytes(uniqueSize(images))))
+	section := layout.AddSection()
+	section.Add(summary, component.WidthFull)
+}
+
+// addLoadTagPromptSection renders the form to pick which tag of an image to
+// load. The tag matters in the cluster, since pods only find the image
+// under the reference they name.
+func addLoadTagPromptSection(layout *flexlayout.FlexLayout, imageID string, tags []string, err error) {
+	var choices []component.InputChoice
+	for n, tag := range tags {
+		choices = append(choices, component.InputChoice{Label: tag, Value: tag, Checked: n == 0})
+	}
+
+	card := component.NewCard(component.TitleFromString(fmt.Sprintf("Load a Tag of %s", imageID)))
+	card.SetBody(component.NewText("Kind keeps the image under the tag it is loaded with. " +
+		"Pick the one your pod specs use, or pods with imagePullPolicy IfNotPresent won't find it."))
+	card.AddAction(component.Action{
+		Name:  "Load",
+		Title: fmt.Sprintf("Load a tag of %s", imageID),
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.Load),
+				component.NewFormFieldHidden("prompt", "tag"),
+				component.NewFormFieldRadio("Tag", "imageID", choices),
+			},
+		},
+		Modal: true,
+	})
+	if err != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, err.Error()))
+	}
+
+	promptSection := layout.AddSection()
+	promptSection.Add(card, component.WidthFull)
+	layout.AddButton("Dismiss tag choice", action.Payload{
+		"action":  names.TagPrompt,
+		"imageID": "",
+	})
+}
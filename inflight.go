@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// inflightOperation is a queued job whose commands are running, as recorded
+// in the in-flight file. The commands run in their own process groups, so a
+// plugin process that is killed outright leaves them running.
+type inflightOperation struct {
+	Queue     string    `json:"queue"`
+	ImageID   string    `json:"imageID"`
+	StartedAt time.Time `json:"startedAt"`
+	// Processes are the commands started for the job, each leading its
+	// process group.
+	Processes []inflightProcess `json:"processes"`
+
+	// Running is whether a command of an operation left by an earlier plugin
+	// process was still alive when last checked.
+	Running bool `json:"-"`
+}
+
+// inflightProcess is a command of an operation. Started is the process start
+// time the system reported right after it started, so a later plugin process
+// can tell it from an unrelated process that reused the PID. It is empty when
+// the start time couldn't be read.
+type inflightProcess struct {
+	PID     int    `json:"pid"`
+	Started string `json:"started,omitempty"`
+}
+
+// verify reports whether p still runs the command recorded, and false for
+// known when that can't be told.
+func (p inflightProcess) verify() (same, known bool) {
+	if !processAlive(p.PID) {
+		return false, true
+	}
+	if p.Started == "" {
+		return false, false
+	}
+	started, err := processStartTime(p.PID)
+	if err != nil {
+		return false, false
+	}
+	return started == p.Started, true
+}
+
+// inflightFile sits next to the state file and is only kept when the state
+// file is.
+func inflightFile() string {
+	path := statePath()
+	if path == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "inflight.json")
+}
+
+// operationKey carries the queue name of a job in its context, so the
+// commands the job starts are recorded against it.
+type operationKey struct{}
+
+func withOperation(ctx context.Context, queue string) context.Context {
+	return context.WithValue(ctx, operationKey{}, queue)
+}
+
+// inflightTracker records the running operations of this plugin process in
+// the in-flight file, and keeps the ones an earlier process left behind.
+type inflightTracker struct {
+	mu      sync.Mutex
+	current map[string]inflightOperation
+	orphans []inflightOperation
+}
+
+// inflight is package level since commands are started by free functions
+// that only have the job's context.
+var inflight = &inflightTracker{current: map[string]inflightOperation{}}
+
+// Start records that a queue started running job.
+func (t *inflightTracker) Start(queue string, job queuedJob) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.current[queue] = inflightOperation{Queue: queue, ImageID: job.ImageID, StartedAt: job.StartedAt}
+	t.write()
+}
+
+// AddProcess records a command started by the job running in ctx.
+func (t *inflightTracker) AddProcess(ctx context.Context, pid int) {
+	queue, ok := ctx.Value(operationKey{}).(string)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	op, ok := t.current[queue]
+	if !ok {
+		return
+	}
+	process := inflightProcess{PID: pid}
+	if started, err := processStartTime(pid); err == nil {
+		process.Started = started
+	} else {
+		log.Printf("unable to read the start time of pid %d, it can't be stopped after a restart: %s", pid, err)
+	}
+	op.Processes = append(op.Processes, process)
+	t.current[queue] = op
+	t.write()
+}
+
+// Finish forgets the job a queue was running.
+func (t *inflightTracker) Finish(queue string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.current, queue)
+	t.write()
+}
+
+// write saves the current operations. The caller holds t.mu.
+func (t *inflightTracker) write() {
+	path := inflightFile()
+	if path == "" {
+		return
+	}
+	if len(t.current) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("unable to remove %s: %s", path, err)
+		}
+		return
+	}
+
+	ops := make([]inflightOperation, 0, len(t.current))
+	for _, op := range t.current {
+		ops = append(ops, op)
+	}
+	data, err := json.Marshal(ops)
+	if err != nil {
+		log.Printf("unable to encode in-flight operations: %s", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("unable to record in-flight operations: %s", err)
+		return
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		log.Printf("unable to record in-flight operations: %s", err)
+	}
+}
+
+// Recover reads the operations an earlier plugin process was running when it
+// stopped, and watches the ones whose commands are still alive until they
+// exit. Their outcome can't be known, since this process can't wait on them.
+func (t *inflightTracker) Recover() {
+	path := inflightFile()
+	if path == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("unable to read in-flight operations: %s", err)
+		}
+		return
+	}
+	var ops []inflightOperation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		log.Printf("ignoring unreadable in-flight operations in %s: %s", path, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, op := range ops {
+		op.Running = anyProcessAlive(op.Processes)
+		log.Printf("%s %s was running when the plugin stopped, still running: %t", op.Queue, op.ImageID, op.Running)
+		t.orphans = append(t.orphans, op)
+	}
+	t.write()
+	if len(t.orphans) > 0 {
+		go t.watchOrphans()
+	}
+}
+
+// watchOrphans polls the orphaned commands until all of them have exited.
+func (t *inflightTracker) watchOrphans() {
+	for range time.Tick(2 * time.Second) {
+		t.mu.Lock()
+		running := false
+		for n, op := range t.orphans {
+			if op.Running && !anyProcessAlive(op.Processes) {
+				log.Printf("%s %s left by the previous plugin process exited", op.Queue, op.ImageID)
+				t.orphans[n].Running = false
+			}
+			running = running || t.orphans[n].Running
+		}
+		t.mu.Unlock()
+		if !running {
+			return
+		}
+	}
+}
+
+// Orphans returns the operations an earlier plugin process left behind.
+func (t *inflightTracker) Orphans() []inflightOperation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]inflightOperation(nil), t.orphans...)
+}
+
+// Stop kills the commands of an orphaned operation. The PIDs come from a
+// file an earlier process wrote and may have been reused since, so a process
+// group is only killed when its leader's start time is the one recorded.
+// Stop refuses when that can't be checked rather than risk killing another.
+func (t *inflightTracker) Stop(imageID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for n, op := range t.orphans {
+		if op.ImageID != imageID || !op.Running {
+			continue
+		}
+		var verified []int
+		for _, p := range op.Processes {
+			same, known := p.verify()
+			if !known {
+				return fmt.Errorf("stop %s %s: unable to verify that pid %d is still its command, not killing it; "+
+					"check it and kill process group %d yourself", op.Queue, op.ImageID, p.PID, p.PID)
+			}
+			if same {
+				verified = append(verified, p.PID)
+			}
+		}
+		if len(verified) == 0 {
+			// Every PID was reused by another process, so the commands exited.
+			t.orphans[n].Running = false
+			return nil
+		}
+		for _, pid := range verified {
+			if err := killProcessGroupID(pid); err != nil {
+				return fmt.Errorf("stop %s %s: pid %d: %w", op.Queue, op.ImageID, pid, err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is not running from an earlier plugin process", imageID)
+}
+
+// anyProcessAlive reports whether a command of an operation may still run.
+// A process whose start time can't be checked counts as running, so an
+// operation is never reported finished while it might not be.
+func anyProcessAlive(processes []inflightProcess) bool {
+	for _, p := range processes {
+		if same, known := p.verify(); same || !known && processAlive(p.PID) {
+			return true
+		}
+	}
+	return false
+}
+
+// addOrphanSection reports the operations an earlier plugin process was
+// running, so a restart never hides a load that is still going.
+func addOrphanSection(layout *flexlayout.FlexLayout, orphans []inflightOperation) {
+	for _, op := range orphans {
+		section := layout.AddSection()
+		if op.Running {
+			text := component.NewTextf("%s of %s, started %s ago by an earlier plugin process, is still running. "+
+				"Its result will not be known.", op.Queue, op.ImageID, time.Since(op.StartedAt).Round(time.Second))
+			text.SetStatus(component.TextStatusWarning)
+			section.Add(text, component.WidthFull)
+			layout.AddButton(fmt.Sprintf("Stop %s of %s", op.Queue, op.ImageID), action.Payload{
+				"action":    names.Cancel,
+				"imageID":   op.ImageID,
+				"operation": "orphan",
+			}, component.WithButtonConfirmation("Stop orphaned operation?",
+				fmt.Sprintf("This kills the commands of the %s of %s. Do you want to continue?", op.Queue, op.ImageID)))
+			continue
+		}
+		text := component.NewTextf("%s of %s, started at %s, has unknown outcome: the plugin restarted while it ran.",
+			op.Queue, op.ImageID, op.StartedAt.Format(time.Kitchen))
+		text.SetStatus(component.TextStatusWarning)
+		section.Add(text, component.WidthFull)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// imageSpec is the subset of the OCI image config reported by crictl inspecti.
+type imageSpec struct {
+	Created      time.Time `json:"created"`
+	Architecture string    `json:"architecture"`
+	OS           string    `json:"os"`
+	// Config only keeps the labels, for the Revision column.
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// Platform returns the os/architecture the image was built for, e.g. "linux/arm64".
+func (s imageSpec) Platform() string {
+	return platform(s.OS, s.Architecture)
+}
+
+func platform(os, arch string) string {
+	if os == "" || arch == "" {
+		return arch
+	}
+	return os + "/" + arch
+}
+
+type crictlInspect struct {
+	Status kindImage `json:"status"`
+	Info   struct {
+		ImageSpec imageSpec `json:"imageSpec"`
+	} `json:"info"`
+}
+
+// imageSpecCache caches crictl inspecti results by image ID. Image IDs are
+// content addressed so entries never go stale.
+type imageSpecCache struct {
+	mu    sync.Mutex
+	specs map[string]imageSpec
+}
+
+func newImageSpecCache() *imageSpecCache {
+	return &imageSpecCache{specs: map[string]imageSpec{}}
+}
+
+// Reset drops every cached spec. Specs never go stale on their own, but a
+// node restart can leave containerd reporting an image that no longer matches
+// what was cached for its ID.
+func (c *imageSpecCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.specs = map[string]imageSpec{}
+}
+
+// Get returns the image specs for the given IDs, inspecting any that are not
+// cached yet in a single crictl call.
+func (c *imageSpecCache) Get(ids []string) (map[string]imageSpec, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var missing []string
+	for _, id := range ids {
+		if _, ok := c.specs[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		inspected, err := inspectKindImages(missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, inspect := range inspected {
+			c.specs[inspect.Status.ID] = inspect.Info.ImageSpec
+		}
+	}
+
+	specs := make(map[string]imageSpec, len(ids))
+	for _, id := range ids {
+		specs[id] = c.specs[id]
+	}
+	return specs, nil
+}
+
+func inspectKindImages(ids []string) ([]crictlInspect, error) {
+	args := append([]string{"inspecti", "--output=json"}, ids...)
+	cmd := crictlCommand(kindNode, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(cmd); err != nil {
+		return nil, fmt.Errorf("inspectKindImages: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// crictl prints one JSON document per image.
+	var inspected []crictlInspect
+	decoder := json.NewDecoder(&stdout)
+	for {
+		var inspect crictlInspect
+		if err := decoder.Decode(&inspect); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("inspectKindImages: %w", err)
+		}
+		inspected = append(inspected, inspect)
+	}
+	return inspected, nil
+}
+
+// dockerInspect is the subset of docker image inspect output used by the plugin.
+type dockerInspect struct {
+	ID           string   `json:"Id"`
+	Architecture string   `json:"Architecture"`
+	OS           string   `json:"Os"`
+	RepoDigests  []string `json:"RepoDigests"`
+	Config       struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// Platform returns the os/architecture the image was built for, e.g. "linux/arm64".
+func (d dockerInspect) Platform() string {
+	return platform(d.OS, d.Architecture)
+}
+
+// ShortID returns the truncated ID docker image ls reports.
+func (d dockerInspect) ShortID() string {
+	return shortID(d.ID)
+}
+
+// dockerInspectCache caches docker image inspect results by short image ID.
+type dockerInspectCache struct {
+	mu       sync.Mutex
+	inspects map[string]dockerInspect
+}
+
+func newDockerInspectCache() *dockerInspectCache {
+	return &dockerInspectCache{inspects: map[string]dockerInspect{}}
+}
+
+// Get returns the inspect results for the given IDs, inspecting any that are
+// not cached yet in a single docker call.
+func (c *dockerInspectCache) Get(ids []string) (map[string]dockerInspect, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var missing []string
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if _, ok := c.inspects[id]; !ok && !seen[id] {
+			missing = append(missing, id)
+			seen[id] = true
+		}
+	}
+
+	if len(missing) > 0 {
+		inspected, err := inspectDockerImages(missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, inspect := range inspected {
+			c.inspects[inspect.ShortID()] = inspect
+		}
+	}
+
+	inspects := make(map[string]dockerInspect, len(ids))
+	for _, id := range ids {
+		inspects[id] = c.inspects[id]
+	}
+	return inspects, nil
+}
+
+// Reset drops every cached result, e.g. after a push gives images new repo digests.
+func (c *dockerInspectCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inspects = map[string]dockerInspect{}
+}
+
+// inspectBatchSize is how many images one docker image inspect is given, which
+// keeps hundreds of images to a few commands without overlong command lines.
+const inspectBatchSize = 100
+
+func inspectDockerImages(ids []string) ([]dockerInspect, error) {
+	var inspected []dockerInspect
+	for start := 0; start < len(ids); start += inspectBatchSize {
+		end := start + inspectBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch, err := inspectDockerBatch(ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		inspected = append(inspected, batch...)
+	}
+	return inspected, nil
+}
+
+func inspectDockerBatch(ids []string) ([]dockerInspect, error) {
+	// docker image inspect {{ids}}
+	args := append([]string{"image", "inspect"}, ids...)
+	cmd := exec.Command(host.Name(), args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(cmd); err != nil {
+		return nil, fmt.Errorf("inspectDockerImages: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var inspected []dockerInspect
+	if err := json.Unmarshal(stdout.Bytes(), &inspected); err != nil {
+		return nil, fmt.Errorf("inspectDockerImages: %w", err)
+	}
+	return inspected, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const inventoryPath = "inventory"
+
+// inventory is the combined image listing rendered as JSON for scripts.
+type inventory struct {
+	Generated time.Time            `json:"generated"`
+	Runtime   string               `json:"runtime"`
+	Cluster   string               `json:"cluster"`
+	Host      []hostInventoryImage `json:"host"`
+	Kind      []kindInventoryImage `json:"kind"`
+	Errors    []string             `json:"errors,omitempty"`
+}
+
+type hostInventoryImage struct {
+	ID             string   `json:"id"`
+	Tags           []string `json:"tags"`
+	Created        string   `json:"created"`
+	Size           string   `json:"size"`
+	LoadedIntoKind bool     `json:"loadedIntoKind"`
+}
+
+type kindInventoryImage struct {
+	ID          string   `json:"id"`
+	RepoTags    []string `json:"repoTags"`
+	RepoDigests []string `json:"repoDigests"`
+	Size        string   `json:"size"`
+}
+
+// buildInventory lists the host and kind images, recording listing errors
+// instead of failing so partial results are still usable.
+func buildInventory() inventory {
+	inv := inventory{
+		Generated: time.Now().UTC(),
+		Runtime:   host.Name(),
+		Cluster:   kindCluster,
+		Host:      []hostInventoryImage{},
+		Kind:      []kindInventoryImage{},
+	}
+
+	kindImages, err := listKindImages()
+	if err != nil {
+		inv.Errors = append(inv.Errors, err.Error())
+	}
+	inKind := map[string]bool{}
+	for _, image := range kindImages.Images {
+		inv.Kind = append(inv.Kind, kindInventoryImage{
+			ID:          image.ID,
+			RepoTags:    image.RepoTags,
+			RepoDigests: image.RepoDigests,
+			Size:        image.Size,
+		})
+		for _, repoTag := range image.RepoTags {
+			inKind[normalizeReference(repoTag)] = true
+		}
+	}
+
+	dockerImages, err := listDockerImages()
+	if err != nil {
+		inv.Errors = append(inv.Errors, err.Error())
+	}
+	grouped, tags := groupByID(dockerImages)
+	for _, image := range grouped {
+		entry := hostInventoryImage{
+			ID:      image.ID,
+			Tags:    tags[image.ID],
+			Created: image.CreatedAt,
+			Size:    image.Size,
+		}
+		for _, tag := range entry.Tags {
+			if inKind[normalizeReference(tag)] {
+				entry.LoadedIntoKind = true
+			}
+		}
+		// Untagged images can only be matched by ID.
+		if _, ok := kindImages.Find(image.ID); ok && len(entry.Tags) == 0 {
+			entry.LoadedIntoKind = true
+		}
+		inv.Host = append(inv.Host, entry)
+	}
+	return inv
+}
+
+func (i *imagePlugin) handleInventory(request service.Request) (component.ContentResponse, error) {
+	contentResponse := component.NewContentResponse(component.TitleFromString("Image Inventory"))
+
+	layout := flexlayout.New()
+	data, err := json.MarshalIndent(buildInventory(), "", "  ")
+	if err != nil {
+		addErrorSection(layout, err)
+	} else {
+		inventorySection := layout.AddSection()
+		inventorySection.Add(component.NewCodeBlock(string(data)), component.WidthFull)
+	}
+
+	view := layout.ToComponent("Inventory")
+	view.SetAccessor(inventoryPath)
+	contentResponse.Add(view)
+	return *contentResponse, nil
+}
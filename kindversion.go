@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+)
+
+// kindFeature is a kind subcommand the plugin runs and the first kind
+// release that has it.
+type kindFeature struct {
+	Command string
+	Since   [3]int
+}
+
+var (
+	// kind load was added in v0.2.0; earlier releases have no way to load
+	// images into nodes.
+	kindLoadDockerImage  = kindFeature{Command: "kind load docker-image", Since: [3]int{0, 2, 0}}
+	kindLoadImageArchive = kindFeature{Command: "kind load image-archive", Since: [3]int{0, 2, 0}}
+)
+
+// installedKind is the kind version found on the PATH, detected once at startup.
+var installedKind struct {
+	once    sync.Once
+	version string
+	err     error
+}
+
+// detectKindVersion runs kind version the first time it is called and
+// returns the cached result afterwards.
+func detectKindVersion() (string, error) {
+	installedKind.once.Do(func() {
+		installedKind.version, installedKind.err = kindVersion()
+		if installedKind.err != nil {
+			log.Printf("unable to determine kind version: %s", installedKind.err)
+		}
+	})
+	return installedKind.version, installedKind.err
+}
+
+// requireKind returns an error naming the kind release a feature needs when
+// the installed kind is older. When the version cannot be determined the
+// command is attempted anyway and its own error is reported.
+func requireKind(feature kindFeature) error {
+	version, err := detectKindVersion()
+	if err != nil {
+		return nil
+	}
+	if older(version, feature.Since) {
+		return fmt.Errorf("%s requires kind >= v%d.%d.%d, but %s is installed",
+			feature.Command, feature.Since[0], feature.Since[1], feature.Since[2], version)
+	}
+	return nil
+}
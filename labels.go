@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+// labelColumns are the image label keys shown as columns of the host image
+// table, set with --label-columns.
+var labelColumns []string
+
+// labelFilter restricts the host image table to images with labels, given
+// as comma separated key or key=value terms, each optionally written like
+// docker's filter as label=key=value. It is held by the plugin so it
+// survives refreshes.
+type labelFilter struct {
+	mu       sync.Mutex
+	selector string
+}
+
+// Set validates and stores a selector; an empty selector clears the filter.
+func (f *labelFilter) Set(selector string) error {
+	selector = strings.TrimSpace(selector)
+	for _, term := range labelTerms(selector) {
+		if strings.HasPrefix(term, "=") {
+			return fmt.Errorf("label filter %q has no key", term)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.selector = selector
+	return nil
+}
+
+func (f *labelFilter) Get() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.selector
+}
+
+// labelTerms splits a selector into its key or key=value terms, dropping the
+// optional label= prefix of each.
+func labelTerms(selector string) []string {
+	var terms []string
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		// "label" on its own is a key, not the prefix.
+		if strings.HasPrefix(term, "label=") && strings.Contains(term[len("label="):], "=") {
+			term = term[len("label="):]
+		}
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// matchLabels reports whether labels satisfy every term of a selector.
+func matchLabels(selector string, labels map[string]string) bool {
+	for _, term := range labelTerms(selector) {
+		key, value := term, ""
+		hasValue := false
+		if i := strings.Index(term, "="); i >= 0 {
+			key, value, hasValue = term[:i], term[i+1:], true
+		}
+		actual, ok := labels[key]
+		if !ok || (hasValue && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// labelFilterCard renders the form that sets the label filter.
+func labelFilterCard(selector string) *component.Card {
+	card := component.NewCard(component.TitleFromString("Filter by Label"))
+	body := "Show only images with labels, given as key or key=value terms separated by commas, " +
+		"e.g. label=team=payments,org.opencontainers.image.revision."
+	if selector != "" {
+		body = fmt.Sprintf("Showing only images labelled %s. Submit an empty filter to show every image.", selector)
+	}
+	card.SetBody(component.NewText(body))
+	card.AddAction(component.Action{
+		Name:  "Filter",
+		Title: "Filter images by label",
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.LabelFilter),
+				component.NewFormFieldText("Label", "label", selector),
+			},
+		},
+		Modal: true,
+	})
+	return card
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The docker releases whose image listing the dockerImage fields were
+// checked against: 20.10, 24 and 27. Releases outside the range are parsed
+// the same way, but a changed format is the first suspect for empty columns.
+var (
+	oldestListingVersion = [3]int{20, 10, 0}
+	newestListingMajor   = 27
+)
+
+// dockerListingFields are the keys of docker image ls --format={{json .}}
+// in the checked releases, and of nerdctl's imitation of it. docker 25
+// dropped VirtualSize; nerdctl adds BlobSize and Platform.
+var dockerListingFields = []string{
+	"Containers", "CreatedAt", "CreatedSince", "Digest", "ID", "Repository",
+	"SharedSize", "Size", "Tag", "UniqueSize", "VirtualSize", "BlobSize", "Platform",
+}
+
+// dockerListingAliases are other names for listing fields, used by docker
+// compatible CLIs and custom --image-list-format templates.
+var dockerListingAliases = map[string]string{
+	"Id":      "ID",
+	"Created": "CreatedAt",
+}
+
+// notAvailable is what docker prints for counts it didn't compute, such as
+// Containers and SharedSize without docker system df.
+const notAvailable = "N/A"
+
+// listingWarnings logs each listing format surprise once, so a new docker
+// release doesn't repeat the same warning on every render.
+type listingWarnings struct {
+	mu      sync.Mutex
+	seen    map[string]bool
+	version sync.Once
+}
+
+var listingWarned = &listingWarnings{}
+
+// Warn logs message unless it was logged before.
+func (w *listingWarnings) Warn(message string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.seen == nil {
+		w.seen = map[string]bool{}
+	}
+	if w.seen[message] {
+		return
+	}
+	w.seen[message] = true
+	log.Printf("warning: %s", message)
+}
+
+// CheckVersion warns once when the docker client is a release whose image
+// listing format wasn't checked. Only docker is gated; podman is listed with
+// its own parser and nerdctl keeps docker's field names.
+func (w *listingWarnings) CheckVersion(name string) {
+	if name != "docker" {
+		return
+	}
+	w.version.Do(func() {
+		client, _, err := runtimeVersion()
+		if client == "" {
+			log.Printf("unable to check the docker version for the image listing format: %s", err)
+			return
+		}
+		version, ok := parseVersion(client)
+		if !ok {
+			w.Warn("unable to parse docker client version " + client + ", image listing fields are parsed on a best effort basis")
+			return
+		}
+		if older(client, oldestListingVersion) || version[0] > newestListingMajor {
+			w.Warn("docker client " + client + " is outside the releases the image listing was checked with (20.10 to 27), " +
+				"fields it renamed are parsed on a best effort basis and may show up as empty columns")
+		}
+	})
+}
+
+// unixTime parses a timestamp given as seconds since the epoch, as docker
+// compatible CLIs print Created.
+func unixTime(v string) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
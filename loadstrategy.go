@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"sync/atomic"
+	"time"
+)
+
+// The ways a host image gets into the kind nodes, set with --load-strategy.
+const (
+	// loadKind runs kind load docker-image, which saves the image to a
+	// temporary archive before copying it into each node.
+	loadKind = "kind"
+	// loadStream pipes docker save straight into ctr images import on each
+	// node, without the temporary archive.
+	loadStream = "stream"
+	// loadAuto streams images larger than largeImageThreshold and uses kind
+	// load for the rest.
+	loadAuto = "auto"
+)
+
+// loadStrategy is how images are loaded; see loadKind, loadStream and loadAuto.
+var loadStrategy = loadKind
+
+// streamProgressInterval is how often a streamed import reports the bytes
+// piped so far.
+const streamProgressInterval = 2 * time.Second
+
+// useStreamImport reports whether imageID is loaded by streaming it into the
+// nodes. nerdctl without docker always streams, see nerdctlRuntime.Load.
+func useStreamImport(imageID string) bool {
+	if host.Name() == "nerdctl" {
+		return false
+	}
+	switch loadStrategy {
+	case loadStream:
+		return true
+	case loadAuto:
+		size, err := dockerImageBytes(imageID)
+		return err == nil && size > largeImageThreshold
+	}
+	return false
+}
+
+// streamImportCommand describes a streamed import, for logs and dry runs.
+func streamImportCommand(imageID string) string {
+	return fmt.Sprintf("%s save %s | %s exec -i <node> ctr -n %s images import --digests -",
+		host.Name(), shellQuote(imageID), nodeRuntime(), containerdNamespace)
+}
+
+// streamImport loads imageID into every kind node by piping the host's image
+// save into ctr images import on the node, reporting the bytes piped.
+func streamImport(ctx context.Context, imageID string, onLine func(string)) error {
+	nodes, err := listKindNodes()
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		// The same wording as kind so loads are counted per node.
+		onLine(fmt.Sprintf("Image: %q not yet present on node %q, loading...", imageID, node))
+		var piped int64
+		done := make(chan struct{})
+		go func(node string) {
+			ticker := time.NewTicker(streamProgressInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					onLine(fmt.Sprintf("%s: %s piped", node, formatBytes(atomic.LoadInt64(&piped))))
+				case <-done:
+					return
+				}
+			}
+		}(node)
+		started := time.Now()
+		err := pipeImport(ctx, exec.Command(host.Name(), "save", imageID), node, &piped)
+		close(done)
+		if err != nil {
+			return fmt.Errorf("%s: %w", node, err)
+		}
+		onLine(fmt.Sprintf("%s: imported %s in %s", node, formatBytes(atomic.LoadInt64(&piped)), time.Since(started).Round(time.Second)))
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it into n.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+// streamLoad runs a streamed import and falls back to kind load when it
+// fails for another reason than ctx ending. Each is a progress phase, so the
+// activity log records which strategy loaded the image.
+func (i *imagePlugin) streamLoad(ctx context.Context, imageID string) (bool, error) {
+	i.progress.Phase("stream import")
+	err := streamImport(ctx, imageID, i.progress.Write)
+	if err == nil || ctx.Err() != nil {
+		return true, err
+	}
+	log.Printf("streamed import of %s failed, falling back to kind load: %s", imageID, err)
+	i.progress.Write(fmt.Sprintf("Streamed import failed, falling back to kind load: %s", err))
+	return false, nil
+}
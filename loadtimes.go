@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// loadSampleCount is how many completed loads are kept, and saved in the
+// state file, for the load time statistics.
+const loadSampleCount = 20
+
+// loadSample is the timing of one completed load.
+type loadSample struct {
+	Image    string        `json:"image"`
+	ImageID  string        `json:"imageID,omitempty"`
+	Size     int64         `json:"size"`
+	Duration time.Duration `json:"duration"`
+	At       time.Time     `json:"at"`
+}
+
+// Rate is the throughput of the load in MB/s, the unit docker reports
+// transfers in.
+func (s loadSample) Rate() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Size) / 1000 / 1000 / s.Duration.Seconds()
+}
+
+// String describes the load, e.g. "95s (1.8 GiB at 19.4 MB/s)".
+func (s loadSample) String() string {
+	if s.Size == 0 {
+		return s.Duration.Round(time.Second).String()
+	}
+	return fmt.Sprintf("%s (%s at %.1f MB/s)", s.Duration.Round(time.Second), formatBytes(s.Size), s.Rate())
+}
+
+// loadTimes keeps the most recent load timings, so the time a load will
+// take can be judged before starting it.
+type loadTimes struct {
+	mu      sync.Mutex
+	samples []loadSample
+}
+
+// Add records a completed load.
+func (l *loadTimes) Add(sample loadSample) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.samples = append(l.samples, sample)
+	if len(l.samples) > loadSampleCount {
+		l.samples = l.samples[len(l.samples)-loadSampleCount:]
+	}
+}
+
+// Set replaces the samples, e.g. with the ones saved by the last session.
+func (l *loadTimes) Set(samples []loadSample) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.samples = append([]loadSample(nil), samples...)
+	if len(l.samples) > loadSampleCount {
+		l.samples = l.samples[len(l.samples)-loadSampleCount:]
+	}
+}
+
+// List returns a copy of the samples, oldest first.
+func (l *loadTimes) List() []loadSample {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]loadSample(nil), l.samples...)
+}
+
+// Summary returns the average and last load durations, and false when no
+// load has been timed yet.
+func (l *loadTimes) Summary() (time.Duration, loadSample, bool) {
+	samples := l.List()
+	if len(samples) == 0 {
+		return 0, loadSample{}, false
+	}
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample.Duration
+	}
+	return (total / time.Duration(len(samples))).Round(time.Second), samples[len(samples)-1], true
+}
+
+// Latest returns the most recent load of the image with the given ID or
+// loaded under one of refs.
+func (l *loadTimes) Latest(id string, refs ...string) (loadSample, bool) {
+	samples := l.List()
+	for n := len(samples) - 1; n >= 0; n-- {
+		sample := samples[n]
+		if (id != "" && sameImageID(sample.ImageID, id)) || contains(refs, sample.Image) {
+			return sample, true
+		}
+	}
+	return loadSample{}, false
+}
+
+// addLoadTimesSection shows how long loads took, on top of the kind table.
+func addLoadTimesSection(layout *flexlayout.FlexLayout, times *loadTimes) {
+	average, last, ok := times.Summary()
+	if !ok {
+		return
+	}
+	section := layout.AddSection()
+	section.Add(component.NewTextf("Average load: %s, last: %s of %s", average, last, last.Image), component.WidthFull)
+}
+
+// loadTimeView tells when an image was last loaded into kind and how long
+// it took, for its detail page.
+func loadTimeView(sample loadSample) *component.FlexLayout {
+	layout := flexlayout.New()
+	section := layout.AddSection()
+	section.Add(component.NewTextf("Last loaded into kind as %s %s ago in %s.",
+		sample.Image, time.Since(sample.At).Round(time.Second), sample), component.WidthFull)
+	return layout.ToComponent("Load Time")
+}
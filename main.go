@@ -1,50 +1,254 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"os"
 	"os/exec"
 	"strings"
-	"sync/atomic"
+	"time"
 
 	"github.com/vmware-tanzu/octant/pkg/plugin"
-	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/vmware-tanzu/octant/pkg/action"
-	"github.com/vmware-tanzu/octant/pkg/navigation"
 
 	"github.com/vmware-tanzu/octant/pkg/plugin/service"
 	"github.com/vmware-tanzu/octant/pkg/view/component"
 )
 
 var (
-	pluginName   = "waynewitzel.com/kind-images"
-	loadAction   = "waynewitzel.com/kind-load-image"
-	deleteAction = "waynewitzel.com/kind-delete-image"
+	kindCluster = "kind"
+	kindNode    = kindCluster + "-control-plane"
+
+	// largeImageThreshold is the image size above which loading asks for an
+	// extra confirmation, set with KIND_REGISTRY_LARGE_IMAGE_SIZE.
+	largeImageThreshold int64 = 1 << 30
+
+	// containerdNamespace is the containerd namespace holding the images the
+	// kubelet uses. kind always imports into k8s.io, but it can be overridden
+	// for custom node images with --namespace or KIND_REGISTRY_NAMESPACE.
+	containerdNamespace = "k8s.io"
+
+	// criEndpoint is the CRI socket crictl talks to inside the node. Passing it
+	// explicitly keeps crictl from probing deprecated default endpoints and
+	// warning about it. Override it with --cri-endpoint or
+	// KIND_REGISTRY_CRI_ENDPOINT.
+	criEndpoint = "unix:///run/containerd/containerd.sock"
+
+	// imageListFormat is the --format template docker and nerdctl image ls
+	// use. It must print one JSON object per line with the dockerImage
+	// fields, so a release that renames fields can be handled with
+	// --image-list-format instead of a rebuild.
+	imageListFormat = "{{json .}}"
+
+	// crictlOutput is the --output format of crictl images. It must produce
+	// the same JSON as crictl's json format, set with --crictl-output.
+	crictlOutput = "json"
+
+	// dryRun makes loads and deletes log the command they would run instead
+	// of running it, set with KIND_REGISTRY_DRY_RUN.
+	dryRun bool
 )
 
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// nodeCommand runs a command inside a kind node container, see nodeExec.
+func nodeCommand(node string, args ...string) *exec.Cmd {
+	return nodeExec(node, false, args...)
+}
+
+// nodeInputCommand is nodeCommand with stdin attached to the command.
+func nodeInputCommand(node string, args ...string) *exec.Cmd {
+	return nodeExec(node, true, args...)
+}
+
+// crictlCommand builds a crictl command that runs inside a kind node against criEndpoint.
+func crictlCommand(node string, args ...string) *exec.Cmd {
+	crictlArgs := []string{"crictl", "--runtime-endpoint", criEndpoint, "--image-endpoint", criEndpoint}
+	return nodeCommand(node, append(crictlArgs, args...)...)
+}
+
+// requiredTools are the executables the plugin shells out to.
+var requiredTools = []string{host.Name(), "kind"}
+
 type imagePlugin struct {
-	loading int32
+	queue          *jobQueue
+	progress       *operationProgress
+	deleteProgress *operationProgress
+	forcePrompt    *imagePrompt
+	// removeProgress reports deletes from the host runtime.
+	removeProgress *operationProgress
+	pushes         *jobQueue
+	pushProgress   *operationProgress
+	registries     *registryDetector
+	pushPrompt     *imagePrompt
+	loadPrompt     *imagePrompt
+	tagPrompt      *imagePrompt
+	saves          *jobQueue
+	saveProgress   *operationProgress
+	savePrompt     *imagePrompt
+	copies         *jobQueue
+	copyProgress   *operationProgress
+	copyPrompt     *imagePrompt
+	history        *pushHistory
+	archiveError   *formError
+	pulls          *jobQueue
+	pullProgress   *operationProgress
+	pullError      *formError
+	gets           *jobQueue
+	getProgress    *operationProgress
+	getError       *formError
+	scanQueue      *jobQueue
+	scans          *scanCache
+	// clusterOps runs kind cluster creation and restarts one at a time.
+	clusterOps    *jobQueue
+	setupProgress *operationProgress
+	clusterError  *formError
+	// cleanups runs local registry garbage collection.
+	cleanups      *jobQueue
+	cleanProgress *operationProgress
+	pages         *tablePages
+	sorts         *tableSorts
+	systemImages  *systemFilter
+	commands      *commandToggle
+	autoSync      *autoSyncer
+	stateSaver    *stateSaver
+	labels        *labelFilter
+	projects      *projectFilter
+	specs         *imageSpecCache
+	details       *imageDetailCache
+	inspects      *dockerInspectCache
+	selfChecks    *selfCheckCache
+	overviews     *overviewFlight
+	environment   *environment
+	health        *healthProbe
+	diskUsage     *diskUsageCache
+	strays        *strayImageCache
+	feedback      *actionFeedback
+	activity      *activityLog
+	stats         *sessionStats
+	loadTimes     *loadTimes
+	clusters      *clusterWatcher
+	nodeImages    *nodeImageCache
+	refresher     *refresher
+	buildx        *buildxCache
+	buildError    *formError
+	maintenance   *jobQueue
+	maintProgress *operationProgress
+	hostUsage     *hostUsageCache
+	pressure      *pressureCache
+	policies      *pullPolicyCache
+	selection     *kindSelection
+	bulkDeletes   *bulkDeleteReport
+	staleOnly     *staleFilter
+	api           *apiServer
+	// digests keeps the manifest digests of the local registry's tags.
+	digests *registryDigestCache
+}
+
+func (i *imagePlugin) hasTool(tool string) bool {
+	for _, missing := range i.selfChecks.Missing() {
+		if missing == tool {
+			return false
+		}
+	}
+	return true
 }
 
+// dockerImage is one line of docker image ls --format={{json .}} output.
+// Which fields are present, and whether some are strings or numbers, has
+// changed between docker releases, so it is decoded with UnmarshalJSON.
 type dockerImage struct {
-	Containers   string
-	CreatedAt    string
-	CreatedSince string
-	Digest       string
-	ID           string
-	Repository   string
-	SharedSize   string
-	Size         string
-	Tag          string
-	UniqueSize   string
-	VirtualSize  string
+	Containers   string `json:"Containers"`
+	CreatedAt    string `json:"CreatedAt"`
+	CreatedSince string `json:"CreatedSince"`
+	Digest       string `json:"Digest"`
+	ID           string `json:"ID"`
+	Repository   string `json:"Repository"`
+	SharedSize   string `json:"SharedSize"`
+	Size         string `json:"Size"`
+	Tag          string `json:"Tag"`
+	UniqueSize   string `json:"UniqueSize"`
+	// VirtualSize was dropped in docker 25, where Size is the same value.
+	VirtualSize string `json:"VirtualSize"`
+}
+
+// UnmarshalJSON decodes a listing line leniently: missing and null fields are
+// left empty, numbers are kept as their text and N/A counts as empty, so only
+// malformed JSON fails. Aliased fields fill the ones they stand for, and
+// fields no checked release prints are logged once and ignored.
+func (d *dockerImage) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	targets := map[string]*string{
+		"Containers":   &d.Containers,
+		"CreatedAt":    &d.CreatedAt,
+		"CreatedSince": &d.CreatedSince,
+		"Digest":       &d.Digest,
+		"ID":           &d.ID,
+		"Repository":   &d.Repository,
+		"SharedSize":   &d.SharedSize,
+		"Size":         &d.Size,
+		"Tag":          &d.Tag,
+		"UniqueSize":   &d.UniqueSize,
+		"VirtualSize":  &d.VirtualSize,
+	}
+	for key, raw := range fields {
+		if alias, ok := dockerListingAliases[key]; ok {
+			// The field's own name wins over an alias.
+			if _, ok := fields[alias]; ok {
+				continue
+			}
+			key = alias
+		}
+		target, ok := targets[key]
+		if !ok {
+			if !contains(dockerListingFields, key) {
+				listingWarned.Warn(fmt.Sprintf("image listing has an unknown field %q, ignoring it", key))
+			}
+			continue
+		}
+		if string(raw) == "null" {
+			continue
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			*target = string(raw)
+		}
+		if *target == notAvailable {
+			*target = ""
+		}
+	}
+	if d.Size == "" {
+		d.Size = d.VirtualSize
+	}
+	if created, ok := unixTime(d.CreatedAt); ok {
+		d.CreatedAt = created.Format(dockerTimeLayout)
+	}
+	if d.ID == "" {
+		listingWarned.Warn("image listing lines have no ID field, check --image-list-format")
+	}
+	return nil
 }
 
 type kindImages struct {
 	Images []kindImage `json:"images"`
+	// Backend is the tool the images were listed with, crictl or ctr.
+	Backend string `json:"-"`
 }
 
 type kindImage struct {
@@ -54,62 +258,520 @@ type kindImage struct {
 	RepoDigests []string `json:"repoDigests"`
 	Size        string   `json:"size"`
 	Username    string   `json:"username"`
+	// Pinned is set by newer crictl releases for images the kubelet must
+	// keep, such as the pod sandbox image. Older releases omit it.
+	Pinned bool `json:"pinned"`
 }
 
-func listKindImages() kindImages {
-	cmd := exec.Command("docker", "exec", "kind-control-plane", "crictl", "images", "--output=json") //, "images", "--output json")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
+// dockerTimeLayout is the format docker uses for CreatedAt.
+const dockerTimeLayout = "2006-01-02 15:04:05 -0700 MST"
+
+// Created parses the CreatedAt time reported by docker. The zone
+// abbreviation is ignored because zones without one are printed as the
+// offset again, e.g. "2023-01-02 15:04:05 +0200 +0200", which time.Parse rejects.
+// Listings from other CLIs may use RFC 3339 instead.
+func (d dockerImage) Created() (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, d.CreatedAt); err == nil {
+		return t, nil
+	}
+	fields := strings.Fields(d.CreatedAt)
+	if len(fields) < 3 {
+		return time.Time{}, fmt.Errorf("invalid created time %q", d.CreatedAt)
+	}
+	return time.Parse("2006-01-02 15:04:05 -0700", strings.Join(fields[:3], " "))
+}
+
+// Reference returns the repo:tag reference for the image, falling back to the
+// image ID for dangling images which have no usable repository or tag.
+func (d dockerImage) Reference() string {
+	if d.Repository == "<none>" || d.Tag == "<none>" {
+		return d.ID
+	}
+	return fmt.Sprintf("%s:%s", d.Repository, d.Tag)
+}
+
+// groupByID merges the one line per tag docker image ls prints for an image.
+// It returns the first line for each image ID, in order, and all of each
+// image's repo:tag references.
+func groupByID(images []dockerImage) ([]dockerImage, map[string][]string) {
+	var grouped []dockerImage
+	tags := map[string][]string{}
+	for _, image := range images {
+		if _, ok := tags[image.ID]; !ok {
+			grouped = append(grouped, image)
+			tags[image.ID] = nil
+		}
+		if ref := image.Reference(); ref != image.ID {
+			tags[image.ID] = append(tags[image.ID], ref)
+		}
+	}
+	return grouped, tags
+}
+
+// Find returns the listed image whose ID or one of whose repo tags is ref.
+func (k kindImages) Find(ref string) (kindImage, bool) {
+	for _, image := range k.Images {
+		if image.ID == ref || sameImageID(image.ID, ref) {
+			return image, true
+		}
+		for _, repoTag := range image.RepoTags {
+			if repoTag == ref {
+				return image, true
+			}
+		}
+	}
+	return kindImage{}, false
+}
+
+// Reference returns a pull-by-digest reference for the given repo tag when
+// crictl reports a matching digest, otherwise the repo tag itself.
+func (k kindImage) Reference(repoTag string) string {
+	if repoDigest := matchRepoDigest(repoTag, k.RepoDigests); repoDigest != "" {
+		return repoDigest
+	}
+	return repoTag
+}
+
+// matchRepoDigest returns the repo@digest reference from repoDigests for the
+// repository of repoTag, or "" if the image has no digest for it.
+func matchRepoDigest(repoTag string, repoDigests []string) string {
+	repo := repoTag
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	for _, repoDigest := range repoDigests {
+		if strings.HasPrefix(repoDigest, repo+"@") {
+			return repoDigest
+		}
+	}
+	return ""
+}
+
+// shortDigest renders the digest of a repo@digest reference truncated to 12
+// characters, like image IDs, or "—" for images that were never pushed or pulled.
+func shortDigest(repoDigest string) string {
+	i := strings.Index(repoDigest, "@")
+	if i < 0 {
+		return "—"
+	}
+	digest := strings.TrimPrefix(repoDigest[i+1:], "sha256:")
+	if len(digest) > 12 {
+		digest = digest[:12]
+	}
+	return digest
+}
+
+func listKindImages() (kindImages, error) {
+	var images kindImages
+	err := withRetry("listKindImages", func() error {
+		var err error
+		images, err = listNodeImages(kindNode)
+		return err
+	})
+	return images, err
+}
+
+// listNodeImages lists the images in the containerd store of a kind node.
+func listNodeImages(node string) (kindImages, error) {
+	cmd := crictlCommand(node, "images", "--output="+crictlOutput)
+	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return kindImages{}, commandError("crictl images", err, "")
+	}
+	release := acquireProcess()
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		release()
+		return kindImages{}, commandError("crictl images", err, stderr.String())
+	}
 
-	err := cmd.Run()
+	images, parseErr := parseCrictlImages(stdout)
+	// Drain what the decoder left so crictl never blocks on a full pipe.
+	_, _ = io.Copy(ioutil.Discard, stdout)
+	err = cmd.Wait()
+	logCommand(cmd, started)
+	// The slot is freed before falling back to ctr, which takes its own:
+	// nodes listed at once would otherwise each hold one and wait forever
+	// for a second.
+	release()
 	if err != nil {
-		log.Fatalf("failed crictl: %s", err)
+		if crictlMissing(stderr.String()) {
+			return listCtrImages(node)
+		}
+		return kindImages{}, commandError("crictl images", err, stderr.String())
 	}
 
+	images.Backend = backendCrictl
+	return images, parseErr
+}
+
+// parseCrictlImages decodes crictl images --output=json. A node without images
+// may print nothing at all or {"images":null}, both of which are no images.
+func parseCrictlImages(r io.Reader) (kindImages, error) {
 	var images kindImages
-	err = json.Unmarshal(stdout.Bytes(), &images)
-	if err != nil {
-		log.Fatalf("failed crictl json: %s", err)
+
+	// Entries are decoded one at a time so a single entry in a format this
+	// version does not understand only drops that entry.
+	var raw struct {
+		Images []json.RawMessage `json:"images"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		if err == io.EOF {
+			return images, nil
+		}
+		return images, fmt.Errorf("could not parse crictl images output: %w", err)
+	}
+	for _, entry := range raw.Images {
+		var image kindImage
+		if err := json.Unmarshal(entry, &image); err != nil {
+			log.Printf("warning: skipping crictl image entry %s: %s", entry, err)
+			continue
+		}
+		images.Images = append(images.Images, image)
+	}
+	return images, nil
+}
+
+// UnmarshalJSON accepts the size as either a string or a number, since
+// crictl has printed both depending on the release. crictl prints the uid
+// as an object, {"value": "65535"}.
+func (k *kindImage) UnmarshalJSON(data []byte) error {
+	type plain kindImage
+	var image struct {
+		plain
+		UID  json.RawMessage `json:"uid"`
+		Size json.RawMessage `json:"size"`
+	}
+	if err := json.Unmarshal(data, &image); err != nil {
+		return err
+	}
+
+	*k = kindImage(image.plain)
+	k.UID = ""
+	if len(image.UID) > 0 && string(image.UID) != "null" {
+		var uid struct {
+			Value json.Number `json:"value"`
+		}
+		if err := json.Unmarshal(image.UID, &uid); err != nil {
+			return fmt.Errorf("uid: %w", err)
+		}
+		k.UID = uid.Value.String()
+	}
+	k.Size = ""
+	if len(image.Size) > 0 && string(image.Size) != "null" {
+		var size json.Number
+		if err := json.Unmarshal(image.Size, &size); err != nil {
+			return fmt.Errorf("size: %w", err)
+		}
+		k.Size = size.String()
+	}
+	return nil
+}
+
+// parseError reports lines of command output that could not be decoded while
+// the rest of the output was still usable.
+type parseError struct {
+	Command string
+	Failed  int
+	Total   int
+	Err     error
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("could not parse %d of %d lines of %s output, the output format may have changed: %s",
+		e.Failed, e.Total, e.Command, e.Err)
+}
+
+func (e *parseError) Unwrap() error {
+	return e.Err
+}
+
+// commandError describes a failed command by its exit code, keeping it apart
+// from output that could not be parsed.
+func commandError(command string, err error, stderr string) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%s exited with status %d: %w: %s", command, exitErr.ExitCode(), err, strings.TrimSpace(stderr))
 	}
+	return fmt.Errorf("%s failed: %w: %s", command, err, strings.TrimSpace(stderr))
+}
 
-	return images
+// listDockerImages lists the local images of the host runtime. When some
+// lines of output cannot be parsed it returns the images it could parse along
+// with a *parseError.
+func listDockerImages() ([]dockerImage, error) {
+	var images []dockerImage
+	err := withRetry("listDockerImages", func() error {
+		var err error
+		images, err = host.ListImages()
+		return err
+	})
+	if err == nil {
+		lastHostListing.Record(images)
+	}
+	return images, err
 }
 
-func listDockerImages() []dockerImage {
-	cmd := exec.Command("docker", "image", "ls", "--format={{json .}}") //, "--format={{json .}}") // image ls --format={{json .}}")
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
+// listImageLines runs an image listing that prints one JSON object per line,
+// like docker image ls --format={{json .}}.
+func listImageLines(name string, args ...string) ([]dockerImage, error) {
+	command := name + " " + strings.Join(args[:len(args)-1], " ")
+	listingWarned.CheckVersion(name)
+	cmd := exec.Command(name, args...)
+	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
-	err := cmd.Run()
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		log.Fatalf("failed %s", err)
+		return nil, commandError(command, err, "")
+	}
+	release := acquireProcess()
+	defer release()
+	started := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, commandError(command, err, stderr.String())
 	}
+	defer logCommand(cmd, started)
 
-	imageSlice := strings.Split(string(stdout.Bytes()), "\n")
+	images, parseErr := parseDockerImages(stdout)
+	_, _ = io.Copy(ioutil.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return nil, commandError(command, err, stderr.String())
+	}
 
+	var partial *parseError
+	if errors.As(parseErr, &partial) {
+		partial.Command = command
+	}
+	return images, parseErr
+}
+
+// skippedLineLength is how much of a line that could not be parsed is
+// logged with --verbose.
+const skippedLineLength = 200
+
+// parseDockerImages decodes image listings with one JSON object per line.
+// Blank lines are skipped, and lines that are not valid JSON are counted in
+// a *parseError returned along with the images that could be parsed.
+func parseDockerImages(r io.Reader) ([]dockerImage, error) {
 	var images []dockerImage
-	for _, i := range imageSlice {
+	var parseErr *parseError
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
 		var image dockerImage
-		err = json.Unmarshal([]byte(i), &image)
-		if err != nil {
+		if err := json.Unmarshal(line, &image); err != nil {
+			if parseErr == nil {
+				parseErr = &parseError{Err: err}
+			}
+			parseErr.Failed++
+			if verbose {
+				text := string(line)
+				if len(text) > skippedLineLength {
+					text = text[:skippedLineLength] + "..."
+				}
+				log.Printf("skipping line %d of the image listing: %s: %q", n, err, text)
+			}
 			continue
 		}
 		images = append(images, image)
-		// fmt.Printf("%+v\n", image)
 	}
-	return images
+	if err := scanner.Err(); err != nil {
+		return images, fmt.Errorf("could not read image listing: %w", err)
+	}
+	if parseErr != nil {
+		parseErr.Total = len(images) + parseErr.Failed
+		return images, parseErr
+	}
+	return images, nil
 }
 
 func main() {
 	// Remove the prefix from the go logger since Octant will print logs with timestamps.
 	log.SetPrefix("")
 
-	p := &imagePlugin{}
+	if err := parseConfig(os.Args[1:]); err != nil {
+		log.Fatalf("invalid configuration: %s", err)
+	}
+
+	if host.Name() == "docker" {
+		if e, err := pinDockerEndpoint(); err == nil {
+			endpoint = e
+			log.Printf("using docker %s", endpoint)
+		} else {
+			log.Printf("unable to determine docker endpoint: %s", err)
+		}
+	}
+
+	p := &imagePlugin{
+		queue:          newJobQueue("load", queueSize),
+		progress:       &operationProgress{},
+		deleteProgress: &operationProgress{},
+		forcePrompt:    &imagePrompt{},
+		removeProgress: &operationProgress{},
+		pushes:         newJobQueue("push", queueSize),
+		pushProgress:   &operationProgress{},
+		registries:     &registryDetector{},
+		pushPrompt:     &imagePrompt{},
+		loadPrompt:     &imagePrompt{},
+		tagPrompt:      &imagePrompt{},
+		saves:          newJobQueue("save", queueSize),
+		saveProgress:   &operationProgress{},
+		savePrompt:     &imagePrompt{},
+		copies:         newJobQueue("copy", queueSize),
+		copyProgress:   &operationProgress{},
+		copyPrompt:     &imagePrompt{},
+		history:        &pushHistory{},
+		archiveError:   &formError{},
+		pulls:          newJobQueue("pull", queueSize),
+		pullProgress:   &operationProgress{},
+		pullError:      &formError{},
+		gets:           newJobQueue("get", queueSize),
+		getProgress:    &operationProgress{},
+		getError:       &formError{},
+		scanQueue:      newJobQueue("scan", queueSize),
+		scans:          newScanCache(),
+		clusterOps:     newJobQueue("cluster", 1),
+		setupProgress:  &operationProgress{},
+		clusterError:   &formError{},
+		cleanups:       newJobQueue("registry cleanup", 1),
+		cleanProgress:  &operationProgress{},
+		pages:          newTablePages(),
+		sorts:          newTableSorts(),
+		systemImages:   &systemFilter{hidden: hideSystemImages},
+		commands:       &commandToggle{},
+		autoSync:       newAutoSyncer(autoSync),
+		stateSaver:     &stateSaver{},
+		labels:         &labelFilter{},
+		projects:       &projectFilter{},
+		specs:          newImageSpecCache(),
+		details:        newImageDetailCache(),
+		inspects:       newDockerInspectCache(),
+		selfChecks:     &selfCheckCache{},
+		overviews:      newOverviewFlight(),
+		environment:    &environment{},
+		health:         &healthProbe{},
+		diskUsage:      &diskUsageCache{},
+		strays:         &strayImageCache{},
+		feedback:       &actionFeedback{},
+		activity:       &activityLog{},
+		stats:          &sessionStats{},
+		loadTimes:      &loadTimes{},
+		clusters:       &clusterWatcher{},
+		nodeImages:     newNodeImageCache(),
+		refresher:      &refresher{},
+		buildx:         &buildxCache{},
+		buildError:     &formError{},
+		maintenance:    newJobQueue("maintenance", queueSize),
+		maintProgress:  &operationProgress{},
+		hostUsage:      &hostUsageCache{},
+		pressure:       &pressureCache{},
+		policies:       &pullPolicyCache{},
+		selection:      &kindSelection{},
+		bulkDeletes:    &bulkDeleteReport{},
+		staleOnly:      &staleFilter{},
+		digests:        newRegistryDigestCache(),
+	}
+	// Registering goes ahead either way; the views explain what is missing.
+	for _, check := range failedChecks(p.selfChecks.Get()) {
+		log.Printf("warning: self-check %s failed: %s", check.Name, check.Err)
+	}
+	// Detected up front so loads can be checked against the kind release.
+	detectKindVersion()
+	p.loadState()
+	inflight.Recover()
+	p.queue.Finished = func(job queuedJob, err error) {
+		p.nodeImages.Reset()
+		p.details.Reset()
+		name := "load"
+		if job.Auto {
+			name = "auto-sync load"
+		}
+		record := jobRecord(name, job, err)
+		record.Phases = p.progress.PhaseTimings()
+		// Loads that copied the image were timed, with its size.
+		if sample, ok := p.loadTimes.Latest("", job.ImageID); ok && err == nil && !sample.At.Before(job.StartedAt) {
+			record.Size = sample.Size
+		}
+		p.activity.Add(record)
+		p.stats.RecordLoad(job, err)
+	}
+	p.pushes.Finished = p.recordJob("push")
+	p.saves.Finished = p.recordJob("save")
+	p.copies.Finished = p.recordJob("copy")
+	recordPull := p.recordJob("pull")
+	p.pulls.Finished = func(job queuedJob, err error) {
+		p.nodeImages.Reset()
+		p.details.Reset()
+		recordPull(job, err)
+	}
+	recordGet := p.recordPhasedJob("get", p.getProgress)
+	p.gets.Finished = func(job queuedJob, err error) {
+		p.nodeImages.Reset()
+		p.details.Reset()
+		recordGet(job, err)
+	}
+	p.scanQueue.Finished = p.recordJob("scan")
+	p.cleanups.Finished = p.recordJob("registry gc")
+	p.maintenance.Finished = func(job queuedJob, err error) {
+		p.activity.Add(jobRecord(job.Target, job, err))
+		// The action that queued the job has long returned, so its error
+		// is shown like one of an action.
+		if err != nil {
+			p.feedback.Record(err)
+		}
+	}
+	go p.queue.Run(func(ctx context.Context, job queuedJob) error {
+		if loadTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, loadTimeout)
+			defer cancel()
+		}
+		if job.Archive {
+			return p.loadArchive(ctx, job.ImageID)
+		}
+		if job.Platform != "" {
+			return p.loadPlatformImage(ctx, job.ImageID, job.Platform)
+		}
+		return p.loadImage(ctx, job.ImageID)
+	})
+	go p.pushes.Run(p.pushImage)
+	go p.saves.Run(p.saveImage)
+	go p.copies.Run(p.copyImage)
+	go p.pulls.Run(p.pullImage)
+	go p.gets.Run(func(ctx context.Context, job queuedJob) error {
+		if job.Build != nil {
+			return p.buildImage(ctx, job)
+		}
+		return p.getImage(ctx, job)
+	})
+	go p.scanQueue.Run(p.scanImage)
+	go p.cleanups.Run(p.collectRegistryGarbage)
+	go p.maintenance.Run(p.runMaintenance)
+	p.clusters.Changed = p.clustersChanged
+	go p.clusters.Run()
+	go p.autoSync.Run(p)
+	go p.clusterOps.Run(func(ctx context.Context, job queuedJob) error {
+		switch job.Target {
+		case "start":
+			return p.startCluster(ctx, job)
+		case "delete":
+			return p.deleteCluster(ctx, job)
+		}
+		return p.createCluster(ctx, job)
+	})
+
+	p.api = p.startAPI()
 
 	// Tell Octant to call this plugin when printing configuration or tabs for Pods
 	capabilities := &plugin.Capabilities{
-		ActionNames: []string{deleteAction, loadAction},
+		SupportsTab: []schema.GroupVersionKind{{Version: "v1", Kind: "Pod"}},
+		ActionNames: names.Actions(),
 		IsModule:    true,
 	}
 
@@ -117,182 +779,957 @@ func main() {
 	options := []service.PluginOption{
 		service.WithNavigation(p.handleNav, p.initRoutes),
 		service.WithActionHandler(p.handleActions),
+		service.WithTabPrinter(p.handleTab),
 	}
 
 	// Use the plugin service helper to register this plugin.
-	ps, err := service.Register(pluginName, "kind images plugin", capabilities, options...)
+	ps, err := service.Register(names.Plugin, "kind images plugin", capabilities, options...)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	// The plugin can log and the log messages will show up in Octant.
 	log.Printf("docker registry plugin is starting")
+	go p.stopOnSignal()
 	ps.Serve()
+	p.shutdown()
 }
 
-func (i *imagePlugin) IsLoading() bool {
-	return atomic.LoadInt32(&(i.loading)) != 0
-}
-
-func (i *imagePlugin) SetLoading(b bool) {
-	var j int32 = 0
-	if b {
-		j = 1
+// handleActions runs an action and records its outcome. Octant only logs the
+// errors returned from actions, so the views render the recorded error instead.
+func (i *imagePlugin) handleActions(request *service.ActionRequest) error {
+	started := time.Now()
+	err := i.runAction(request)
+	if err != nil {
+		log.Printf("action %s failed: %s", request.ActionName, err)
 	}
-	atomic.StoreInt32(&(i.loading), j)
+	i.feedback.Record(err)
+	i.activity.Add(newActivityRecord(request, started, err))
+	return err
 }
 
-func (i *imagePlugin) handleActions(request *service.ActionRequest) error {
+func (i *imagePlugin) runAction(request *service.ActionRequest) error {
 	switch request.ActionName {
-	case loadAction:
-		if i.IsLoading() {
-			return fmt.Errorf("already loading an image, please wait")
+	case names.Load:
+		imageID, err := payloadImage(request.Payload)
+		if err != nil {
+			return err
 		}
-
+		platform, err := request.Payload.OptionalString("platform")
+		if err != nil {
+			return err
+		}
+		if platform != "" {
+			if err := validatePlatform(platform); err != nil {
+				i.loadPrompt.SetError(err)
+				return err
+			}
+			i.loadPrompt.Set("")
+		}
+		prompt, _ := request.Payload.OptionalString("prompt")
+		err = i.checkLocalImage(imageID)
+		if err == nil {
+			err = i.queue.Enqueue(queuedJob{ImageID: imageID, Platform: platform})
+		}
+		if err != nil {
+			if prompt == "tag" {
+				i.tagPrompt.SetError(err)
+			}
+			return err
+		}
+		if prompt == "tag" {
+			i.tagPrompt.Set("")
+		}
+		log.Printf("queued %s for loading into kind", imageID)
+		return nil
+	case names.LoadPrompt:
+		return setPrompt(i.loadPrompt, request)
+	case names.TagPrompt:
+		return setPrompt(i.tagPrompt, request)
+	case names.Push:
+		imageID, err := payloadImage(request.Payload)
+		if err != nil {
+			return err
+		}
+		registry := i.registries.Last()
+		if registry == nil {
+			return fmt.Errorf("no local registry found, is the %s container running?", registryContainer)
+		}
+		if err := i.pushes.Enqueue(queuedJob{ImageID: imageID, Target: registry.Target(imageID)}); err != nil {
+			return err
+		}
+		log.Printf("queued %s for pushing to the local registry", imageID)
+		return nil
+	case names.PushPrompt:
+		return setPrompt(i.pushPrompt, request)
+	case names.PushTo:
+		source, err := request.Payload.String("source")
+		if err != nil {
+			return err
+		}
+		if err := validateReference(source); err != nil {
+			return err
+		}
+		destination, err := request.Payload.String("destination")
+		if err != nil {
+			return err
+		}
+		destination = strings.TrimSpace(destination)
+		if err := validateReference(destination); err != nil {
+			i.pushPrompt.SetError(err)
+			return err
+		}
+		i.pushPrompt.Set("")
+		if err := i.pushes.Enqueue(queuedJob{ImageID: source, Target: destination}); err != nil {
+			return err
+		}
+		log.Printf("queued %s for pushing to %s", source, destination)
+		return nil
+	case names.SavePrompt:
+		return setPrompt(i.savePrompt, request)
+	case names.Save:
+		source, err := request.Payload.String("source")
+		if err != nil {
+			return err
+		}
+		if err := validateReference(source); err != nil {
+			return err
+		}
+		path, err := request.Payload.String("path")
+		if err != nil {
+			return err
+		}
+		// Checkboxes submit the values of the checked choices.
+		overwrite, _ := request.Payload.StringSlice("overwrite")
+		path, err = checkSavePath(path, len(overwrite) > 0)
+		if err != nil {
+			i.savePrompt.SetError(err)
+			return err
+		}
+		i.savePrompt.Set("")
+		if err := i.saves.Enqueue(queuedJob{ImageID: source, Target: path}); err != nil {
+			return err
+		}
+		log.Printf("queued %s for saving to %s", source, path)
+		return nil
+	case names.CopyPrompt:
+		return setPrompt(i.copyPrompt, request)
+	case names.Copy:
+		source, err := request.Payload.String("source")
+		if err != nil {
+			return err
+		}
+		if err := validateReference(source); err != nil {
+			return err
+		}
+		cluster, err := request.Payload.String("cluster")
+		if err != nil || cluster == "" || cluster == kindCluster {
+			err := fmt.Errorf("choose a cluster other than %s to copy %s to", kindCluster, source)
+			i.copyPrompt.SetError(err)
+			return err
+		}
+		if clusters, _ := i.clusters.Clusters(); !contains(clusters, cluster) {
+			err := fmt.Errorf("%q is not a kind cluster", cluster)
+			i.copyPrompt.SetError(err)
+			return err
+		}
+		i.copyPrompt.Set("")
+		if err := i.copies.Enqueue(queuedJob{ImageID: source, Target: cluster}); err != nil {
+			return err
+		}
+		log.Printf("queued %s for copying to cluster %s", source, cluster)
+		return nil
+	case names.Pull:
+		ref, err := request.Payload.String("image")
+		ref = strings.TrimSpace(ref)
+		if err == nil {
+			err = validateReference(ref)
+		}
+		if err != nil {
+			i.pullError.Set(err)
+			return err
+		}
+		// Pulling on the host and loading is the same operation as Get.
+		if via, _ := request.Payload.StringSlice("via"); len(via) > 0 {
+			i.pullError.Set(nil)
+			return i.queueGet(ref)
+		}
+		creds, err := pullCreds(request.Payload, ref)
+		if err != nil {
+			i.pullError.Set(err)
+			return err
+		}
+		i.pullError.Set(nil)
+		if err := i.pulls.Enqueue(queuedJob{ImageID: ref, Creds: creds}); err != nil {
+			return err
+		}
+		log.Printf("queued %s for pulling into kind", ref)
+		return nil
+	case names.Get:
+		ref, err := request.Payload.String("image")
+		ref = strings.TrimSpace(ref)
+		if err == nil {
+			err = validateReference(ref)
+		}
+		if err != nil {
+			i.getError.Set(err)
+			return err
+		}
+		i.getError.Set(nil)
+		return i.queueGet(ref)
+	case names.BuildxLoad:
+		return i.buildxLoadAction(request.Payload)
+	case names.LoadArchive:
+		path, err := request.Payload.String("path")
+		if err != nil {
+			return err
+		}
+		path, err = checkArchive(path)
+		i.archiveError.Set(err)
+		if err != nil {
+			return err
+		}
+		if err := i.queue.Enqueue(queuedJob{ImageID: path, Archive: true}); err != nil {
+			return err
+		}
+		log.Printf("queued archive %s for loading into kind", path)
+		return nil
+	case names.Delete:
+		imageID, err := payloadImage(request.Payload)
+		if err != nil {
+			return err
+		}
+		force, _ := request.Payload.Bool("force")
+		err = i.deleteImage(imageID, force)
+		i.stats.RecordDelete(err)
+		return err
+	case names.ForceDelete:
 		imageID, err := request.Payload.String("imageID")
 		if err != nil {
 			return err
 		}
-		return i.loadImage(imageID)
-	case deleteAction:
+		if imageID == "" {
+			i.forcePrompt.Set("")
+			return nil
+		}
+		err = i.forceDeleteImage(imageID)
+		i.stats.RecordDelete(err)
+		return err
+	case names.StaleOnly:
+		table, _ := request.Payload.String("table")
+		i.staleOnly.Toggle(table)
+		return nil
+	case names.PruneStale:
+		table, _ := request.Payload.String("table")
+		if table == dockerTableName {
+			return i.queueMaintenance(queuedJob{ImageID: "Deleting stale " + host.Name() + " images", Target: staleHostJob})
+		}
+		return i.queueMaintenance(queuedJob{ImageID: "Deleting stale kind images", Target: staleKindJob})
+	case names.PruneKind:
+		return i.queueMaintenance(queuedJob{ImageID: "Deleting unused kind images", Target: pruneJob})
+	case names.Select:
+		return i.selectImages(request.Payload)
+	case names.BulkDelete:
+		return i.queueBulkDelete()
+	case names.Sync:
+		set, err := request.Payload.String("set")
+		if err != nil {
+			return err
+		}
+		repository, _ := request.Payload.OptionalString("repository")
+		return i.syncImages(set, repository)
+	case names.MoveStray, names.DeleteStray:
+		node, err := request.Payload.String("node")
+		if err != nil {
+			return err
+		}
+		namespace, err := request.Payload.String("namespace")
+		if err != nil {
+			return err
+		}
+		ref, err := request.Payload.String("imageID")
+		if err != nil {
+			return err
+		}
+		if request.ActionName == names.MoveStray {
+			return i.queueStray(moveStrayJob, node, namespace, ref)
+		}
+		return i.queueStray(deleteStrayJob, node, namespace, ref)
+	case names.DeleteTag:
+		repository, err := request.Payload.String("repository")
+		if err != nil {
+			return err
+		}
+		digest, err := request.Payload.String("digest")
+		if err != nil {
+			return err
+		}
+		return i.deleteRegistryManifest(repository, digest)
+	case names.RegistryGC:
+		return i.cleanups.Enqueue(queuedJob{ImageID: "Collecting local registry garbage"})
+	case names.DeleteHost:
+		imageID, err := payloadImage(request.Payload)
+		if err != nil {
+			return err
+		}
+		err = i.deleteHostImage(imageID)
+		i.stats.RecordDelete(err)
+		return err
+	case names.Cancel:
 		imageID, err := request.Payload.String("imageID")
 		if err != nil {
 			return err
 		}
-		return i.deleteImage(imageID)
+		// Loads are cancelled unless another operation is named.
+		operation, _ := request.Payload.OptionalString("operation")
+		switch operation {
+		case "scan":
+			if !i.scanQueue.Cancel(imageID) {
+				return fmt.Errorf("%s is not being scanned or queued", imageID)
+			}
+			return nil
+		case "orphan":
+			return inflight.Stop(imageID)
+		}
+		if !i.queue.Cancel(imageID) {
+			return fmt.Errorf("%s is not loading or queued", imageID)
+		}
+		return nil
+	case names.Scan:
+		ref, err := request.Payload.String("imageID")
+		if err != nil {
+			return err
+		}
+		id, err := request.Payload.String("id")
+		if err != nil {
+			return err
+		}
+		if err := validateReference(ref); err != nil {
+			return err
+		}
+		if !imageIDPattern.MatchString(id) {
+			return fmt.Errorf("%q is not an image ID", id)
+		}
+		if !scanAvailable() {
+			return fmt.Errorf("scanning needs --scan-images and trivy on the PATH")
+		}
+		return i.scanQueue.Enqueue(queuedJob{ImageID: ref, Target: id})
+	case names.Sort:
+		table, err := request.Payload.String("table")
+		if err != nil {
+			return err
+		}
+		order, err := request.Payload.String("order")
+		if err != nil {
+			return err
+		}
+		if err := i.sorts.Set(table, order); err != nil {
+			return err
+		}
+		i.saveState()
+		return nil
+	case names.Page:
+		table, err := request.Payload.String("table")
+		if err != nil {
+			return err
+		}
+		direction, err := request.Payload.String("direction")
+		if err != nil {
+			return err
+		}
+		switch direction {
+		case "next":
+			i.pages.Move(table, 1)
+		case "previous":
+			i.pages.Move(table, -1)
+		default:
+			return fmt.Errorf("unknown page direction %q", direction)
+		}
+		return nil
+	case names.LabelFilter:
+		selector, err := request.Payload.OptionalString("label")
+		if err != nil {
+			return err
+		}
+		if err := i.labels.Set(selector); err != nil {
+			return err
+		}
+		i.saveState()
+		return nil
+	case names.Project:
+		project, err := request.Payload.OptionalString("project")
+		if err != nil {
+			return err
+		}
+		i.projects.Set(project)
+		i.saveState()
+		return nil
+	case names.LoadProject:
+		project, err := request.Payload.String("project")
+		if err != nil {
+			return err
+		}
+		return i.loadProject(project)
+	case names.NewCluster:
+		spec, err := i.clusterSpecFromPayload(request.Payload)
+		if err != nil {
+			i.clusterError.Set(err)
+			return err
+		}
+		i.clusterError.Set(nil)
+		return i.clusterOps.Enqueue(queuedJob{ImageID: "Creating kind cluster " + spec.Name, Target: "create", Cluster: &spec})
+	case names.StartCluster:
+		return i.clusterOps.Enqueue(queuedJob{ImageID: "Starting kind cluster " + kindCluster, Target: "start", Cluster: &clusterSpec{Name: kindCluster}})
+	case names.Teardown:
+		cluster, err := request.Payload.String("cluster")
+		if err != nil {
+			return err
+		}
+		return i.queueDeleteCluster(cluster)
+	case names.Refresh:
+		return i.queueMaintenance(queuedJob{ImageID: "Refreshing kind images", Target: refreshJob})
+	case names.RefreshNow:
+		i.refreshNow()
+		return nil
+	case names.ToggleSystem:
+		i.systemImages.Toggle()
+		i.saveState()
+		return nil
+	case names.Commands:
+		i.commands.Toggle()
+		i.saveState()
+		return nil
+	case names.AutoSync:
+		i.autoSync.Toggle()
+		i.saveState()
+		return nil
 	default:
 		return fmt.Errorf("unhandled action")
 	}
 }
 
-func (i *imagePlugin) loadImage(imageID string) error {
-	i.SetLoading(true)
-	defer i.SetLoading(false)
+func (i *imagePlugin) loadImage(ctx context.Context, imageID string) (err error) {
+	started := time.Now()
+	i.progress.Start(fmt.Sprintf("Loading %s into kind", imageID))
+	upToDate, stream := false, false
+	defer func() {
+		success := fmt.Sprintf("Loaded %s into %d node(s) in %s", imageID, i.progress.Nodes(), i.progress.Elapsed())
+		phase, phases := i.progress.CurrentPhase()
+		switch {
+		case phase == "stream import":
+			success += " by streaming it into ctr images import"
+		case stream:
+			success += fmt.Sprintf(" with kind load after the streamed import failed (%s)", i.progress.PhaseTimings())
+		case phases > 1:
+			success += fmt.Sprintf(" after %d attempts (%s)", phases, i.progress.PhaseTimings())
+		}
+		if upToDate {
+			success = fmt.Sprintf("%s is already up to date in kind, every node has the same image ID", imageID)
+		} else if dryRun && stream {
+			success = fmt.Sprintf("Dry run: would have run %s", streamImportCommand(imageID))
+		} else if dryRun {
+			success = fmt.Sprintf("Dry run: would have run %s", host.LoadCommand(imageID))
+		} else if i.progress.Nodes() == 0 {
+			success = fmt.Sprintf("%s was already present on all nodes", imageID)
+		}
+		i.progress.Finish(err, success)
+	}()
 
-	// kind load docker-image {{imageID}}
-	cmd := exec.Command("kind", "load", "docker-image", imageID)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	// kind load docker-image saves the whole image before finding out the
+	// nodes have it, so identical images are skipped up front. A rebuilt
+	// image has a new ID and is loaded as usual.
+	if ok, err := upToDateInKind(imageID); err != nil {
+		log.Printf("unable to compare %s with the kind images, loading it: %s", imageID, err)
+	} else if ok {
+		upToDate = true
+		return nil
+	}
 
-	err := cmd.Run()
-	if err != nil {
-		return fmt.Errorf("loadImage: %w", err)
+	if err := checkNodeCapacity(imageID); err != nil {
+		return fmt.Errorf("loadImage %s: %w", imageID, err)
+	}
+
+	stream = useStreamImport(imageID)
+	if dryRun {
+		command := host.LoadCommand(imageID)
+		if stream {
+			command = streamImportCommand(imageID)
+		}
+		log.Printf("dry run: %s", command)
+		return nil
+	}
+	streamed := false
+	if stream {
+		if streamed, err = i.streamLoad(ctx, imageID); err != nil {
+			return fmt.Errorf("loadImage %s: %w", imageID, err)
+		}
+	}
+	// Each attempt is a progress phase, so the status and the activity log
+	// show how many it took. The load timeout covers all of them.
+	attempts := 1 + loadRetries
+	delay := retryDelay
+	for attempt := 1; !streamed; attempt++ {
+		i.progress.Phase(fmt.Sprintf("attempt %d", attempt))
+		err := host.Load(ctx, imageID, i.progress.Write)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("loadImage %s: %w", imageID, err)
+		}
+		err = fmt.Errorf("loadImage %s: %w: %s", imageID, err, strings.Join(i.progress.Output(), "\n"))
+		if strings.Contains(err.Error(), "not present locally") {
+			return i.withBuildxHint(err, imageID)
+		}
+		if attempt == attempts || !isTransientLoad(err) {
+			if attempt > 1 {
+				return fmt.Errorf("%w (after %d attempts)", err, attempt)
+			}
+			return err
+		}
+		log.Printf("load of %s failed (attempt %d of %d), retrying in %s: %s", imageID, attempt, attempts, delay, err)
+		i.progress.Write(fmt.Sprintf("Transient failure, retrying in %s", delay))
+		if err := sleepContext(ctx, delay); err != nil {
+			return fmt.Errorf("loadImage %s: %w", imageID, err)
+		}
+		delay *= 2
+	}
+	// The image was copied once to each node that did not have it.
+	size, err := dockerImageBytes(imageID)
+	if err == nil {
+		i.stats.AddBytes(size * int64(i.progress.Nodes()))
+	}
+	if i.progress.Nodes() > 0 {
+		id, _ := dockerImageID(imageID)
+		i.loadTimes.Add(loadSample{Image: imageID, ImageID: id, Size: size, Duration: time.Since(started), At: time.Now()})
+		i.saveState()
 	}
 
 	return nil
 }
 
-func (i *imagePlugin) deleteImage(imageID string) error {
-	// kind load docker-image {{imageID}}
-	cmd := exec.Command("docker", "exec", "kind-control-plane", "crictl", "rmi", imageID)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// checkNodeCapacity refuses to load an image that would not fit on the
+// filesystem of every node it gets copied to.
+func checkNodeCapacity(imageID string) error {
+	size, err := dockerImageBytes(imageID)
+	if err != nil {
+		return err
+	}
 
-	err := cmd.Run()
+	nodes, err := listKindNodes()
 	if err != nil {
-		return fmt.Errorf("deleteImage: %w", err)
+		return err
+	}
+
+	for _, node := range nodes {
+		available, err := nodeAvailableBytes(node)
+		if err != nil {
+			// Not every node image ships a df that supports --output; let kind try anyway.
+			log.Printf("unable to check free space on %s: %s", node, err)
+			continue
+		}
+		if available < size {
+			return fmt.Errorf("image is %s but node %s only has %s free", formatBytes(size), node, formatBytes(available))
+		}
 	}
 	return nil
 }
 
-func (i *imagePlugin) handleNav(request *service.NavigationRequest) (navigation.Navigation, error) {
-	return navigation.Navigation{
-		Title:    "Local Images",
-		Path:     request.GeneratePath(""),
-		IconName: "storage",
-	}, nil
+// nodeImage is a kind image as listed on one node of the cluster.
+type nodeImage struct {
+	Node    string
+	Image   kindImage
+	Backend string
 }
 
-func (i *imagePlugin) initRoutes(router *service.Router) {
-	router.HandleFunc("*", i.handleOverview)
+// findDeletable returns the nodes that have the kind image imageID names,
+// when it may be deleted.
+func findDeletable(imageID string) ([]nodeImage, error) {
+	if err := validateReference(imageID); err != nil {
+		return nil, err
+	}
+	// Only delete what the kind table lists, so a crafted reference cannot
+	// resolve to some other image on the node.
+	var found []nodeImage
+	for _, node := range knownNodes() {
+		images, err := listNodeImages(node)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", node, err)
+		}
+		image, ok := images.Find(imageID)
+		if !ok {
+			continue
+		}
+		// Even a forced delete is refused, since removing the sandbox image breaks pod creation.
+		if image.Pinned {
+			return nil, fmt.Errorf("the image is pinned by the kubelet on %s, e.g. as the pod sandbox image, and can't be deleted", node)
+		}
+		found = append(found, nodeImage{Node: node, Image: image, Backend: images.Backend})
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no such image on the nodes of %s", kindCluster)
+	}
+	return found, nil
 }
 
-func (i *imagePlugin) handleOverview(request service.Request) (component.ContentResponse, error) {
-	table := component.NewTable("Docker Images", "No images found",
-		component.NewTableCols("Repository", "Tag", "Image ID", "Created", "Size"))
+// deleteImage deletes imageID from every node that has it. Unless force is
+// set, it refuses while running containers on any of them use the image.
+func (i *imagePlugin) deleteImage(imageID string, force bool) error {
+	found, err := findDeletable(imageID)
+	if err != nil {
+		return fmt.Errorf("deleteImage %s: %w", imageID, err)
+	}
 
-	for _, image := range listDockerImages() {
-		table.Add(rowPrinter(image))
+	for _, n := range found {
+		// Removing one of several tags leaves the image for its containers.
+		if force || keepsOtherTags(imageID, n.Image) {
+			continue
+		}
+		// ctr has no notion of pods, so nodes without crictl cannot be checked for users of the image.
+		if n.Backend == backendCtr {
+			log.Printf("warning: not checking whether %s is in use, crictl is not available on %s", imageID, n.Node)
+			continue
+		}
+		containers, err := crictlContainers(n.Node, "ps", "--output=json")
+		if err != nil {
+			return fmt.Errorf("deleteImage %s: %w", imageID, err)
+		}
+		if consumers := imageConsumers(containers)[n.Image.ID]; len(consumers) > 0 {
+			return fmt.Errorf("deleteImage %s: image is used by %d running container(s) on %s: %s",
+				imageID, len(consumers), n.Node, describeConsumers(consumers))
+		}
 	}
 
-	kindTable := component.NewTable("Kind Images", "No images found",
-		component.NewTableCols("Image", "Image ID", "Size"))
+	i.deleteProgress.Start(fmt.Sprintf("Deleting %s", imageID))
+	if dryRun {
+		var commands []string
+		for _, n := range found {
+			cmd := nodeDeleteCommand(n.Node, imageID, n.Image, n.Backend)
+			log.Printf("dry run: %s", commandLine(cmd))
+			commands = append(commands, commandLine(cmd))
+		}
+		i.deleteProgress.Finish(nil, fmt.Sprintf("Dry run: would have run %s", strings.Join(commands, "; ")))
+		return nil
+	}
 
-	for _, image := range listKindImages().Images {
-		for _, repoTag := range image.RepoTags {
-			kindTable.Add(kindPrinter(image, repoTag))
+	var failed, inUse []string
+	for _, n := range found {
+		cmd := nodeDeleteCommand(n.Node, imageID, n.Image, n.Backend)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := runCommand(cmd); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s: %s", n.Node, err, strings.TrimSpace(stderr.String())))
+			if isImageInUse(stderr.String()) {
+				inUse = append(inUse, n.Node)
+			}
+		}
+	}
+	// Reset even when some nodes failed, since the others changed.
+	i.nodeImages.Reset()
+	i.details.Reset()
+	if len(failed) > 0 {
+		err = fmt.Errorf("deleteImage %s: deleted on %d of %d node(s), failed on %s",
+			imageID, len(found)-len(failed), len(found), strings.Join(failed, "; "))
+		// Offer removing the containers that still reference it.
+		if len(inUse) > 0 {
+			i.forcePrompt.Set(imageID)
+			err = fmt.Errorf("deleteImage %s: the image is still referenced by containers on %s, "+
+				"use Force delete to remove them and the image: %s", imageID, strings.Join(inUse, ", "), strings.Join(failed, "; "))
 		}
+		i.deleteProgress.Finish(err, "")
+		return err
+	}
+	success := fmt.Sprintf("Deleted %s", imageID)
+	if len(found) > 1 {
+		success = fmt.Sprintf("Deleted %s from %d nodes", imageID, len(found))
+	}
+	if image := found[0].Image; keepsOtherTags(imageID, image) {
+		success = fmt.Sprintf("Deleted tag %s, the image keeps its other tags: %s", imageID, strings.Join(otherTags(image, imageID), ", "))
 	}
+	i.deleteProgress.Finish(nil, success)
+	return nil
+}
 
-	layout := flexlayout.New()
+// clusterInfo describes the kind cluster images get loaded into.
+type clusterInfo struct {
+	Nodes        int
+	Architecture string
+	// Registry is the local registry container, or nil when there is none.
+	Registry *localRegistry
+}
 
-	if i.IsLoading() {
-		loadingSection := layout.AddSection()
-		loadingSection.Add(component.NewText("Started loading image in to kind..."), component.WidthFull)
-		kindTable.SetIsLoading(true)
+// clusterInfo looks up what the host image rows need to know about the
+// cluster. Anything that cannot be determined is left empty.
+func (i *imagePlugin) clusterInfo(request service.Request) clusterInfo {
+	var cluster clusterInfo
+	if nodes, err := listKindNodes(); err == nil {
+		cluster.Nodes = len(nodes)
+	}
+	if arch, err := nodeArchitecture(kindNode); err == nil {
+		cluster.Architecture = arch
 	} else {
-		kindTable.SetIsLoading(false)
+		log.Printf("unable to determine kind node architecture: %s", err)
 	}
-
-	dockerSection := layout.AddSection()
-	dockerSection.Add(table, component.WidthFull)
-
-	kindSection := layout.AddSection()
-	kindSection.Add(kindTable, component.WidthFull)
-
-	flexComponent := layout.ToComponent("Local Images")
-	contentResponse := component.NewContentResponse(component.TitleFromString("Local Images"))
-	contentResponse.Add(flexComponent)
-	return *contentResponse, nil
+	if registry, err := i.registries.Detect(request.Context(), request.DashboardClient()); err == nil {
+		cluster.Registry = registry
+	} else {
+		log.Printf("unable to find local registry: %s", err)
+	}
+	return cluster
 }
 
-func rowPrinter(image dockerImage) component.TableRow {
+func rowPrinter(image dockerImage, tags []string, inspect dockerInspect, cluster clusterInfo) component.TableRow {
 	row := component.TableRow{}
-	row["Repository"] = component.NewText(fmt.Sprintf("%s", image.Repository))
-	row["Tag"] = component.NewText(fmt.Sprintf("%s", image.Tag))
-	row["Image ID"] = component.NewText(fmt.Sprintf("%s", image.ID))
-	row["Created"] = component.NewText(fmt.Sprintf("%s", image.CreatedSince))
-	row["Size"] = component.NewText(fmt.Sprintf("%s", image.Size))
+	row["Tags"] = textList(tags)
+	row["Image ID"] = imageIDLink(dockerImagePath, image.ID)
+	row["Reference"] = component.NewText(image.Reference())
+	row["Digest"] = component.NewText(shortDigest(matchRepoDigest(image.Reference(), inspect.RepoDigests)))
+	if created, err := image.Created(); err == nil {
+		row["Created"] = component.NewTimestamp(created)
+	} else {
+		row["Created"] = component.NewText(fmt.Sprintf("%s", image.CreatedSince))
+	}
+	// The size links to the layer history, which shows what makes an image large.
+	row["Size"] = imageHistoryLink(displaySize(image.Size), image.ID)
 
-	action := component.GridAction{
+	archMismatch := inspect.Architecture != "" && cluster.Architecture != "" && inspect.Architecture != cluster.Architecture
+	arch := component.NewText(inspect.Platform())
+	if archMismatch {
+		arch = component.NewTextf("%s (kind node is %s)", inspect.Platform(), cluster.Architecture)
+		arch.SetStatus(component.TextStatusWarning)
+	}
+	row["Architecture"] = arch
+	row["Project"] = composeProjectCell(inspect.Config.Labels)
+	row["Revision"] = revisionCell(inspect.Config.Labels)
+	for _, key := range labelColumns {
+		value, ok := inspect.Config.Labels[key]
+		if !ok {
+			value = "—"
+		}
+		row[key] = component.NewText(value)
+	}
+
+	loadGridAction := component.GridAction{
 		Name:       "Load into Kind",
-		ActionPath: loadAction,
+		ActionPath: names.Load,
 		Payload: action.Payload{
-			"action":  loadAction,
-			"imageID": fmt.Sprintf("%s:%s", image.Repository, image.Tag),
+			"action":  names.Load,
+			"imageID": image.Reference(),
 		},
 		Type: component.GridActionPrimary,
 	}
-	row.AddAction(action)
+
+	var warnings []string
+	if size, err := parseSize(image.Size); err == nil && size > largeImageThreshold {
+		warnings = append(warnings, fmt.Sprintf("%s is %s and will be copied into every node of the cluster (%d node(s)). "+
+			"Large images can fill the node filesystem.", image.Reference(), formatBytes(size), cluster.Nodes))
+	}
+	if archMismatch {
+		warnings = append(warnings, fmt.Sprintf("%s is built for %s but the kind node is %s, "+
+			"so containers using it will fail with exec format errors.", image.Reference(), inspect.Architecture, cluster.Architecture))
+	}
+	if len(warnings) > 0 {
+		loadGridAction.Confirmation = &component.Confirmation{
+			Title: "Load into Kind?",
+			Body:  strings.Join(warnings, " ") + " Do you want to continue?",
+		}
+	}
+	row.AddAction(loadGridAction)
+	// The Load action uses the first tag, the others are picked from a prompt.
+	if len(tags) > 1 {
+		row.AddAction(component.GridAction{
+			Name:       "Load tag…",
+			ActionPath: names.TagPrompt,
+			Payload: action.Payload{
+				"action":  names.TagPrompt,
+				"imageID": image.ID,
+			},
+			Type: component.GridActionPrimary,
+		})
+	}
+	row.AddAction(component.GridAction{
+		Name:       "Load for platform…",
+		ActionPath: names.LoadPrompt,
+		Payload: action.Payload{
+			"action":  names.LoadPrompt,
+			"imageID": image.Reference(),
+		},
+		Type: component.GridActionPrimary,
+	})
+
+	// Only tagged images can be pushed, an image ID has no repository to push to.
+	if cluster.Registry != nil && image.Reference() != image.ID {
+		row.AddAction(component.GridAction{
+			Name:       "Push to local registry",
+			ActionPath: names.Push,
+			Payload: action.Payload{
+				"action":  names.Push,
+				"imageID": image.Reference(),
+			},
+			Type: component.GridActionPrimary,
+		})
+	}
+	row.AddAction(component.GridAction{
+		Name:       "Push…",
+		ActionPath: names.PushPrompt,
+		Payload: action.Payload{
+			"action":  names.PushPrompt,
+			"imageID": image.Reference(),
+		},
+		Type: component.GridActionPrimary,
+	})
+	row.AddAction(component.GridAction{
+		Name:       "Save to tar…",
+		ActionPath: names.SavePrompt,
+		Payload: action.Payload{
+			"action":  names.SavePrompt,
+			"imageID": image.Reference(),
+		},
+		Type: component.GridActionPrimary,
+	})
+	row.AddAction(component.GridAction{
+		Name:       "Delete from " + host.Title(),
+		ActionPath: names.DeleteHost,
+		Payload: action.Payload{
+			"action":  names.DeleteHost,
+			"imageID": image.Reference(),
+		},
+		Confirmation: &component.Confirmation{
+			Title: "Delete from " + host.Title() + "?",
+			Body: fmt.Sprintf("Do you want to run %s image rm for %s? All of its tags are removed. "+
+				"Copies already loaded into kind are not affected.", host.Name(), image.Reference()),
+		},
+		Type: component.GridActionDanger,
+	})
 
 	return row
 }
 
-func kindPrinter(image kindImage, repoTag string) component.TableRow {
+func queuePrinter(title string, pending []queuedJob) *component.Table {
+	table := component.NewTable(title, "Nothing queued",
+		component.NewTableCols("Image", "Queued"))
+	for _, job := range pending {
+		table.Add(component.TableRow{
+			"Image":  component.NewText(job.ImageID),
+			"Queued": component.NewTimestamp(job.QueuedAt),
+		})
+	}
+	return table
+}
+
+func copyGridAction(repoTag string) component.GridAction {
+	return component.GridAction{
+		Name:       "Copy to cluster…",
+		ActionPath: names.CopyPrompt,
+		Payload: action.Payload{
+			"action":  names.CopyPrompt,
+			"imageID": repoTag,
+		},
+		Type: component.GridActionPrimary,
+	}
+}
+
+func kindPrinter(image kindImage, repoTag string, spec imageSpec, nodeArch string, consumers []kindContainer) component.TableRow {
 	row := component.TableRow{}
 	row["Image"] = component.NewText(fmt.Sprintf("%s", repoTag))
-	row["Image ID"] = component.NewText(fmt.Sprintf("%s", image.ID))
-	row["Size"] = component.NewText(fmt.Sprintf("%s", image.Size))
+	if image.Pinned {
+		row["Image"] = component.NewTextf("%s (pinned)", repoTag)
+	}
+	row["Image ID"] = imageIDLink(kindImagePath, image.ID)
+	row["Reference"] = component.NewText(image.Reference(repoTag))
+	row["Digest"] = component.NewText(shortDigest(matchRepoDigest(repoTag, image.RepoDigests)))
+	if spec.Created.IsZero() {
+		row["Created"] = component.NewText("")
+	} else {
+		row["Created"] = component.NewTimestamp(spec.Created)
+	}
+	row["Size"] = component.NewText(displaySize(image.Size))
+	arch := component.NewText(spec.Platform())
+	if spec.Architecture != "" && nodeArch != "" && spec.Architecture != nodeArch {
+		arch = component.NewTextf("%s (kind node is %s)", spec.Platform(), nodeArch)
+		arch.SetStatus(component.TextStatusWarning)
+	}
+	row["Architecture"] = arch
+	row["Used by"] = usedByPrinter(consumers)
+	row["Revision"] = revisionCell(spec.Config.Labels)
+
+	// Pinned images can't be deleted, so only offer copying them.
+	if image.Pinned {
+		row.AddAction(copyGridAction(repoTag))
+		return row
+	}
 
+	target := deleteTarget(image, repoTag)
 	confirmation := &component.Confirmation{
 		Title: "Are you sure?",
-		Body:  fmt.Sprintf("Do you want to delete %s from your kind images?", repoTag),
+		Body: fmt.Sprintf("Do you want to delete %s from your kind images? It is the image's only tag, "+
+			"so the image is removed.", repoTag),
 	}
 
-	action := component.GridAction{
+	deleteGridAction := component.GridAction{
 		Name:       "Delete",
-		ActionPath: deleteAction,
+		ActionPath: names.Delete,
 		Payload: action.Payload{
-			"action":  deleteAction,
-			"imageID": image.ID,
+			"action":  names.Delete,
+			"imageID": target,
 		},
 		Confirmation: confirmation,
 		Type:         component.GridActionDanger,
 	}
 
-	row.AddAction(action)
+	// Removing one of several tags leaves the image, so its containers keep running.
+	keepsImage := keepsOtherTags(target, image)
+	switch {
+	case keepsImage:
+		confirmation.Body = fmt.Sprintf("Do you want to delete the tag %s from your kind images? "+
+			"Other tags of this image will be kept: %s.", repoTag, strings.Join(otherTags(image, repoTag), ", "))
+	case len(consumers) > 0:
+		confirmation.Body = fmt.Sprintf("This image is used by %d running container(s): %s. "+
+			"Delete will refuse to remove it; use Force delete to remove it anyway.", len(consumers), describeConsumers(consumers))
+	}
+
+	row.AddAction(deleteGridAction)
+
+	if len(consumers) > 0 && !keepsImage {
+		row.AddAction(component.GridAction{
+			Name:       "Force delete",
+			ActionPath: names.Delete,
+			Payload: action.Payload{
+				"action":  names.Delete,
+				"imageID": target,
+				"force":   true,
+			},
+			Confirmation: &component.Confirmation{
+				Title: "Force delete?",
+				Body: fmt.Sprintf("%s is used by %s. Pods using it will fail to restart if they cannot pull it again. "+
+					"Do you want to delete it anyway?", repoTag, describeConsumers(consumers)),
+			},
+			Type: component.GridActionDanger,
+		})
+	}
+	row.AddAction(copyGridAction(repoTag))
 
 	return row
 }
+
+// usedByPrinter links to the pods using an image, or shows a dash when the
+// image is unused.
+func usedByPrinter(consumers []kindContainer) component.Component {
+	if len(consumers) == 0 {
+		return component.NewText("—")
+	}
+
+	seen := map[string]bool{}
+	var links []component.Component
+	for _, c := range consumers {
+		if seen[c.PodName()] {
+			continue
+		}
+		seen[c.PodName()] = true
+		links = append(links, podLink(c))
+	}
+	if len(links) == 1 {
+		return links[0]
+	}
+	return component.NewList(nil, links)
+}
@@ -0,0 +1,820 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+const (
+	testImageID = "sha256:4f2a8c1e9b7d6a5c3e1f0b9d8c7a6e5f4d3c2b1a0f9e8d7c6b5a4f3e2d1c0b9a"
+	testDigest  = "sha256:0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9"
+)
+
+// cellText returns the text of a row's cell, failing when it isn't text.
+func cellText(t *testing.T, row component.TableRow, column string) string {
+	t.Helper()
+	text, ok := row[column].(*component.Text)
+	if !ok {
+		t.Fatalf("cell %s is a %T, want *component.Text", column, row[column])
+	}
+	return text.String()
+}
+
+// rowActions returns the grid actions of a row by name.
+func rowActions(t *testing.T, row component.TableRow) map[string]component.GridAction {
+	t.Helper()
+	gridActions, ok := row[component.GridActionKey].(*component.GridActions)
+	if !ok {
+		t.Fatalf("row has no grid actions")
+	}
+	actions := map[string]component.GridAction{}
+	for _, gridAction := range gridActions.Config.Actions {
+		actions[gridAction.Name] = gridAction
+	}
+	return actions
+}
+
+// checkAction checks that a row has the named action and that its payload
+// runs want with imageID.
+func checkAction(t *testing.T, actions map[string]component.GridAction, name, want, imageID string) {
+	t.Helper()
+	gridAction, ok := actions[name]
+	if !ok {
+		t.Errorf("no %q action", name)
+		return
+	}
+	if gridAction.ActionPath != want {
+		t.Errorf("%q action path = %q, want %q", name, gridAction.ActionPath, want)
+	}
+	if got, _ := gridAction.Payload.String("action"); got != want {
+		t.Errorf("%q payload action = %q, want %q", name, got, want)
+	}
+	if got, _ := gridAction.Payload.String("imageID"); got != imageID {
+		t.Errorf("%q payload imageID = %q, want %q", name, got, imageID)
+	}
+}
+
+func TestRowPrinter(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     dockerImage
+		tags      []string
+		inspect   dockerInspect
+		cluster   clusterInfo
+		reference string
+		digest    string
+		// absent are actions the row must not offer.
+		absent []string
+	}{
+		{
+			name:      "repo:tag",
+			image:     dockerImage{ID: testImageID, Repository: "nginx", Tag: "1.25", Size: "187MB"},
+			tags:      []string{"nginx:1.25"},
+			inspect:   dockerInspect{RepoDigests: []string{"nginx@" + testDigest}},
+			cluster:   clusterInfo{Registry: &localRegistry{}},
+			reference: "nginx:1.25",
+			digest:    "0a1b2c3d4e5f",
+			absent:    []string{"Load tag…"},
+		},
+		{
+			name:      "digest only",
+			image:     dockerImage{ID: testImageID, Repository: "nginx", Tag: "<none>", Digest: testDigest, Size: "187MB"},
+			inspect:   dockerInspect{RepoDigests: []string{"nginx@" + testDigest}},
+			cluster:   clusterInfo{Registry: &localRegistry{}},
+			reference: testImageID,
+			digest:    "—",
+			absent:    []string{"Push to local registry", "Load tag…"},
+		},
+		{
+			name:      "<none> falls back to the ID",
+			image:     dockerImage{ID: testImageID, Repository: "<none>", Tag: "<none>", Size: "12MB"},
+			reference: testImageID,
+			digest:    "—",
+			absent:    []string{"Push to local registry", "Load tag…"},
+		},
+		{
+			name:      "several tags",
+			image:     dockerImage{ID: testImageID, Repository: "app", Tag: "v2", Size: "12MB"},
+			tags:      []string{"app:v2", "registry.local/app:v2"},
+			reference: "app:v2",
+			digest:    "—",
+			absent:    []string{"Push to local registry"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			row := rowPrinter(test.image, test.tags, test.inspect, test.cluster)
+			if got := cellText(t, row, "Reference"); got != test.reference {
+				t.Errorf("Reference = %q, want %q", got, test.reference)
+			}
+			if got := cellText(t, row, "Digest"); got != test.digest {
+				t.Errorf("Digest = %q, want %q", got, test.digest)
+			}
+
+			actions := rowActions(t, row)
+			checkAction(t, actions, "Load into Kind", names.Load, test.reference)
+			checkAction(t, actions, "Load for platform…", names.LoadPrompt, test.reference)
+			checkAction(t, actions, "Push…", names.PushPrompt, test.reference)
+			checkAction(t, actions, "Save to tar…", names.SavePrompt, test.reference)
+			checkAction(t, actions, "Delete from "+host.Title(), names.DeleteHost, test.reference)
+			if len(test.tags) > 1 {
+				checkAction(t, actions, "Load tag…", names.TagPrompt, test.image.ID)
+			}
+			if test.cluster.Registry != nil && test.reference != test.image.ID {
+				checkAction(t, actions, "Push to local registry", names.Push, test.reference)
+			}
+			for _, name := range test.absent {
+				if _, ok := actions[name]; ok {
+					t.Errorf("unexpected %q action", name)
+				}
+			}
+		})
+	}
+}
+
+func TestKindPrinter(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     kindImage
+		repoTag   string
+		consumers []kindContainer
+		cell      string
+		reference string
+		digest    string
+		// deleteID is the imageID of the Delete action, empty when the row
+		// has none.
+		deleteID string
+		force    bool
+	}{
+		{
+			name:      "repo:tag",
+			image:     kindImage{ID: testImageID, RepoTags: []string{"docker.io/library/nginx:1.25"}},
+			repoTag:   "docker.io/library/nginx:1.25",
+			cell:      "docker.io/library/nginx:1.25",
+			reference: "docker.io/library/nginx:1.25",
+			digest:    "—",
+			deleteID:  "docker.io/library/nginx:1.25",
+		},
+		{
+			name: "repo digest",
+			image: kindImage{ID: testImageID, RepoTags: []string{"docker.io/library/nginx:1.25"},
+				RepoDigests: []string{"docker.io/library/nginx@" + testDigest}},
+			repoTag:   "docker.io/library/nginx:1.25",
+			cell:      "docker.io/library/nginx:1.25",
+			reference: "docker.io/library/nginx@" + testDigest,
+			digest:    "0a1b2c3d4e5f",
+			deleteID:  "docker.io/library/nginx:1.25",
+		},
+		{
+			name:      "untagged falls back to the ID",
+			image:     kindImage{ID: testImageID},
+			repoTag:   testImageID,
+			cell:      testImageID,
+			reference: testImageID,
+			digest:    "—",
+			deleteID:  testImageID,
+		},
+		{
+			name:      "in use",
+			image:     kindImage{ID: testImageID, RepoTags: []string{"app:v1"}},
+			repoTag:   "app:v1",
+			consumers: []kindContainer{{}},
+			cell:      "app:v1",
+			reference: "app:v1",
+			digest:    "—",
+			deleteID:  "app:v1",
+			force:     true,
+		},
+		{
+			name:      "pinned",
+			image:     kindImage{ID: testImageID, RepoTags: []string{"registry.k8s.io/pause:3.9"}, Pinned: true},
+			repoTag:   "registry.k8s.io/pause:3.9",
+			cell:      "registry.k8s.io/pause:3.9 (pinned)",
+			reference: "registry.k8s.io/pause:3.9",
+			digest:    "—",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			row := kindPrinter(test.image, test.repoTag, imageSpec{}, "", test.consumers)
+			if got := cellText(t, row, "Image"); got != test.cell {
+				t.Errorf("Image = %q, want %q", got, test.cell)
+			}
+			if got := cellText(t, row, "Reference"); got != test.reference {
+				t.Errorf("Reference = %q, want %q", got, test.reference)
+			}
+			if got := cellText(t, row, "Digest"); got != test.digest {
+				t.Errorf("Digest = %q, want %q", got, test.digest)
+			}
+
+			actions := rowActions(t, row)
+			checkAction(t, actions, "Copy to cluster…", names.CopyPrompt, test.repoTag)
+			if test.deleteID == "" {
+				if _, ok := actions["Delete"]; ok {
+					t.Errorf("unexpected Delete action")
+				}
+				return
+			}
+			checkAction(t, actions, "Delete", names.Delete, test.deleteID)
+			_, hasForce := actions["Force delete"]
+			if hasForce != test.force {
+				t.Errorf("Force delete offered = %t, want %t", hasForce, test.force)
+			}
+			if test.force {
+				checkAction(t, actions, "Force delete", names.Delete, test.deleteID)
+				if force, _ := actions["Force delete"].Payload.Bool("force"); !force {
+					t.Errorf("Force delete payload does not set force")
+				}
+			}
+		})
+	}
+}
+
+// captureListingWarnings starts listingWarned afresh and collects what is
+// logged until the returned func restores the log output.
+func captureListingWarnings() (*bytes.Buffer, func()) {
+	var logged bytes.Buffer
+	listingWarned = &listingWarnings{}
+	log.SetOutput(&logged)
+	return &logged, func() {
+		log.SetOutput(os.Stderr)
+		listingWarned = &listingWarnings{}
+	}
+}
+
+func TestDockerImageUnmarshalJSONReleases(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want dockerImage
+		// warning is a logged warning the line must cause, empty when it
+		// must not log any.
+		warning string
+	}{
+		{
+			name: "docker 20.10",
+			line: `{"Containers":"N/A","CreatedAt":"2023-06-14 19:45:12 +0000 UTC","CreatedSince":"4 months ago","Digest":"\u003cnone\u003e","ID":"e2ffdd1b2a6a","Repository":"nginx","SharedSize":"N/A","Size":"187MB","Tag":"1.25","UniqueSize":"N/A","VirtualSize":"187.3MB"}`,
+			want: dockerImage{CreatedAt: "2023-06-14 19:45:12 +0000 UTC", CreatedSince: "4 months ago", Digest: "<none>",
+				ID: "e2ffdd1b2a6a", Repository: "nginx", Size: "187MB", Tag: "1.25", VirtualSize: "187.3MB"},
+		},
+		{
+			name: "docker 24.0",
+			line: `{"Containers":"N/A","CreatedAt":"2023-10-11 18:01:03 +0200 CEST","CreatedSince":"2 days ago","Digest":"\u003cnone\u003e","ID":"61395b4c586d","Repository":"nginx","SharedSize":"N/A","Size":"187MB","Tag":"latest","UniqueSize":"N/A","VirtualSize":"186.6MB"}`,
+			want: dockerImage{CreatedAt: "2023-10-11 18:01:03 +0200 CEST", CreatedSince: "2 days ago", Digest: "<none>",
+				ID: "61395b4c586d", Repository: "nginx", Size: "187MB", Tag: "latest", VirtualSize: "186.6MB"},
+		},
+		{
+			name: "docker 27.3",
+			line: `{"Containers":"N/A","CreatedAt":"2024-08-14 21:31:12 +0000 UTC","CreatedSince":"2 months ago","Digest":"\u003cnone\u003e","ID":"5ef79149e0ec","Repository":"nginx","SharedSize":"N/A","Size":"188MB","Tag":"1.27","UniqueSize":"N/A"}`,
+			want: dockerImage{CreatedAt: "2024-08-14 21:31:12 +0000 UTC", CreatedSince: "2 months ago", Digest: "<none>",
+				ID: "5ef79149e0ec", Repository: "nginx", Size: "188MB", Tag: "1.27"},
+		},
+		{
+			name:    "field no checked release prints",
+			line:    `{"Containers":"N/A","CreatedAt":"2024-08-14 21:31:12 +0000 UTC","ID":"5ef79149e0ec","Repository":"nginx","Size":"188MB","Tag":"1.27","Annotations":"N/A"}`,
+			want:    dockerImage{CreatedAt: "2024-08-14 21:31:12 +0000 UTC", ID: "5ef79149e0ec", Repository: "nginx", Size: "188MB", Tag: "1.27"},
+			warning: `image listing has an unknown field "Annotations", ignoring it`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logged, restore := captureListingWarnings()
+			defer restore()
+
+			var image dockerImage
+			if err := json.Unmarshal([]byte(test.line), &image); err != nil {
+				t.Fatalf("Unmarshal: %s", err)
+			}
+			if image.ID != test.want.ID {
+				t.Errorf("ID = %q, want %q", image.ID, test.want.ID)
+			}
+			if image.CreatedAt != test.want.CreatedAt {
+				t.Errorf("CreatedAt = %q, want %q", image.CreatedAt, test.want.CreatedAt)
+			}
+			if image.Size != test.want.Size {
+				t.Errorf("Size = %q, want %q", image.Size, test.want.Size)
+			}
+			if image.VirtualSize != test.want.VirtualSize {
+				t.Errorf("VirtualSize = %q, want %q", image.VirtualSize, test.want.VirtualSize)
+			}
+			if image != test.want {
+				t.Errorf("image = %+v, want %+v", image, test.want)
+			}
+
+			if test.warning == "" {
+				if logged.Len() > 0 {
+					t.Errorf("unexpected warning: %s", logged)
+				}
+			} else if !strings.Contains(logged.String(), test.warning) {
+				t.Errorf("logged %q, want the warning %q", logged, test.warning)
+			}
+		})
+	}
+}
+
+func TestParseDockerImagesSkipsCorruptLines(t *testing.T) {
+	output := strings.Join([]string{
+		`{"ID":"e2ffdd1b2a6a","Repository":"nginx","Tag":"1.25","Size":"187MB"}`,
+		`{"ID":"7f553e8bbc89","Repository":"redis"`,
+		``,
+		`{"ID":"5ef79149e0ec","Repository":"nginx","Tag":"1.27","Size":"188MB"}`,
+		`WARNING: the image store is being migrated`,
+		`{"ID":"a3ed95caeb02","Repository":"busybox","Tag":"1.36","Size":"4.26MB"}`,
+		`}{`,
+	}, "\n")
+
+	images, err := parseDockerImages(strings.NewReader(output))
+	var ids []string
+	for _, image := range images {
+		ids = append(ids, image.ID)
+	}
+	if want := []string{"e2ffdd1b2a6a", "5ef79149e0ec", "a3ed95caeb02"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("parsed images %v, want %v", ids, want)
+	}
+
+	var partial *parseError
+	if !errors.As(err, &partial) {
+		t.Fatalf("err = %v, want a *parseError", err)
+	}
+	if partial.Failed != 3 || partial.Total != 6 {
+		t.Errorf("Failed/Total = %d/%d, want 3/6", partial.Failed, partial.Total)
+	}
+}
+
+func TestParseCrictlImagesWithoutImages(t *testing.T) {
+	for _, output := range []string{"", "{}", `{"images":null}`, `{"images":[]}`} {
+		images, err := parseCrictlImages(strings.NewReader(output))
+		if err != nil {
+			t.Errorf("parseCrictlImages(%q): %s", output, err)
+		}
+		if len(images.Images) != 0 {
+			t.Errorf("parseCrictlImages(%q) = %v, want no images", output, images.Images)
+		}
+	}
+}
+
+// Outputs of crictl images --output=json captured from kind nodes.
+const (
+	// crictl v1.25.0, kindest/node:v1.25.3.
+	crictlOutput125 = `{
+  "images": [
+    {
+      "id": "sha256:221177c6082a88ea4f6240ab2450d540955ac6f4d5454f0e15751b653ebda165",
+      "repoTags": [
+        "registry.k8s.io/pause:3.7"
+      ],
+      "repoDigests": [
+        "registry.k8s.io/pause@sha256:bb6ed397957e9ca7c65ada0db5c5d1c707c9c8afc80a94acbe69f3ae76988f0c"
+      ],
+      "size": "311278",
+      "uid": {
+        "value": "65535"
+      },
+      "username": "",
+      "spec": null
+    }
+  ]
+}
+`
+	// crictl v1.28.0, kindest/node:v1.28.0.
+	crictlOutput128 = `{
+  "images": [
+    {
+      "id": "sha256:e6f1816883972d4be47bd48879a08919b96afcd344132622e4d444987919323c",
+      "repoTags": [
+        "registry.k8s.io/pause:3.9"
+      ],
+      "repoDigests": [
+        "registry.k8s.io/pause@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097"
+      ],
+      "size": "321520",
+      "uid": {
+        "value": "65535"
+      },
+      "username": "",
+      "spec": null,
+      "pinned": true
+    },
+    {
+      "id": "sha256:b0b1fa0f58c6e932b7f20bf208b2841317a1e8c88cc51b18358310bbd8ec95da",
+      "repoTags": [
+        "docker.io/kindest/kindnetd:v20230809-80a64d96"
+      ],
+      "repoDigests": [],
+      "size": "27731571",
+      "uid": null,
+      "username": "",
+      "spec": null,
+      "pinned": false
+    }
+  ]
+}
+`
+	// crictl v1.30.0, kindest/node:v1.30.0. The second entry is from a
+	// newer release's format this one can't decode, and is skipped.
+	crictlOutput130 = `{
+  "images": [
+    {
+      "id": "sha256:ac1c61439df4625ba53a9ceaccb5eb07a830bdf942cc1c60535a4dd7e763d55f",
+      "repoTags": [
+        "docker.io/library/app:v1.2"
+      ],
+      "repoDigests": [],
+      "size": "7420000",
+      "uid": null,
+      "username": "nonroot",
+      "spec": null,
+      "pinned": false
+    },
+    {
+      "id": "sha256:3861cfcd7c04ccac1f062788eca39487248527ef0c0cfd477a83d7691a75a899",
+      "repoTags": "registry.k8s.io/etcd:3.5.12-0",
+      "size": "57236178"
+    }
+  ]
+}
+`
+)
+
+func TestParseCrictlImagesReleases(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []kindImage
+	}{
+		{
+			name:   "crictl v1.25",
+			output: crictlOutput125,
+			want: []kindImage{{
+				ID:          "sha256:221177c6082a88ea4f6240ab2450d540955ac6f4d5454f0e15751b653ebda165",
+				UID:         "65535",
+				RepoTags:    []string{"registry.k8s.io/pause:3.7"},
+				RepoDigests: []string{"registry.k8s.io/pause@sha256:bb6ed397957e9ca7c65ada0db5c5d1c707c9c8afc80a94acbe69f3ae76988f0c"},
+				Size:        "311278",
+			}},
+		},
+		{
+			name:   "crictl v1.28",
+			output: crictlOutput128,
+			want: []kindImage{
+				{
+					ID:          "sha256:e6f1816883972d4be47bd48879a08919b96afcd344132622e4d444987919323c",
+					UID:         "65535",
+					RepoTags:    []string{"registry.k8s.io/pause:3.9"},
+					RepoDigests: []string{"registry.k8s.io/pause@sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097"},
+					Size:        "321520",
+					Pinned:      true,
+				},
+				{
+					ID:          "sha256:b0b1fa0f58c6e932b7f20bf208b2841317a1e8c88cc51b18358310bbd8ec95da",
+					RepoTags:    []string{"docker.io/kindest/kindnetd:v20230809-80a64d96"},
+					RepoDigests: []string{},
+					Size:        "27731571",
+				},
+			},
+		},
+		{
+			name:   "crictl v1.30 with an entry that can't be decoded",
+			output: crictlOutput130,
+			want: []kindImage{{
+				ID:          "sha256:ac1c61439df4625ba53a9ceaccb5eb07a830bdf942cc1c60535a4dd7e763d55f",
+				RepoTags:    []string{"docker.io/library/app:v1.2"},
+				RepoDigests: []string{},
+				Size:        "7420000",
+				Username:    "nonroot",
+			}},
+		},
+		{
+			name:   "numeric size",
+			output: `{"images":[{"id":"sha256:221177c6082a88ea4f6240ab2450d540955ac6f4d5454f0e15751b653ebda165","repoTags":["registry.k8s.io/pause:3.7"],"size":311278}]}`,
+			want: []kindImage{{
+				ID:       "sha256:221177c6082a88ea4f6240ab2450d540955ac6f4d5454f0e15751b653ebda165",
+				RepoTags: []string{"registry.k8s.io/pause:3.7"},
+				Size:     "311278",
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			images, err := parseCrictlImages(strings.NewReader(test.output))
+			if err != nil {
+				t.Fatalf("parseCrictlImages: %s", err)
+			}
+			if !reflect.DeepEqual(images.Images, test.want) {
+				t.Errorf("images = %+v, want %+v", images.Images, test.want)
+			}
+		})
+	}
+}
+
+func TestKindImageUnmarshalJSONSize(t *testing.T) {
+	tests := []struct {
+		entry string
+		size  string
+		fails bool
+	}{
+		{entry: `{"size":"311278"}`, size: "311278"},
+		{entry: `{"size":311278}`, size: "311278"},
+		{entry: `{"size":null}`},
+		{entry: `{}`},
+		{entry: `{"size":"311 kB"}`, fails: true},
+		{entry: `{"size":{"value":311278}}`, fails: true},
+	}
+	for _, test := range tests {
+		var image kindImage
+		err := json.Unmarshal([]byte(test.entry), &image)
+		if test.fails {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %+v, want an error", test.entry, image)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s): %s", test.entry, err)
+		} else if image.Size != test.size {
+			t.Errorf("Unmarshal(%s) size = %q, want %q", test.entry, image.Size, test.size)
+		}
+	}
+}
+
+func TestParseCrictlImagesPinned(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		pinned []bool
+	}{
+		// crictl v1.25 predates the field.
+		{name: "without pinned", output: crictlOutput125, pinned: []bool{false}},
+		{name: "with pinned", output: crictlOutput128, pinned: []bool{true, false}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			images, err := parseCrictlImages(strings.NewReader(test.output))
+			if err != nil {
+				t.Fatalf("parseCrictlImages: %s", err)
+			}
+			var pinned []bool
+			for _, image := range images.Images {
+				pinned = append(pinned, image.Pinned)
+			}
+			if !reflect.DeepEqual(pinned, test.pinned) {
+				t.Errorf("pinned = %v, want %v", pinned, test.pinned)
+			}
+		})
+	}
+}
+
+// openFixture opens a file under testdata.
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestParseDockerImagesFixtures(t *testing.T) {
+	tests := []struct {
+		file string
+		// images are the repository:tag of the parsed images.
+		images []string
+		failed int
+	}{
+		{file: "empty.jsonl"},
+		{file: "none.jsonl", images: []string{"nginx:1.27", "<none>:<none>"}},
+		{file: "unicode.jsonl", images: []string{"registry.local/café:v1-日本", "registry.local/café:latest"}},
+		{file: "malformed.jsonl", images: []string{"nginx:1.27", "busybox:1.36"}, failed: 1},
+		{file: "no-newline.jsonl", images: []string{"nginx:1.27", "busybox:1.36"}},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			f := openFixture(t, filepath.Join("docker", test.file))
+			defer f.Close()
+
+			images, err := parseDockerImages(f)
+			var got []string
+			for _, image := range images {
+				got = append(got, image.Repository+":"+image.Tag)
+			}
+			if !reflect.DeepEqual(got, test.images) {
+				t.Errorf("images = %q, want %q", got, test.images)
+			}
+
+			if test.failed == 0 {
+				if err != nil {
+					t.Errorf("parseDockerImages: %s", err)
+				}
+				return
+			}
+			var partial *parseError
+			if !errors.As(err, &partial) {
+				t.Fatalf("err = %v, want a *parseError", err)
+			}
+			if partial.Failed != test.failed || partial.Total != len(test.images)+test.failed {
+				t.Errorf("Failed/Total = %d/%d, want %d/%d", partial.Failed, partial.Total, test.failed, len(test.images)+test.failed)
+			}
+		})
+	}
+}
+
+func TestParseCrictlImagesFixtures(t *testing.T) {
+	tests := []struct {
+		file string
+		// tags are the repo tags of each parsed image.
+		tags  [][]string
+		fails bool
+	}{
+		{file: "empty.json"},
+		{file: "none.json", tags: [][]string{{}}},
+		{file: "unicode.json", tags: [][]string{{"registry.local/café:v1-日本", "registry.local/café:latest"}}},
+		{file: "malformed.json", fails: true},
+		{file: "no-newline.json", tags: [][]string{{"docker.io/library/nginx:1.27"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			f := openFixture(t, filepath.Join("crictl", test.file))
+			defer f.Close()
+
+			images, err := parseCrictlImages(f)
+			if test.fails {
+				if err == nil {
+					t.Errorf("parseCrictlImages = %+v, want an error", images.Images)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCrictlImages: %s", err)
+			}
+			var tags [][]string
+			for _, image := range images.Images {
+				tags = append(tags, image.RepoTags)
+			}
+			if !reflect.DeepEqual(tags, test.tags) {
+				t.Errorf("repo tags = %q, want %q", tags, test.tags)
+			}
+		})
+	}
+}
+
+// fakeRuntime makes the host runtime a script that runs script as its body,
+// returning the func that removes it and restores the runtime.
+func fakeRuntime(t *testing.T, script string) func() {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake runtime is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "fake-runtime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "docker")
+	if err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	saved := host
+	host = dockerRuntime{name: path}
+	return func() {
+		host = saved
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRunActionImagePayloads(t *testing.T) {
+	// docker image inspect finds nginx:1.25 and nothing else, as if every
+	// other image was pruned.
+	defer fakeRuntime(t, `[ "$4" = nginx:1.25 ] && echo `+testImageID+` && exit 0
+echo "Error: No such image: $4" >&2
+exit 1
+`)()
+
+	tests := []struct {
+		name    string
+		action  string
+		payload action.Payload
+		// queued is the imageID the action queues, empty when it must fail.
+		queued string
+		err    string
+	}{
+		{name: "load without imageID", action: names.Load, payload: action.Payload{}, err: "imageID"},
+		{name: "load empty", action: names.Load, payload: action.Payload{"imageID": ""}, err: "no image given"},
+		{name: "load whitespace", action: names.Load, payload: action.Payload{"imageID": " \t\n"}, err: "no image given"},
+		{name: "load malformed", action: names.Load, payload: action.Payload{"imageID": "nginx:1.25;rm -rf ~"}, err: "not a valid image reference"},
+		{name: "load pruned", action: names.Load, payload: action.Payload{"imageID": "redis:7"}, err: "did it get pruned?"},
+		{name: "load padded", action: names.Load, payload: action.Payload{"imageID": "  nginx:1.25\n"}, queued: "nginx:1.25"},
+		{name: "push empty", action: names.Push, payload: action.Payload{"imageID": ""}, err: "no image given"},
+		{name: "push whitespace", action: names.Push, payload: action.Payload{"imageID": "  "}, err: "no image given"},
+		{name: "push malformed", action: names.Push, payload: action.Payload{"imageID": "NGINX:latest"}, err: "not a valid image reference"},
+		{name: "push padded", action: names.Push, payload: action.Payload{"imageID": " nginx:1.25 "}, queued: "nginx:1.25"},
+		{name: "delete empty", action: names.Delete, payload: action.Payload{"imageID": ""}, err: "no image given"},
+		{name: "delete whitespace", action: names.Delete, payload: action.Payload{"imageID": "\n"}, err: "no image given"},
+		{name: "delete malformed", action: names.Delete, payload: action.Payload{"imageID": "$(reboot)"}, err: "not a valid image reference"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &imagePlugin{
+				queue:      newJobQueue("load", queueSize),
+				pushes:     newJobQueue("push", queueSize),
+				registries: &registryDetector{last: &localRegistry{Host: "localhost:5001"}},
+				buildx:     &buildxCache{},
+				loadPrompt: &imagePrompt{},
+				tagPrompt:  &imagePrompt{},
+				stats:      &sessionStats{},
+			}
+			err := p.runAction(&service.ActionRequest{ActionName: test.action, Payload: test.payload})
+
+			_, loads := p.queue.Snapshot()
+			_, pushes := p.pushes.Snapshot()
+			queued := append(loads, pushes...)
+			if test.queued == "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("runAction error = %v, want one containing %q", err, test.err)
+				}
+				if len(queued) > 0 {
+					t.Errorf("queued %+v after a failed action", queued)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runAction: %s", err)
+			}
+			if len(queued) != 1 || queued[0].ImageID != test.queued {
+				t.Errorf("queued %+v, want %s", queued, test.queued)
+			}
+		})
+	}
+}
+
+func TestDockerImageUnmarshalJSONLenient(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want dockerImage
+	}{
+		{
+			name: "null fields",
+			line: `{"ID":"5ef79149e0ec","Repository":"nginx","Tag":null,"Digest":null,"Size":null,"CreatedAt":null}`,
+			want: dockerImage{ID: "5ef79149e0ec", Repository: "nginx"},
+		},
+		{
+			name: "missing fields",
+			line: `{"ID":"5ef79149e0ec"}`,
+			want: dockerImage{ID: "5ef79149e0ec"},
+		},
+		{
+			name: "numeric size",
+			line: `{"ID":"5ef79149e0ec","Repository":"nginx","Tag":"1.27","Size":187654321,"Containers":2}`,
+			want: dockerImage{ID: "5ef79149e0ec", Repository: "nginx", Tag: "1.27", Size: "187654321", Containers: "2"},
+		},
+		{
+			name: "VirtualSize only",
+			line: `{"ID":"5ef79149e0ec","Repository":"nginx","Tag":"1.27","VirtualSize":"187.3MB"}`,
+			want: dockerImage{ID: "5ef79149e0ec", Repository: "nginx", Tag: "1.27", Size: "187.3MB", VirtualSize: "187.3MB"},
+		},
+		{
+			name: "N/A counts",
+			line: `{"ID":"5ef79149e0ec","Containers":"N/A","SharedSize":"N/A","UniqueSize":"N/A","Size":"188MB"}`,
+			want: dockerImage{ID: "5ef79149e0ec", Size: "188MB"},
+		},
+		{
+			name: "aliases",
+			line: `{"Id":"sha256:5ef79149e0ec","Repository":"nginx","Created":1723671072}`,
+			want: dockerImage{ID: "sha256:5ef79149e0ec", Repository: "nginx",
+				CreatedAt: time.Unix(1723671072, 0).Format(dockerTimeLayout)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, restore := captureListingWarnings()
+			defer restore()
+
+			var image dockerImage
+			if err := json.Unmarshal([]byte(test.line), &image); err != nil {
+				t.Fatalf("Unmarshal: %s", err)
+			}
+			if image != test.want {
+				t.Errorf("image = %+v, want %+v", image, test.want)
+			}
+		})
+	}
+
+	var image dockerImage
+	if err := json.Unmarshal([]byte(`{"ID":`), &image); err == nil {
+		t.Errorf("Unmarshal of malformed JSON = %+v, want an error", image)
+	}
+}
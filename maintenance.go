@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// The kind maintenance jobs, by the Target of their queuedJob.
+const (
+	pruneJob       = "prune"
+	refreshJob     = "refresh"
+	moveStrayJob   = "move"
+	deleteStrayJob = "delete"
+	staleKindJob   = "prune stale"
+	staleHostJob   = "prune stale host"
+)
+
+// runMaintenance runs a job of the maintenance queue. Pruning, refreshing
+// and moving images between namespaces run commands on every node, which
+// can take longer than Octant waits for an action, so the actions only
+// queue them.
+func (i *imagePlugin) runMaintenance(ctx context.Context, job queuedJob) error {
+	switch job.Target {
+	case pruneJob:
+		return i.deleteUnusedImages(ctx, time.Time{})
+	case staleKindJob:
+		return i.deleteUnusedImages(ctx, staleBefore())
+	case staleHostJob:
+		return i.deleteStaleHostImages(ctx)
+	case refreshJob:
+		i.maintProgress.Start("Refreshing kind images")
+		err := i.refreshKind()
+		i.maintProgress.Finish(err, fmt.Sprintf("Refreshed kind images in %s", i.maintProgress.Elapsed()))
+		return err
+	case moveStrayJob:
+		return i.moveStrayImage(ctx, job.Stray.Node, job.Stray.Namespace, job.Stray.Ref)
+	case deleteStrayJob:
+		return i.deleteStrayImage(ctx, job.Stray.Node, job.Stray.Namespace, job.Stray.Ref)
+	case bulkDeleteJob:
+		return i.deleteSelected(ctx, job.Batch)
+	}
+	return fmt.Errorf("unknown maintenance job %q", job.Target)
+}
+
+// queueMaintenance queues a maintenance job. The queue refuses a job that is
+// already running or queued.
+func (i *imagePlugin) queueMaintenance(job queuedJob) error {
+	if err := i.maintenance.Enqueue(job); err != nil {
+		return err
+	}
+	log.Printf("queued maintenance job: %s", job.ImageID)
+	return nil
+}
+
+// queueStray checks a stray image action and queues it.
+func (i *imagePlugin) queueStray(target, node, namespace, ref string) error {
+	if err := validateStray(node, namespace, ref); err != nil {
+		return err
+	}
+	description := fmt.Sprintf("Deleting %s on %s from namespace %s", ref, node, namespace)
+	if target == moveStrayJob {
+		description = fmt.Sprintf("Moving %s on %s from namespace %s to %s", ref, node, namespace, containerdNamespace)
+	}
+	return i.queueMaintenance(queuedJob{
+		ImageID: description,
+		Target:  target,
+		Stray:   &strayImage{Node: node, Namespace: namespace, Ref: ref},
+	})
+}
+
+// addMaintenanceSection shows the running maintenance job and the queued
+// ones, or the outcome of the last.
+func (i *imagePlugin) addMaintenanceSection(layout *flexlayout.FlexLayout) {
+	current, pending := i.maintenance.Snapshot()
+	if current == nil {
+		addStatusSection(layout, i.maintProgress)
+		return
+	}
+	section := layout.AddSection()
+	section.Add(component.NewTextf("%s (%s elapsed, %d queued)...",
+		current.ImageID, time.Since(current.StartedAt).Round(time.Second), len(pending)), component.WidthFull)
+	if output := i.maintProgress.Output(); len(output) > 0 {
+		section.Add(component.NewCodeBlock(strings.Join(output, "\n")), component.WidthFull)
+	}
+	if len(pending) > 0 {
+		section.Add(queuePrinter("Queued Maintenance", pending), component.WidthFull)
+	}
+}
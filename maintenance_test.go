@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+)
+
+func TestRunActionOnlyQueuesMaintenance(t *testing.T) {
+	p := &imagePlugin{maintenance: newJobQueue("maintenance", queueSize)}
+	started := make(chan queuedJob, 1)
+	release := make(chan struct{})
+	defer close(release)
+	go p.maintenance.Run(func(ctx context.Context, job queuedJob) error {
+		started <- job
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+
+	returned := make(chan error, 1)
+	go func() {
+		returned <- p.runAction(&service.ActionRequest{ActionName: names.PruneKind})
+	}()
+	select {
+	case err := <-returned:
+		if err != nil {
+			t.Fatalf("runAction: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("runAction waited for the prune to finish")
+	}
+
+	select {
+	case job := <-started:
+		if job.Target != pruneJob {
+			t.Errorf("started job %q, want %q", job.Target, pruneJob)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the prune never started")
+	}
+	current, _ := p.maintenance.Snapshot()
+	if current == nil || current.Target != pruneJob {
+		t.Errorf("running job = %+v, want the prune", current)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+// pluginDomain namespaces the plugin and action names so forks and internal
+// builds don't collide with other plugins. Override it at build time with
+// -ldflags "-X main.pluginDomain=example.com" or at runtime with KIND_REGISTRY_DOMAIN.
+var pluginDomain = "waynewitzel.com"
+
+var names = newPluginNames(envOrDefault("KIND_REGISTRY_DOMAIN", pluginDomain))
+
+// pluginNames holds the plugin name and every action name the plugin handles.
+type pluginNames struct {
+	Plugin       string
+	Load         string
+	Delete       string
+	Cancel       string
+	Page         string
+	Push         string
+	PushPrompt   string
+	PushTo       string
+	LoadArchive  string
+	SavePrompt   string
+	Save         string
+	CopyPrompt   string
+	Copy         string
+	Pull         string
+	ToggleSystem string
+	DeleteHost   string
+	LabelFilter  string
+	NewCluster   string
+	StartCluster string
+	Refresh      string
+	LoadPrompt   string
+	Get          string
+	ForceDelete  string
+	Scan         string
+	PruneKind    string
+	Commands     string
+	DeleteTag    string
+	RegistryGC   string
+	Sort         string
+	MoveStray    string
+	DeleteStray  string
+	AutoSync     string
+	Teardown     string
+	TagPrompt    string
+	RefreshNow   string
+	BuildxLoad   string
+	StaleOnly    string
+	PruneStale   string
+	Project      string
+	LoadProject  string
+	Select       string
+	BulkDelete   string
+	Sync         string
+}
+
+func newPluginNames(domain string) pluginNames {
+	return pluginNames{
+		Plugin:       domain + "/kind-images",
+		Load:         domain + "/kind-load-image",
+		Delete:       domain + "/kind-delete-image",
+		Cancel:       domain + "/kind-cancel-load",
+		Page:         domain + "/kind-page",
+		Push:         domain + "/kind-push-image",
+		PushPrompt:   domain + "/kind-push-prompt",
+		PushTo:       domain + "/kind-push-to",
+		LoadArchive:  domain + "/kind-load-archive",
+		SavePrompt:   domain + "/kind-save-prompt",
+		Save:         domain + "/kind-save-image",
+		CopyPrompt:   domain + "/kind-copy-prompt",
+		Copy:         domain + "/kind-copy-image",
+		Pull:         domain + "/kind-pull-image",
+		ToggleSystem: domain + "/kind-toggle-system-images",
+		DeleteHost:   domain + "/kind-delete-host-image",
+		LabelFilter:  domain + "/kind-label-filter",
+		NewCluster:   domain + "/kind-create-cluster",
+		StartCluster: domain + "/kind-start-cluster",
+		Refresh:      domain + "/kind-refresh",
+		LoadPrompt:   domain + "/kind-load-prompt",
+		Get:          domain + "/kind-get-image",
+		ForceDelete:  domain + "/kind-force-delete-image",
+		Scan:         domain + "/kind-scan-image",
+		PruneKind:    domain + "/kind-delete-unused-images",
+		Commands:     domain + "/kind-toggle-commands",
+		DeleteTag:    domain + "/kind-registry-delete-manifest",
+		RegistryGC:   domain + "/kind-registry-garbage-collect",
+		Sort:         domain + "/kind-sort",
+		MoveStray:    domain + "/kind-move-namespace-image",
+		DeleteStray:  domain + "/kind-delete-namespace-image",
+		AutoSync:     domain + "/kind-toggle-auto-sync",
+		Teardown:     domain + "/kind-delete-cluster",
+		TagPrompt:    domain + "/kind-load-tag-prompt",
+		RefreshNow:   domain + "/kind-refresh-now",
+		BuildxLoad:   domain + "/kind-buildx-load",
+		StaleOnly:    domain + "/kind-toggle-stale",
+		PruneStale:   domain + "/kind-delete-stale",
+		Project:      domain + "/kind-project-filter",
+		LoadProject:  domain + "/kind-load-project",
+		Select:       domain + "/kind-select-images",
+		BulkDelete:   domain + "/kind-delete-selected-images",
+		Sync:         domain + "/kind-sync-images",
+	}
+}
+
+// Actions returns the action names to register with Octant.
+func (n pluginNames) Actions() []string {
+	return []string{n.Delete, n.Load, n.Cancel, n.Page, n.Push, n.PushPrompt, n.PushTo, n.LoadArchive, n.SavePrompt, n.Save, n.CopyPrompt, n.Copy, n.Pull, n.ToggleSystem, n.DeleteHost, n.LabelFilter, n.NewCluster, n.StartCluster, n.Refresh, n.LoadPrompt, n.Get, n.ForceDelete, n.Scan, n.PruneKind, n.Commands, n.DeleteTag, n.RegistryGC, n.Sort, n.MoveStray, n.DeleteStray, n.AutoSync, n.Teardown, n.TagPrompt, n.RefreshNow, n.BuildxLoad, n.StaleOnly, n.PruneStale, n.Project, n.LoadProject, n.Select, n.BulkDelete, n.Sync}
+}
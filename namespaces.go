@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// namespacePattern matches containerd namespace names.
+var namespacePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// strayImage is an image in a containerd namespace other than the one the
+// kubelet uses, typically left by ctr images import without -n k8s.io. It
+// takes disk space but pods can't use it.
+type strayImage struct {
+	Node      string
+	Namespace string
+	Ref       string
+	Size      string
+	// Visible is set when the same reference is also in containerdNamespace.
+	Visible bool
+}
+
+// listContainerdNamespaces lists the containerd namespaces on a node.
+func listContainerdNamespaces(node string) ([]string, error) {
+	// docker exec {{node}} ctr namespaces ls -q
+	cmd := nodeCommand(node, "ctr", "namespaces", "ls", "-q")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, commandError("ctr namespaces ls", err, stderr.String())
+	}
+
+	var namespaces []string
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		if namespace := strings.TrimSpace(scanner.Text()); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces, nil
+}
+
+// listStrayImages lists the tagged images of every other namespace on a node.
+func listStrayImages(node string) ([]strayImage, error) {
+	namespaces, err := listContainerdNamespaces(node)
+	if err != nil {
+		return nil, err
+	}
+	var others []string
+	for _, namespace := range namespaces {
+		if namespace != containerdNamespace {
+			others = append(others, namespace)
+		}
+	}
+	// Usually the kubelet's namespace is the only one.
+	if len(others) == 0 {
+		return nil, nil
+	}
+	visible, err := listNamespaceImages(node, containerdNamespace)
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]bool{}
+	for _, image := range visible.Images {
+		for _, repoTag := range image.RepoTags {
+			tags[repoTag] = true
+		}
+	}
+
+	var strays []strayImage
+	for _, namespace := range others {
+		images, err := listNamespaceImages(node, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range images.Images {
+			for _, repoTag := range image.RepoTags {
+				strays = append(strays, strayImage{
+					Node:      node,
+					Namespace: namespace,
+					Ref:       repoTag,
+					Size:      image.Size,
+					Visible:   tags[repoTag],
+				})
+			}
+		}
+	}
+	return strays, nil
+}
+
+// strayImageCache keeps the stray images of every node for healthTTL, since
+// listing them takes a ctr call per namespace.
+type strayImageCache struct {
+	mu      sync.Mutex
+	checked time.Time
+	strays  []strayImage
+	err     error
+}
+
+func (c *strayImageCache) Get() ([]strayImage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked) <= healthTTL {
+		return c.strays, c.err
+	}
+
+	nodes, err := listKindNodes()
+	if err != nil {
+		nodes = []string{kindNode}
+	}
+	c.strays, c.err = nil, nil
+	for _, node := range nodes {
+		strays, err := listStrayImages(node)
+		if err != nil {
+			c.err = fmt.Errorf("%s: %w", node, err)
+			continue
+		}
+		c.strays = append(c.strays, strays...)
+	}
+	c.checked = time.Now()
+	return c.strays, c.err
+}
+
+// Reset makes the next Get list the nodes again.
+func (c *strayImageCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checked = time.Time{}
+}
+
+// validateStray checks the node, namespace and reference of a stray image
+// action before they are passed to a command.
+func validateStray(node, namespace, ref string) error {
+	if !contains(knownNodes(), node) {
+		return fmt.Errorf("%q is not a node of cluster %s", node, kindCluster)
+	}
+	if !namespacePattern.MatchString(namespace) || namespace == containerdNamespace {
+		return fmt.Errorf("%q is not a namespace other than %s", namespace, containerdNamespace)
+	}
+	return validateReference(ref)
+}
+
+// knownNodes returns the nodes of the cluster, or just kindNode when they
+// can't be listed.
+func knownNodes() []string {
+	nodes, err := listKindNodes()
+	if err != nil || len(nodes) == 0 {
+		return []string{kindNode}
+	}
+	return nodes
+}
+
+// moveStrayImage re-imports a stray image into containerdNamespace, where
+// the kubelet sees it, then removes it from its namespace.
+func (i *imagePlugin) moveStrayImage(ctx context.Context, node, namespace, ref string) (err error) {
+	if err := validateStray(node, namespace, ref); err != nil {
+		return err
+	}
+	i.maintProgress.Start(fmt.Sprintf("Moving %s on %s from namespace %s to %s", ref, node, namespace, containerdNamespace))
+	defer func() {
+		i.strays.Reset()
+		i.nodeImages.Reset()
+		i.maintProgress.Finish(err, fmt.Sprintf("Moved %s on %s to namespace %s", ref, node, containerdNamespace))
+	}()
+
+	// The export is piped inside the node. The values are passed as
+	// arguments rather than pasted into the script.
+	// docker exec {{node}} sh -c 'ctr -n "$1" images export - "$2" | ctr -n "$3" images import --digests -'
+	script := `ctr -n "$1" images export - "$2" | ctr -n "$3" images import --digests -`
+	move := nodeCommand(node, "sh", "-c", script, "sh", namespace, ref, containerdNamespace)
+	// docker exec {{node}} ctr -n {{namespace}} images rm {{ref}}
+	remove := nodeCommand(node, "ctr", "-n", namespace, "images", "rm", ref)
+	if dryRun {
+		i.maintProgress.Write(fmt.Sprintf("Dry run: would have run %s and %s", commandLine(move), commandLine(remove)))
+		return nil
+	}
+
+	var stderr bytes.Buffer
+	move.Stderr = &stderr
+	if err := runContext(ctx, move); err != nil {
+		return fmt.Errorf("moveStrayImage %s: %w: %s", ref, err, strings.TrimSpace(stderr.String()))
+	}
+	// sh reports only the import's status, so check the image arrived before
+	// removing the original.
+	images, err := listNamespaceImages(node, containerdNamespace)
+	if err != nil {
+		return fmt.Errorf("moveStrayImage %s: %w", ref, err)
+	}
+	if !hasRef(images, ref) {
+		return fmt.Errorf("moveStrayImage %s: the image is missing from namespace %s after the import: %s",
+			ref, containerdNamespace, strings.TrimSpace(stderr.String()))
+	}
+	return removeNamespaceImage(ctx, remove, ref)
+}
+
+// deleteStrayImage removes a stray image from its namespace.
+func (i *imagePlugin) deleteStrayImage(ctx context.Context, node, namespace, ref string) (err error) {
+	if err := validateStray(node, namespace, ref); err != nil {
+		return err
+	}
+	i.maintProgress.Start(fmt.Sprintf("Deleting %s on %s from namespace %s", ref, node, namespace))
+	defer func() {
+		i.strays.Reset()
+		i.maintProgress.Finish(err, fmt.Sprintf("Deleted %s on %s from namespace %s", ref, node, namespace))
+	}()
+
+	// docker exec {{node}} ctr -n {{namespace}} images rm {{ref}}
+	remove := nodeCommand(node, "ctr", "-n", namespace, "images", "rm", ref)
+	if dryRun {
+		i.maintProgress.Write(fmt.Sprintf("Dry run: would have run %s", commandLine(remove)))
+		return nil
+	}
+	return removeNamespaceImage(ctx, remove, ref)
+}
+
+func removeNamespaceImage(ctx context.Context, cmd *exec.Cmd, ref string) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := runContext(ctx, cmd); err != nil {
+		return fmt.Errorf("ctr images rm %s: %w: %s", ref, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func hasRef(images kindImages, ref string) bool {
+	for _, image := range images.Images {
+		if contains(image.RepoTags, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// addStrayImagesSection warns about images the kubelet can't see, with
+// actions to move them into containerdNamespace or delete them.
+func addStrayImagesSection(layout *flexlayout.FlexLayout, strays []strayImage, err error) {
+	if err != nil {
+		text := component.NewTextf("Unable to check other containerd namespaces: %s", err)
+		text.SetStatus(component.TextStatusWarning)
+		section := layout.AddSection()
+		section.Add(text, component.WidthFull)
+	}
+	if len(strays) == 0 {
+		return
+	}
+
+	table := component.NewTable("Images Outside "+containerdNamespace, "No images",
+		component.NewTableCols("Image", "Namespace", "Node", "Size"))
+	for _, stray := range strays {
+		text := component.NewTextf("present in %q namespace, not visible to Kubernetes", stray.Namespace)
+		if stray.Visible {
+			text = component.NewTextf("present in %q namespace too, a copy Kubernetes doesn't use", stray.Namespace)
+		}
+		text.SetStatus(component.TextStatusWarning)
+		row := component.TableRow{
+			"Image":     component.NewText(stray.Ref),
+			"Namespace": text,
+			"Node":      component.NewText(stray.Node),
+			"Size":      component.NewText(displaySize(stray.Size)),
+		}
+		payload := func(name string) action.Payload {
+			return action.Payload{
+				"action":    name,
+				"node":      stray.Node,
+				"namespace": stray.Namespace,
+				"imageID":   stray.Ref,
+			}
+		}
+		if !stray.Visible {
+			row.AddAction(component.GridAction{
+				Name:       "Move to " + containerdNamespace,
+				ActionPath: names.MoveStray,
+				Payload:    payload(names.MoveStray),
+				Type:       component.GridActionPrimary,
+			})
+		}
+		row.AddAction(component.GridAction{
+			Name:       "Delete",
+			ActionPath: names.DeleteStray,
+			Payload:    payload(names.DeleteStray),
+			Confirmation: &component.Confirmation{
+				Title: "Delete image?",
+				Body:  fmt.Sprintf("Do you want to delete %s from the %q namespace on %s?", stray.Ref, stray.Namespace, stray.Node),
+			},
+			Type: component.GridActionDanger,
+		})
+		table.Add(row)
+	}
+	section := layout.AddSection()
+	section.Add(table, component.WidthFull)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+// nodeRuntimeName is the CLI used to run commands inside the kind node
+// containers. Empty means it follows the kind provider, see nodeRuntime.
+var nodeRuntimeName string
+
+// nodeRuntime is the CLI that owns the kind node containers. That is the
+// provider kind was told to use with KIND_EXPERIMENTAL_PROVIDER, otherwise
+// docker when the host runtime is nerdctl and docker is installed, since
+// kind then creates its nodes with docker, and the host runtime in every
+// other case.
+func nodeRuntime() string {
+	if nodeRuntimeName != "" {
+		return nodeRuntimeName
+	}
+	if provider := os.Getenv("KIND_EXPERIMENTAL_PROVIDER"); provider != "" {
+		return provider
+	}
+	if host.Name() == "nerdctl" {
+		if _, err := exec.LookPath("docker"); err == nil {
+			return "docker"
+		}
+	}
+	return host.Name()
+}
+
+// nodeExec builds a command that runs inside a kind node container. docker,
+// podman and nerdctl all take the same exec arguments. stdin attaches the
+// command's stdin. args are run as given, so callers running ctr add the
+// containerd namespace with -n themselves.
+func nodeExec(node string, stdin bool, args ...string) *exec.Cmd {
+	execArgs := []string{"exec"}
+	if stdin {
+		execArgs = append(execArgs, "-i")
+	}
+	execArgs = append(execArgs, node)
+	return exec.Command(nodeRuntime(), append(execArgs, args...)...)
+}
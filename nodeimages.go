@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// nodeListTimeout is how long the kind view waits for one node's image
+// listing before showing the others without it.
+const nodeListTimeout = 5 * time.Second
+
+// nodeListing is the cached image listing of one node.
+type nodeListing struct {
+	images  kindImages
+	err     error
+	checked time.Time
+}
+
+// clusterImages is the merged image listing of every node of the cluster.
+type clusterImages struct {
+	kindImages
+	// Nodes maps image IDs to the nodes that have the image.
+	Nodes map[string][]string
+	// Failed holds why nodes could not be listed; their images are missing.
+	Failed map[string]error
+	// Checked is when the oldest of the listings was made.
+	Checked time.Time
+}
+
+// nodeImageCache lists the images of every node in parallel and caches each
+// node on its own, so one slow or failing node neither delays the others
+// nor drops their cached listings.
+type nodeImageCache struct {
+	mu       sync.Mutex
+	listings map[string]nodeListing
+	pending  map[string]bool
+	// generation counts the resets. A listing started before a reset is
+	// returned to its caller but not cached, so it can't replace a newer one.
+	generation int
+}
+
+func newNodeImageCache() *nodeImageCache {
+	return &nodeImageCache{
+		listings: map[string]nodeListing{},
+		pending:  map[string]bool{},
+	}
+}
+
+// List returns the merged images of nodes. Nodes that do not answer within
+// nodeListTimeout are reported as failed; their listing is cached when it
+// finishes and used by the next call.
+func (c *nodeImageCache) List(nodes []string) clusterImages {
+	results := make([]nodeListing, len(nodes))
+	var wg sync.WaitGroup
+	for n, node := range nodes {
+		wg.Add(1)
+		go func(n int, node string) {
+			defer wg.Done()
+			results[n] = c.get(node)
+		}(n, node)
+	}
+	wg.Wait()
+	return mergeListings(nodes, results)
+}
+
+// Cached returns the merged listings cached so far, however old, without
+// listing any node. Nodes never listed are left out.
+func (c *nodeImageCache) Cached() clusterImages {
+	c.mu.Lock()
+	var nodes []string
+	var results []nodeListing
+	for node := range c.listings {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		results = append(results, c.listings[node])
+	}
+	c.mu.Unlock()
+	return mergeListings(nodes, results)
+}
+
+// mergeListings merges the listings of nodes, given in the same order.
+func mergeListings(nodes []string, results []nodeListing) clusterImages {
+	merged := clusterImages{Nodes: map[string][]string{}, Failed: map[string]error{}}
+	index := map[string]int{}
+	for n, node := range nodes {
+		result := results[n]
+		if result.err != nil {
+			merged.Failed[node] = result.err
+			continue
+		}
+		if merged.Checked.IsZero() || result.checked.Before(merged.Checked) {
+			merged.Checked = result.checked
+		}
+		if merged.Backend == "" || result.images.Backend == backendCtr {
+			merged.Backend = result.images.Backend
+		}
+		for _, image := range result.images.Images {
+			if _, ok := index[image.ID]; !ok {
+				index[image.ID] = len(merged.Images)
+				merged.Images = append(merged.Images, image)
+			}
+			merged.Nodes[image.ID] = append(merged.Nodes[image.ID], node)
+		}
+	}
+	return merged
+}
+
+// get returns the cached listing of node while it is fresh, or lists it.
+func (c *nodeImageCache) get(node string) nodeListing {
+	c.mu.Lock()
+	listing, ok := c.listings[node]
+	if ok && time.Since(listing.checked) <= healthTTL {
+		c.mu.Unlock()
+		return listing
+	}
+	// Only one listing per node runs at a time; a slow node is not asked again
+	// until it answers.
+	if c.pending[node] {
+		c.mu.Unlock()
+		return nodeListing{err: fmt.Errorf("still listing after %s", nodeListTimeout)}
+	}
+	c.pending[node] = true
+	generation := c.generation
+	c.mu.Unlock()
+
+	done := make(chan nodeListing, 1)
+	go func() {
+		images, err := listNodeImagesWithRetry(node)
+		listing := nodeListing{images: images, err: err, checked: time.Now()}
+		c.mu.Lock()
+		if generation == c.generation {
+			c.listings[node] = listing
+			delete(c.pending, node)
+		}
+		c.mu.Unlock()
+		done <- listing
+	}()
+
+	select {
+	case listing := <-done:
+		return listing
+	case <-time.After(nodeListTimeout):
+		return nodeListing{err: fmt.Errorf("timed out after %s", nodeListTimeout)}
+	}
+}
+
+func listNodeImagesWithRetry(node string) (kindImages, error) {
+	var images kindImages
+	err := withRetry("listNodeImages "+node, func() error {
+		var err error
+		images, err = listNodeImages(node)
+		return err
+	})
+	return images, err
+}
+
+// Reset drops every cached listing, e.g. after images were loaded or deleted.
+// Listings still running are forgotten too, so the next List starts over.
+func (c *nodeImageCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.listings = map[string]nodeListing{}
+	c.pending = map[string]bool{}
+	c.generation++
+}
+
+// FailedNodes returns the failed nodes as "node: reason", sorted by node.
+func (m clusterImages) FailedNodes() []string {
+	var failed []string
+	for node, err := range m.Failed {
+		failed = append(failed, fmt.Sprintf("%s: %s", node, err))
+	}
+	sort.Strings(failed)
+	return failed
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// listKindNodes returns the node containers of the kind cluster.
+func listKindNodes() ([]string, error) {
+	return listClusterNodes(kindCluster)
+}
+
+// listClusterNodes returns the node containers of the named kind cluster.
+func listClusterNodes(cluster string) ([]string, error) {
+	cmd := exec.Command("kind", "get", "nodes", "--name", cluster)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(cmd); err != nil {
+		return nil, fmt.Errorf("listClusterNodes %s: %w: %s", cluster, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.Fields(stdout.String()), nil
+}
+
+// listKindClusters returns the names of all kind clusters.
+func listKindClusters() ([]string, error) {
+	cmd := exec.Command("kind", "get", "clusters")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(cmd); err != nil {
+		return nil, fmt.Errorf("listKindClusters: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.Fields(stdout.String()), nil
+}
+
+// nodeAvailableBytes returns the free space on the filesystem backing the
+// node's containerd image store.
+func nodeAvailableBytes(node string) (int64, error) {
+	cmd := nodeCommand(node, "df", "--block-size=1", "--output=avail", "/var/lib/containerd")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(cmd); err != nil {
+		return 0, fmt.Errorf("nodeAvailableBytes %s: %w: %s", node, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// The first line is the "Avail" header.
+	fields := strings.Fields(stdout.String())
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("nodeAvailableBytes %s: unexpected df output %q", node, stdout.String())
+	}
+	return strconv.ParseInt(fields[len(fields)-1], 10, 64)
+}
+
+// dockerImageBytes returns the exact size of a local docker image.
+func dockerImageBytes(imageID string) (int64, error) {
+	cmd := exec.Command(host.Name(), "image", "inspect", "--format={{.Size}}", imageID)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(cmd); err != nil {
+		return 0, fmt.Errorf("dockerImageBytes %s: %w: %s", imageID, err, strings.TrimSpace(stderr.String()))
+	}
+	return strconv.ParseInt(strings.TrimSpace(stdout.String()), 10, 64)
+}
+
+// dockerImageID returns the full ID of a host image, e.g. sha256:3f57d9401f8d.
+func dockerImageID(ref string) (string, error) {
+	// docker image inspect --format={{.Id}} {{ref}}
+	cmd := exec.Command(host.Name(), "image", "inspect", "--format={{.Id}}", ref)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(cmd); err != nil {
+		return "", fmt.Errorf("dockerImageID %s: %w: %s", ref, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// checkLocalImage confirms ref is in the host image store before a load is
+// queued, so a pruned image fails the action instead of the queued load.
+// Other inspect failures are left for the load to report.
+func (i *imagePlugin) checkLocalImage(ref string) error {
+	_, err := dockerImageID(ref)
+	if err == nil {
+		return nil
+	}
+	if !strings.Contains(strings.ToLower(err.Error()), "no such") {
+		log.Printf("unable to check that %s exists locally: %s", ref, err)
+		return nil
+	}
+	return i.withBuildxHint(fmt.Errorf("image %s not found locally in %s, did it get pruned?", ref, host.Name()), ref)
+}
+
+// upToDateInKind reports whether every kind node already has the host image
+// ref under the same image ID and, for tags, the same tag, so loading it
+// again would change nothing.
+func upToDateInKind(ref string) (bool, error) {
+	id, err := dockerImageID(ref)
+	if err != nil {
+		return false, err
+	}
+	nodes, err := listKindNodes()
+	if err != nil {
+		return false, err
+	}
+	for _, node := range nodes {
+		images, err := listNodeImages(node)
+		if err != nil {
+			return false, err
+		}
+		// ctr lists manifest digests, which can't be compared with docker image IDs.
+		if images.Backend == backendCtr {
+			return false, nil
+		}
+		image, ok := images.Find(id)
+		if !ok {
+			return false, nil
+		}
+		// Loads by image ID only need the ID; loads by tag need the tag too.
+		if !sameImageID(id, ref) && !hasRepoTag(image, ref) {
+			return false, nil
+		}
+	}
+	return len(nodes) > 0, nil
+}
+
+// hasRepoTag reports whether one of the image's tags is ref, comparing fully
+// qualified references since crictl lists nginx:latest as docker.io/library/nginx:latest.
+func hasRepoTag(image kindImage, ref string) bool {
+	for _, repoTag := range image.RepoTags {
+		if normalizeReference(repoTag) == normalizeReference(ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeArchitecture returns the GOARCH style architecture of a kind node.
+func nodeArchitecture(node string) (string, error) {
+	cmd := nodeCommand(node, "uname", "-m")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := runCommand(cmd); err != nil {
+		return "", fmt.Errorf("nodeArchitecture %s: %w: %s", node, err, strings.TrimSpace(stderr.String()))
+	}
+
+	machine := strings.TrimSpace(stdout.String())
+	switch machine {
+	case "x86_64":
+		return "amd64", nil
+	case "aarch64", "arm64":
+		return "arm64", nil
+	case "armv7l":
+		return "arm", nil
+	default:
+		return machine, nil
+	}
+}
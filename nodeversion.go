@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// kindDefaultNodeMinor maps kind minor releases to the Kubernetes minor
+// version of their default kindest/node image. Newer kind releases are not
+// checked until they are added here.
+var kindDefaultNodeMinor = map[int]int{
+	8: 18, 9: 19, 10: 20, 11: 21, 12: 23, 13: 24, 14: 24, 15: 25, 16: 25, 17: 25,
+	18: 26, 19: 27, 20: 27, 21: 29, 22: 29, 23: 30, 24: 31, 25: 31, 26: 32, 27: 32,
+}
+
+// maxNodeMinorLag is how many Kubernetes minor versions a node image may be
+// behind the installed kind's default before it is reported.
+const maxNodeMinorLag = 2
+
+// inspectNodeImages returns the image each node container was created from.
+func inspectNodeImages(nodes []string) (map[string]string, error) {
+	// docker inspect --format '{{.Name}} {{.Config.Image}}' {{nodes}}
+	cmd := exec.Command(nodeRuntime(), append([]string{"inspect", "--format", "{{.Name}} {{.Config.Image}}"}, nodes...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, commandError(nodeRuntime()+" inspect", err, stderr.String())
+	}
+
+	images := map[string]string{}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// docker prefixes container names with a slash, podman doesn't.
+		images[strings.TrimPrefix(fields[0], "/")] = fields[1]
+	}
+	return images, nil
+}
+
+// nodeImageName drops the digest kind pins its node images with, e.g.
+// kindest/node:v1.27.3@sha256:… becomes kindest/node:v1.27.3.
+func nodeImageName(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i]
+	}
+	return image
+}
+
+// nodeImageVersion returns the Kubernetes version in a node image tag.
+func nodeImageVersion(image string) (string, [3]int, bool) {
+	_, tag := splitFirstTag([]string{nodeImageName(image)})
+	version, ok := parseVersion(tag)
+	return tag, version, ok
+}
+
+// nodeMinorBehind reports whether a Kubernetes version is more than
+// maxNodeMinorLag minor versions behind v1.defaultMinor.
+func nodeMinorBehind(version [3]int, defaultMinor int) bool {
+	return version[0] == 1 && defaultMinor-version[1] > maxNodeMinorLag
+}
+
+// nodeVersionWarnings reports clusters whose nodes run different Kubernetes
+// versions, and node images far behind the default of the installed kind.
+// Both change crictl's output and how images are loaded.
+func nodeVersionWarnings(w *clusterWatcher) []string {
+	clusters, _ := w.Clusters()
+	kind, _ := detectKindVersion()
+	defaultMinor, known := installedKindDefaultMinor()
+	// versions maps node image tags to the clusters running them.
+	versions := map[string][]string{}
+	var behind []string
+	for _, cluster := range clusters {
+		for _, node := range w.Nodes(cluster) {
+			tag, version, ok := nodeImageVersion(w.NodeImage(node))
+			if !ok || contains(versions[tag], cluster) {
+				continue
+			}
+			versions[tag] = append(versions[tag], cluster)
+			if known && nodeMinorBehind(version, defaultMinor) {
+				behind = append(behind, fmt.Sprintf("Kind cluster %s runs node image %s, more than %d minor versions behind v1.%d, "+
+					"the default of the installed kind %s. Recreate it with a current node image.",
+					cluster, tag, maxNodeMinorLag, defaultMinor, kind))
+			}
+		}
+	}
+
+	var warnings []string
+	if len(versions) > 1 {
+		var tags []string
+		for tag := range versions {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		var running []string
+		for _, tag := range tags {
+			running = append(running, fmt.Sprintf("%s on %s", tag, strings.Join(versions[tag], ", ")))
+		}
+		warnings = append(warnings, fmt.Sprintf("Kind nodes run different Kubernetes versions: %s. "+
+			"crictl output and image loading differ between node images.", strings.Join(running, "; ")))
+	}
+	return append(warnings, behind...)
+}
+
+// installedKindDefaultMinor returns the Kubernetes minor version of the
+// installed kind's default node image, if it is known.
+func installedKindDefaultMinor() (int, bool) {
+	kind, err := detectKindVersion()
+	if err != nil {
+		return 0, false
+	}
+	version, ok := parseVersion(kind)
+	if !ok || version[0] != 0 {
+		return 0, false
+	}
+	minor, ok := kindDefaultNodeMinor[version[1]]
+	return minor, ok
+}
@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestNodeImageVersion(t *testing.T) {
+	tests := []struct {
+		image   string
+		tag     string
+		version [3]int
+		ok      bool
+	}{
+		{image: "kindest/node:v1.27.3", tag: "v1.27.3", version: [3]int{1, 27, 3}, ok: true},
+		{
+			image: "kindest/node:v1.27.3@sha256:3966ac761ae0136263ffdb6cfd4db23ef8a83cba8a463690e98317add2c9ba72",
+			tag:   "v1.27.3", version: [3]int{1, 27, 3}, ok: true,
+		},
+		{image: "registry.local:5000/kindest/node:v1.30.0", tag: "v1.30.0", version: [3]int{1, 30, 0}, ok: true},
+		{image: "kindest/node:latest", tag: "latest"},
+		{image: "kindest/node:main-2024", tag: "main-2024"},
+	}
+	for _, test := range tests {
+		tag, version, ok := nodeImageVersion(test.image)
+		if tag != test.tag || version != test.version || ok != test.ok {
+			t.Errorf("nodeImageVersion(%q) = %q, %v, %t, want %q, %v, %t",
+				test.image, tag, version, ok, test.tag, test.version, test.ok)
+		}
+	}
+}
+
+func TestNodeMinorBehind(t *testing.T) {
+	tests := []struct {
+		version      [3]int
+		defaultMinor int
+		want         bool
+	}{
+		{version: [3]int{1, 27, 3}, defaultMinor: 27, want: false},
+		{version: [3]int{1, 25, 0}, defaultMinor: 27, want: false},
+		{version: [3]int{1, 24, 7}, defaultMinor: 27, want: true},
+		{version: [3]int{1, 29, 0}, defaultMinor: 27, want: false},
+		{version: [3]int{2, 0, 0}, defaultMinor: 27, want: false},
+	}
+	for _, test := range tests {
+		if got := nodeMinorBehind(test.version, test.defaultMinor); got != test.want {
+			t.Errorf("nodeMinorBehind(%v, %d) = %t, want %t", test.version, test.defaultMinor, got, test.want)
+		}
+	}
+}
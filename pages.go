@@ -0,0 +1,58 @@
+package main
+
+import "sync"
+
+const (
+	dockerTableName = "docker"
+	kindTableName   = "kind"
+)
+
+// maxRows is the number of rows rendered per page of a table, set with
+// KIND_REGISTRY_MAX_ROWS. Rendering every row of a large listing makes the
+// Octant UI lag, since the page is rebuilt every few seconds.
+var maxRows = 50
+
+// tablePages tracks the page each table is showing. The page is held by the
+// plugin so it survives the periodic refreshes of the view.
+type tablePages struct {
+	mu    sync.Mutex
+	pages map[string]int
+}
+
+func newTablePages() *tablePages {
+	return &tablePages{pages: map[string]int{}}
+}
+
+// Get returns the zero based page to show for a table with total rows,
+// clamped to the last page in case rows went away since it was chosen.
+func (t *tablePages) Get(table string, total int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	page := t.pages[table]
+	if last := pageCount(total) - 1; page > last {
+		page = last
+		t.pages[table] = page
+	}
+	return page
+}
+
+// Move changes the page of a table by delta, e.g. 1 for the next page.
+func (t *tablePages) Move(table string, delta int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	page := t.pages[table] + delta
+	if page < 0 {
+		page = 0
+	}
+	t.pages[table] = page
+}
+
+// pageCount returns the number of pages needed for total rows, at least one.
+func pageCount(total int) int {
+	if total <= maxRows {
+		return 1
+	}
+	return (total + maxRows - 1) / maxRows
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// platformPattern matches os/arch[/variant] platforms such as linux/arm64/v8.
+var platformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(?:/[a-z0-9]+)?$`)
+
+// loadPlatforms are offered when loading an image for a specific platform.
+var loadPlatforms = []string{"linux/amd64", "linux/arm64"}
+
+func validatePlatform(platform string) error {
+	if !platformPattern.MatchString(platform) {
+		return fmt.Errorf("%q is not a platform such as linux/arm64", platform)
+	}
+	return nil
+}
+
+// loadPlatformImage loads one platform of a multi-platform image. kind load
+// docker-image has no platform option, so the platform is exported with
+// docker save --platform and imported with kind load image-archive.
+func (i *imagePlugin) loadPlatformImage(ctx context.Context, imageID, platform string) (err error) {
+	i.progress.Start(fmt.Sprintf("Loading %s (%s) into kind", imageID, platform))
+	defer func() {
+		success := fmt.Sprintf("Loaded %s (%s) into kind in %s", imageID, platform, i.progress.Elapsed())
+		if dryRun {
+			success = fmt.Sprintf("Dry run: would have run %s save --platform %s and kind load image-archive", host.Name(), platform)
+		}
+		i.progress.Finish(err, success)
+	}()
+
+	if host.Name() != "docker" {
+		return fmt.Errorf("loadPlatformImage %s: loading a specific platform needs docker save --platform, which %s does not have", imageID, host.Name())
+	}
+	if err := requireKind(kindLoadImageArchive); err != nil {
+		return fmt.Errorf("loadPlatformImage %s: %w", imageID, err)
+	}
+	if dryRun {
+		log.Printf("dry run: %s save --platform %s %s | kind load image-archive --name %s", host.Name(), platform, imageID, kindCluster)
+		return nil
+	}
+
+	dir, err := ioutil.TempDir("", "kind-registry-load")
+	if err != nil {
+		return fmt.Errorf("loadPlatformImage %s: %w", imageID, err)
+	}
+	defer os.RemoveAll(dir)
+	archive := filepath.Join(dir, "image.tar")
+
+	// docker save --platform {{platform}} -o {{archive}} {{imageID}}
+	save := exec.Command(host.Name(), "save", "--platform", platform, "-o", archive, imageID)
+	var stderr bytes.Buffer
+	save.Stderr = &stderr
+	i.progress.Write(commandLine(save))
+	if err := runContext(ctx, save); err != nil {
+		return fmt.Errorf("loadPlatformImage %s: %w: %s", imageID, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// kind load image-archive {{archive}} --name {{kindCluster}}
+	load := exec.Command("kind", "load", "image-archive", archive, "--name", kindCluster)
+	if err := streamCommand(ctx, load, i.progress.Write); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("loadPlatformImage %s: %w", imageID, err)
+		}
+		return fmt.Errorf("loadPlatformImage %s: %w: %s", imageID, err, strings.Join(i.progress.Output(), "\n"))
+	}
+	return nil
+}
+
+// addLoadPromptSection renders the form to load source for a chosen platform,
+// defaulting to the kind node's own architecture.
+func addLoadPromptSection(layout *flexlayout.FlexLayout, source, nodeArch string, err error) {
+	var choices []component.InputChoice
+	for _, platform := range loadPlatforms {
+		choices = append(choices, component.InputChoice{
+			Label:   platform,
+			Value:   platform,
+			Checked: platform == "linux/"+nodeArch,
+		})
+	}
+
+	card := component.NewCard(component.TitleFromString(fmt.Sprintf("Load %s for a Platform", source)))
+	card.SetBody(component.NewTextf("Load one platform of a multi-platform image. The kind node is %s.", nodeArch))
+	card.AddAction(component.Action{
+		Name:  "Load",
+		Title: fmt.Sprintf("Load %s", source),
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.Load),
+				component.NewFormFieldHidden("imageID", source),
+				component.NewFormFieldRadio("Platform", "platform", choices),
+			},
+		},
+		Modal: true,
+	})
+	if err != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, err.Error()))
+	}
+
+	promptSection := layout.AddSection()
+	promptSection.Add(card, component.WidthFull)
+	layout.AddButton("Dismiss load", action.Payload{
+		"action":  names.LoadPrompt,
+		"imageID": "",
+	})
+}
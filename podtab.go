@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/plugin"
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func (i *imagePlugin) handleTab(request *service.PrintRequest) (plugin.TabResponse, error) {
+	layout := flexlayout.New()
+
+	// Octant treats a nil tab as an error, so explain the problem instead of omitting the tab.
+	if !i.hasTool(host.Name()) {
+		section := layout.AddSection()
+		section.Add(component.NewText(fmt.Sprintf("Image availability is unknown because %s is not available.", host.Name())), component.WidthFull)
+		return plugin.TabResponse{Tab: component.NewTabWithContents(*layout.ToComponent("Local Images"))}, nil
+	}
+
+	if err := i.feedback.Get(); err != nil {
+		addErrorSection(layout, err)
+	}
+
+	pod, err := toPod(request.Object)
+	if err != nil {
+		return plugin.TabResponse{}, err
+	}
+
+	dockerImages, err := listDockerImages()
+	if err != nil {
+		addErrorSection(layout, err)
+	}
+	local := map[string]dockerImage{}
+	for _, image := range dockerImages {
+		local[normalizeReference(image.Reference())] = image
+	}
+
+	kindImages, err := listKindImages()
+	if err != nil {
+		addErrorSection(layout, err)
+	}
+	// inKind maps the references loaded into kind to their image IDs.
+	inKind := map[string]string{}
+	for _, image := range kindImages.Images {
+		for _, repoTag := range image.RepoTags {
+			inKind[normalizeReference(repoTag)] = image.ID
+		}
+	}
+
+	table := component.NewTable("Container Images", "No containers found",
+		component.NewTableCols("Container", "Image", "Status"))
+
+	containers := append(pod.Spec.InitContainers, pod.Spec.Containers...)
+	for _, container := range containers {
+		ref := normalizeReference(container.Image)
+		image, isLocal := local[ref]
+		kindID, loaded := inKind[ref]
+
+		row := component.TableRow{
+			"Container": component.NewText(container.Name),
+			"Image":     podImageLink(container.Image, kindID, image.ID),
+		}
+
+		switch {
+		case loaded:
+			status := component.NewText("Present in kind")
+			status.SetStatus(component.TextStatusOK)
+			row["Status"] = status
+		case isLocal:
+			status := component.NewText("Present locally in docker, not loaded into kind")
+			status.SetStatus(component.TextStatusWarning)
+			row["Status"] = status
+			row.AddAction(component.GridAction{
+				Name:       "Load into Kind",
+				ActionPath: names.Load,
+				Payload: action.Payload{
+					"action":  names.Load,
+					"imageID": image.Reference(),
+				},
+				Type: component.GridActionPrimary,
+			})
+		default:
+			status := component.NewText("Not found in kind or docker")
+			status.SetStatus(component.TextStatusError)
+			row["Status"] = status
+		}
+
+		table.Add(row)
+	}
+
+	section := layout.AddSection()
+	section.Add(table, component.WidthFull)
+	return plugin.TabResponse{Tab: component.NewTabWithContents(*layout.ToComponent("Local Images"))}, nil
+}
+
+// podImageLink links a container's image to its detail page in this plugin,
+// the kind one when the image is loaded, and is plain text when the image is
+// in neither kind nor docker.
+func podImageLink(ref, kindID, dockerID string) component.Component {
+	switch {
+	case kindID != "":
+		return component.NewLink("", ref, pluginPath(kindImagePath, kindID))
+	case dockerID != "":
+		return component.NewLink("", ref, pluginPath(dockerImagePath, dockerID))
+	default:
+		return component.NewText(ref)
+	}
+}
+
+func toPod(object runtime.Object) (*corev1.Pod, error) {
+	switch o := object.(type) {
+	case *corev1.Pod:
+		return o, nil
+	case *unstructured.Unstructured:
+		pod := &corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, pod); err != nil {
+			return nil, fmt.Errorf("convert pod: %w", err)
+		}
+		return pod, nil
+	default:
+		return nil, fmt.Errorf("expected a pod, got %T", object)
+	}
+}
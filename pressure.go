@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/store"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// diskPressureTaint is the taint the node lifecycle controller adds to nodes
+// reporting DiskPressure.
+const diskPressureTaint = "node.kubernetes.io/disk-pressure"
+
+// nodePressure is a kind node under disk pressure.
+type nodePressure struct {
+	Node    string
+	Since   time.Time
+	Message string
+}
+
+// diskPressure returns the nodes reporting the DiskPressure condition or
+// carrying its taint.
+func diskPressure(nodes []corev1.Node) []nodePressure {
+	var pressured []nodePressure
+	for _, node := range nodes {
+		var condition *corev1.NodeCondition
+		for n := range node.Status.Conditions {
+			if c := node.Status.Conditions[n]; c.Type == corev1.NodeDiskPressure && c.Status == corev1.ConditionTrue {
+				condition = &node.Status.Conditions[n]
+			}
+		}
+		tainted := false
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == diskPressureTaint {
+				tainted = true
+			}
+		}
+		switch {
+		case condition != nil:
+			pressured = append(pressured, nodePressure{Node: node.Name, Since: condition.LastTransitionTime.Time, Message: condition.Message})
+		case tainted:
+			pressured = append(pressured, nodePressure{Node: node.Name, Message: "tainted " + diskPressureTaint})
+		}
+	}
+	return pressured
+}
+
+// listKubeNodes returns the Node objects of the kind cluster. Octant's
+// client is tried first, but it talks to whichever cluster Octant was
+// started with, so its nodes only count when they are kind node containers.
+// Otherwise kubectl runs inside the control plane node with its admin
+// kubeconfig, which always reaches the kind cluster.
+func listKubeNodes(ctx context.Context, client service.Dashboard, kindNodes []string) ([]corev1.Node, error) {
+	if client != nil {
+		nodes, err := dashboardNodes(ctx, client, kindNodes)
+		if err == nil && len(nodes) > 0 {
+			return nodes, nil
+		}
+		if err != nil {
+			log.Printf("unable to list nodes through Octant, using kubectl on %s: %s", kindNode, err)
+		}
+	}
+
+	cmd := kubectlCommand("get", "nodes", "-o", "json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, commandError("kubectl get nodes", err, stderr.String())
+	}
+	var list corev1.NodeList
+	if err := json.Unmarshal(stdout.Bytes(), &list); err != nil {
+		return nil, fmt.Errorf("listKubeNodes: could not parse kubectl get nodes output: %w", err)
+	}
+	return list.Items, nil
+}
+
+// kubectlCommand runs kubectl inside the control plane node with its admin
+// kubeconfig, which reaches the kind cluster whatever Octant is connected to.
+func kubectlCommand(args ...string) *exec.Cmd {
+	// docker exec {{node}} kubectl --kubeconfig /etc/kubernetes/admin.conf {{args}}
+	return nodeCommand(kindNode, append([]string{"kubectl", "--kubeconfig", "/etc/kubernetes/admin.conf"}, args...)...)
+}
+
+// dashboardNodes lists the nodes through Octant and keeps the kind ones.
+func dashboardNodes(ctx context.Context, client service.Dashboard, kindNodes []string) ([]corev1.Node, error) {
+	list, err := client.List(ctx, store.Key{APIVersion: "v1", Kind: "Node"})
+	if err != nil {
+		return nil, fmt.Errorf("dashboardNodes: %w", err)
+	}
+	if list == nil {
+		return nil, nil
+	}
+	var nodes []corev1.Node
+	for _, item := range list.Items {
+		if !contains(kindNodes, item.GetName()) {
+			continue
+		}
+		var node corev1.Node
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &node); err != nil {
+			return nil, fmt.Errorf("dashboardNodes %s: %w", item.GetName(), err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// pressureCache keeps the nodes under disk pressure for healthTTL, like the
+// probes. Asking the API server every render would slow the page.
+type pressureCache struct {
+	mu        sync.Mutex
+	checked   time.Time
+	pressured []nodePressure
+	err       error
+}
+
+// Get returns the nodes under disk pressure, asking the cluster when the
+// cached answer is stale.
+func (c *pressureCache) Get(ctx context.Context, client service.Dashboard) ([]nodePressure, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked) <= healthTTL {
+		return c.pressured, c.err
+	}
+	c.checked = time.Now()
+	c.pressured, c.err = nil, nil
+	kindNodes, err := listKindNodes()
+	if err != nil || len(kindNodes) == 0 {
+		kindNodes = []string{kindNode}
+	}
+	nodes, err := listKubeNodes(ctx, client, kindNodes)
+	if err != nil {
+		log.Printf("unable to check kind nodes for disk pressure: %s", err)
+		c.err = err
+		return nil, err
+	}
+	c.pressured = diskPressure(nodes)
+	return c.pressured, nil
+}
+
+// Reset drops the cached conditions, e.g. after images were deleted.
+func (c *pressureCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checked = time.Time{}
+}
+
+// addPressureSection warns that kind nodes are under disk pressure and
+// points at the buttons that free space. Nothing is shown when the cluster
+// couldn't be asked, the rest of the page doesn't depend on it.
+func addPressureSection(layout *flexlayout.FlexLayout, pressured []nodePressure, err error) {
+	if err != nil || len(pressured) == 0 {
+		return
+	}
+	var described []string
+	for _, p := range pressured {
+		d := p.Node
+		if !p.Since.IsZero() {
+			d += fmt.Sprintf(" (for %s)", time.Since(p.Since).Round(time.Minute))
+		}
+		if p.Message != "" {
+			d += ": " + p.Message
+		}
+		described = append(described, d)
+	}
+	text := component.NewTextf("Disk pressure on %s. Kubelet evicts pods and refuses new ones until space is freed. "+
+		"Use Delete all unused kind images or Delete stale images above to reclaim image space.", strings.Join(described, "; "))
+	text.SetStatus(component.TextStatusError)
+	section := layout.AddSection()
+	section.Add(text, component.WidthFull)
+}
@@ -0,0 +1,75 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// setProcessGroup starts the command in its own process group so that any
+// children it spawns can be killed along with it.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// processAlive reports whether a process exists. EPERM means it exists but
+// belongs to another user.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// killProcessGroupID kills the process group a command started with
+// setProcessGroup leads, for commands this process did not start.
+func killProcessGroupID(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
+
+// processStartTime returns when pid started, as an opaque string that only
+// changes when the PID is reused: the start time in clock ticks from
+// /proc/<pid>/stat where there is a /proc, or what ps -o lstart prints.
+func processStartTime(pid int) (string, error) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err == nil {
+		// The command name, in parentheses, may hold spaces; starttime is
+		// the 22nd field and the 20th after it.
+		fields := strings.Fields(string(data[bytes.LastIndexByte(data, ')')+1:]))
+		if len(fields) < 20 {
+			return "", fmt.Errorf("unexpected /proc/%d/stat", pid)
+		}
+		return fields[19], nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	if _, statErr := os.Stat("/proc/self/stat"); statErr == nil {
+		// /proc works, so the process is gone.
+		return "", err
+	}
+
+	// ps -o lstart= -p {{pid}}
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", fmt.Errorf("ps -p %d: %w", pid, err)
+	}
+	started := strings.TrimSpace(string(out))
+	if started == "" {
+		return "", fmt.Errorf("ps printed no start time for pid %d", pid)
+	}
+	return started, nil
+}
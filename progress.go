@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	progressLines     = 10
+	progressRetention = time.Minute
+)
+
+// operationProgress holds the most recent output of a running operation, such
+// as a kind load or a registry push, and the final status of the last one.
+type operationProgress struct {
+	mu       sync.Mutex
+	title    string
+	lines    []string
+	nodes    int
+	started  time.Time
+	finished time.Time
+	status   string
+	failed   bool
+	phases   []progressPhase
+}
+
+// progressPhase is one step of an operation made of several commands, such
+// as the pull and the load of a get.
+type progressPhase struct {
+	Name     string
+	Started  time.Time
+	Finished time.Time
+}
+
+// Start resets the progress for a new operation described by title, e.g.
+// "Loading nginx:latest into kind".
+func (p *operationProgress) Start(title string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.title = title
+	p.lines = nil
+	p.nodes = 0
+	p.started = time.Now()
+	p.finished = time.Time{}
+	p.status = ""
+	p.failed = false
+	p.phases = nil
+}
+
+// Phase ends the current phase, if any, and starts the next one.
+func (p *operationProgress) Phase(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.endPhase(now)
+	p.phases = append(p.phases, progressPhase{Name: name, Started: now})
+	p.lines = append(p.lines, fmt.Sprintf("== %s ==", name))
+}
+
+func (p *operationProgress) endPhase(at time.Time) {
+	if n := len(p.phases); n > 0 && p.phases[n-1].Finished.IsZero() {
+		p.phases[n-1].Finished = at
+	}
+}
+
+// CurrentPhase returns the name of the running phase and its position, e.g.
+// "load", 2 for the second phase. It returns "", 0 without phases.
+func (p *operationProgress) CurrentPhase() (string, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.phases) == 0 {
+		return "", 0
+	}
+	return p.phases[len(p.phases)-1].Name, len(p.phases)
+}
+
+// PhaseTimings describes how long each phase took, e.g. "pull 3s, load 5s".
+func (p *operationProgress) PhaseTimings() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var timings []string
+	for _, phase := range p.phases {
+		finished := phase.Finished
+		if finished.IsZero() {
+			finished = time.Now()
+		}
+		timings = append(timings, fmt.Sprintf("%s %s", phase.Name, finished.Sub(phase.Started).Round(time.Second)))
+	}
+	return strings.Join(timings, ", ")
+}
+
+// Write records a line of output from the operation.
+func (p *operationProgress) Write(line string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// kind prints one of these per node the image is copied to.
+	if strings.Contains(line, "not yet present on node") {
+		p.nodes++
+	}
+
+	p.lines = append(p.lines, line)
+	if len(p.lines) > progressLines {
+		p.lines = p.lines[len(p.lines)-progressLines:]
+	}
+}
+
+// Nodes returns the number of kind nodes the operation started copying to.
+func (p *operationProgress) Nodes() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.nodes
+}
+
+// Elapsed returns how long the current operation has been running.
+func (p *operationProgress) Elapsed() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return time.Since(p.started).Round(time.Second)
+}
+
+// Finish records the outcome of the operation. success is the status shown
+// when err is nil.
+func (p *operationProgress) Finish(err error, success string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.finished = time.Now()
+	p.endPhase(p.finished)
+	elapsed := p.finished.Sub(p.started).Round(time.Second)
+	// Operations with phases say which one went wrong.
+	title := p.title
+	if n := len(p.phases); n > 0 {
+		title = fmt.Sprintf("%s (%s)", p.title, p.phases[n-1].Name)
+	}
+	if errors.Is(err, context.Canceled) {
+		p.failed = true
+		p.status = fmt.Sprintf("%s was cancelled by user after %s", title, elapsed)
+		if shuttingDown() {
+			p.status = fmt.Sprintf("%s was stopped after %s because the plugin shut down", title, elapsed)
+		}
+		if p.nodes > 0 {
+			p.status += fmt.Sprintf("; copying had started on %d node(s) and may have partially completed", p.nodes)
+		}
+		return
+	}
+	if err != nil {
+		p.failed = true
+		p.status = fmt.Sprintf("%s failed after %s: %s", title, elapsed, err)
+		return
+	}
+	p.status = success
+}
+
+// Output returns the recent output lines of the current operation.
+func (p *operationProgress) Output() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lines := make([]string, len(p.lines))
+	copy(lines, p.lines)
+	return lines
+}
+
+// Status returns the final status of the last operation while it is still
+// recent enough to show.
+func (p *operationProgress) Status() (string, bool, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.finished.IsZero() || time.Since(p.finished) > progressRetention {
+		return "", false, false
+	}
+	return p.status, p.failed, true
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+)
+
+// imagePrompt holds the image the user picked for an action that needs more
+// input, so the view can show a form for it, and why the last submission of
+// that form was rejected.
+type imagePrompt struct {
+	mu     sync.Mutex
+	source string
+	err    error
+}
+
+// Set selects source, or dismisses the form when source is empty.
+func (p *imagePrompt) Set(source string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.source = source
+	p.err = nil
+}
+
+// SetError keeps the form open with err shown on it.
+func (p *imagePrompt) SetError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.err = err
+}
+
+func (p *imagePrompt) Get() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.source, p.err
+}
+
+// setPrompt opens prompt for the image in the request, or dismisses it when
+// the image is empty.
+func setPrompt(prompt *imagePrompt, request *service.ActionRequest) error {
+	imageID, _ := request.Payload.String("imageID")
+	if imageID != "" {
+		if err := validateReference(imageID); err != nil {
+			return err
+		}
+	}
+	prompt.Set(imageID)
+	return nil
+}
+
+// formError remembers why the last submission of an always visible form was
+// rejected, since Octant does not show errors returned from actions.
+type formError struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (f *formError) Set(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.err = err
+}
+
+func (f *formError) Get() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err
+}
+
+// actionFeedback keeps the error of the last action while it is recent.
+type actionFeedback struct {
+	mu  sync.Mutex
+	err error
+	at  time.Time
+}
+
+// Record stores the outcome of an action; a nil err clears earlier errors.
+func (f *actionFeedback) Record(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.err = err
+	f.at = time.Now()
+}
+
+func (f *actionFeedback) Get() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err == nil || time.Since(f.at) > progressRetention {
+		return nil
+	}
+	return f.err
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/url"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+// The OCI annotation keys build pipelines stamp on images as labels to
+// record where an image was built from.
+const (
+	ociSourceLabel   = "org.opencontainers.image.source"
+	ociRevisionLabel = "org.opencontainers.image.revision"
+	ociCreatedLabel  = "org.opencontainers.image.created"
+)
+
+// ociProvenanceLabels are the OCI annotation labels shown on the image
+// detail pages, in the order shown.
+var ociProvenanceLabels = []string{
+	ociSourceLabel,
+	ociRevisionLabel,
+	ociCreatedLabel,
+	"org.opencontainers.image.version",
+	"org.opencontainers.image.url",
+	"org.opencontainers.image.title",
+	"org.opencontainers.image.vendor",
+}
+
+// shortRevisionLength is how much of a revision the Revision column shows,
+// as long as git's abbreviated commit hashes.
+const shortRevisionLength = 7
+
+// revisionCell renders the short revision an image was built from, linked
+// to its source when the image has both labels. Images without a revision
+// label show nothing.
+func revisionCell(labels map[string]string) component.Component {
+	revision := labels[ociRevisionLabel]
+	if revision == "" {
+		return component.NewText("")
+	}
+	short := revision
+	if len(short) > shortRevisionLength {
+		short = short[:shortRevisionLength]
+	}
+	// Only web sources are linked; git@ and other remotes can't be opened.
+	if source, err := url.Parse(labels[ociSourceLabel]); err == nil && (source.Scheme == "https" || source.Scheme == "http") {
+		return component.NewLink("", short, source.String())
+	}
+	return component.NewText(short)
+}
+
+// provenance returns the OCI annotation labels an image has, as key=value
+// in the order of ociProvenanceLabels.
+func provenance(labels map[string]string) []string {
+	var found []string
+	for _, key := range ociProvenanceLabels {
+		if value := labels[key]; value != "" {
+			found = append(found, key+"="+value)
+		}
+	}
+	return found
+}
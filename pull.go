@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// pullImage pulls an image with crictl on every node of the kind cluster.
+// Each node pulls on its own so a failing node does not stop the others.
+func (i *imagePlugin) pullImage(ctx context.Context, job queuedJob) (err error) {
+	ref := job.ImageID
+
+	i.pullProgress.Start(fmt.Sprintf("Pulling %s into kind", ref))
+	var pulled []string
+	defer func() {
+		i.pullProgress.Finish(err, fmt.Sprintf("Pulled %s on %d node(s) in %s: %s",
+			ref, len(pulled), i.pullProgress.Elapsed(), strings.Join(pulled, ", ")))
+	}()
+
+	nodes, err := listKindNodes()
+	if err != nil {
+		return fmt.Errorf("pullImage %s: %w", ref, err)
+	}
+
+	args := []string{"pull"}
+	if job.Creds != "" {
+		args = append(args, "--creds", job.Creds)
+	}
+	args = append(args, ref)
+
+	var failed []string
+	unauthorized := false
+	for _, node := range nodes {
+		// docker exec {{node}} crictl pull [--creds user:password] {{ref}}
+		// Only commandLine and logCommand show the command, and both leave
+		// the credentials out.
+		var stdout, stderr bytes.Buffer
+		cmd := crictlCommand(node, args...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := runContext(ctx, cmd); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("pullImage %s: %w", ref, ctx.Err())
+			}
+			output := redactCreds(strings.TrimSpace(stderr.String()), job.Creds)
+			i.pullProgress.Write(fmt.Sprintf("%s: failed: %s", node, output))
+			if isUnauthorized(output) {
+				unauthorized = true
+			}
+			failed = append(failed, node)
+			continue
+		}
+		i.pullProgress.Write(fmt.Sprintf("%s: %s", node, strings.TrimSpace(stdout.String())))
+		pulled = append(pulled, node)
+	}
+
+	if len(failed) > 0 && unauthorized {
+		hint := "the registry wants credentials: enter a username and password, or check \"Use my docker credentials\" to reuse your docker login"
+		if job.Creds != "" {
+			hint = "the registry refused the credentials given, check them or log in again with " + host.Name() + " login " + registryHost(ref)
+		}
+		return fmt.Errorf("pullImage %s: pulled on %d of %d node(s), failed on %s: %s",
+			ref, len(pulled), len(nodes), strings.Join(failed, ", "), hint)
+	}
+	if len(failed) > 0 {
+		return i.withBuildxHint(fmt.Errorf("pullImage %s: pulled on %d of %d node(s), failed on %s: %s",
+			ref, len(pulled), len(nodes), strings.Join(failed, ", "), strings.Join(i.pullProgress.Output(), "\n")), ref)
+	}
+	return nil
+}
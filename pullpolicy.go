@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/store"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// podPull is a container of a pod on a kind node whose imagePullPolicy makes
+// the kubelet pull its image from the registry instead of using the one
+// loaded into the node.
+type podPull struct {
+	Pod       string
+	Container string
+	Image     string
+	Policy    corev1.PullPolicy
+	// Defaulted is set when the pod spec leaves the policy out and
+	// Kubernetes picks Always for a :latest or untagged image.
+	Defaulted bool
+}
+
+// pullPolicy returns the policy the kubelet applies to a container, with
+// the API server's default for containers that leave it out.
+func pullPolicy(c corev1.Container) (corev1.PullPolicy, bool) {
+	if c.ImagePullPolicy != "" {
+		return c.ImagePullPolicy, false
+	}
+	if strings.HasSuffix(normalizeReference(c.Image), ":latest") {
+		return corev1.PullAlways, true
+	}
+	return corev1.PullIfNotPresent, true
+}
+
+// bypassingPulls returns the containers of pods that pull their image on
+// every start, keyed by normalized image reference. Images referenced by
+// digest are left out, since pulling one again gets the same image.
+func bypassingPulls(pods []corev1.Pod) map[string][]podPull {
+	pulls := map[string][]podPull{}
+	for _, pod := range pods {
+		containers := append(append([]corev1.Container(nil), pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, c := range containers {
+			policy, defaulted := pullPolicy(c)
+			if policy != corev1.PullAlways || strings.Contains(c.Image, "@") {
+				continue
+			}
+			ref := normalizeReference(c.Image)
+			pulls[ref] = append(pulls[ref], podPull{
+				Pod:       pod.Namespace + "/" + pod.Name,
+				Container: c.Name,
+				Image:     c.Image,
+				Policy:    policy,
+				Defaulted: defaulted,
+			})
+		}
+	}
+	return pulls
+}
+
+// listKubePods returns the pods scheduled on the kind nodes. Like
+// listKubeNodes, Octant's client is only trusted when it returns pods on
+// kind nodes, and kubectl on the control plane node is asked otherwise.
+func listKubePods(ctx context.Context, client service.Dashboard, kindNodes []string) ([]corev1.Pod, error) {
+	if client != nil {
+		pods, err := dashboardPods(ctx, client, kindNodes)
+		if err == nil && len(pods) > 0 {
+			return pods, nil
+		}
+		if err != nil {
+			log.Printf("unable to list pods through Octant, using kubectl on %s: %s", kindNode, err)
+		}
+	}
+
+	cmd := kubectlCommand("get", "pods", "--all-namespaces", "-o", "json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, commandError("kubectl get pods", err, stderr.String())
+	}
+	var list corev1.PodList
+	if err := json.Unmarshal(stdout.Bytes(), &list); err != nil {
+		return nil, fmt.Errorf("listKubePods: could not parse kubectl get pods output: %w", err)
+	}
+	return list.Items, nil
+}
+
+// dashboardPods lists the pods through Octant and keeps the ones on kind nodes.
+func dashboardPods(ctx context.Context, client service.Dashboard, kindNodes []string) ([]corev1.Pod, error) {
+	list, err := client.List(ctx, store.Key{APIVersion: "v1", Kind: "Pod"})
+	if err != nil {
+		return nil, fmt.Errorf("dashboardPods: %w", err)
+	}
+	if list == nil {
+		return nil, nil
+	}
+	var pods []corev1.Pod
+	for _, item := range list.Items {
+		var pod corev1.Pod
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pod); err != nil {
+			return nil, fmt.Errorf("dashboardPods %s/%s: %w", item.GetNamespace(), item.GetName(), err)
+		}
+		if contains(kindNodes, pod.Spec.NodeName) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// pullPolicyCache keeps the bypassing pulls for healthTTL, like the probes.
+type pullPolicyCache struct {
+	mu      sync.Mutex
+	checked time.Time
+	pulls   map[string][]podPull
+	err     error
+}
+
+// Get returns the containers that pull their image on every start, keyed by
+// normalized image reference.
+func (c *pullPolicyCache) Get(ctx context.Context, client service.Dashboard) (map[string][]podPull, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked) <= healthTTL {
+		return c.pulls, c.err
+	}
+	c.checked = time.Now()
+	kindNodes, err := listKindNodes()
+	if err != nil || len(kindNodes) == 0 {
+		kindNodes = []string{kindNode}
+	}
+	pods, err := listKubePods(ctx, client, kindNodes)
+	if err != nil {
+		log.Printf("unable to check the pull policies of kind pods: %s", err)
+		c.pulls, c.err = nil, err
+		return nil, err
+	}
+	c.pulls, c.err = bypassingPulls(pods), nil
+	return c.pulls, nil
+}
+
+// Reset drops the cached pull policies, e.g. when a refresh is asked for.
+func (c *pullPolicyCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checked = time.Time{}
+}
+
+// describePull names a container and why it pulls, e.g.
+// "default/web-1 (app): imagePullPolicy defaults to Always for :latest".
+func describePull(p podPull) string {
+	if p.Defaulted {
+		return fmt.Sprintf("%s (%s): imagePullPolicy defaults to Always for %s", p.Pod, p.Container, p.Image)
+	}
+	return fmt.Sprintf("%s (%s): imagePullPolicy is Always", p.Pod, p.Container)
+}
+
+// pullPolicyCell adds a warning to the Used by cell of a tag that pods pull
+// from the registry instead of using.
+func pullPolicyCell(usedBy component.Component, pulls []podPull) component.Component {
+	text := component.NewTextf("Pulled on start, not taken from kind, by %d container(s): see the image page", len(pulls))
+	text.SetStatus(component.TextStatusWarning)
+	return component.NewList(nil, []component.Component{usedBy, text})
+}
+
+// imagePulls returns the bypassing pulls of any of an image's tags.
+func imagePulls(pulls map[string][]podPull, repoTags []string) []podPull {
+	var found []podPull
+	for _, repoTag := range repoTags {
+		found = append(found, pulls[normalizeReference(repoTag)]...)
+	}
+	return found
+}
+
+// pullPolicyView explains on the kind image page why pods don't use the
+// loaded image.
+func pullPolicyView(pulls []podPull) *component.FlexLayout {
+	layout := flexlayout.New()
+	text := component.NewText("These containers have imagePullPolicy Always, set or defaulted for :latest and untagged images, " +
+		"so the kubelet pulls the image from its registry on every start and ignores the copy loaded into kind. " +
+		"When the registry doesn't have it, the pods fail with ErrImagePull. " +
+		"Set imagePullPolicy: IfNotPresent or Never, or use a tag other than latest, to run the loaded image.")
+	text.SetStatus(component.TextStatusWarning)
+	section := layout.AddSection()
+	section.Add(text, component.WidthFull)
+
+	var described []string
+	for _, p := range pulls {
+		described = append(described, describePull(p))
+	}
+	listSection := layout.AddSection()
+	listSection.Add(component.NewCodeBlock(strings.Join(described, "\n")), component.WidthFull)
+
+	view := layout.ToComponent("Pull Policy")
+	view.SetAccessor("pull-policy")
+	return view
+}
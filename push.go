@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const pushHistorySize = 5
+
+// pushRecord is the outcome of a finished push.
+type pushRecord struct {
+	Source   string
+	Target   string
+	Finished time.Time
+	Err      error
+}
+
+// pushHistory keeps the most recent pushes, newest first.
+type pushHistory struct {
+	mu      sync.Mutex
+	records []pushRecord
+}
+
+func (h *pushHistory) Add(record pushRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records = append([]pushRecord{record}, h.records...)
+	if len(h.records) > pushHistorySize {
+		h.records = h.records[:pushHistorySize]
+	}
+}
+
+func (h *pushHistory) List() []pushRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records := make([]pushRecord, len(h.records))
+	copy(records, h.records)
+	return records
+}
+
+// pushImage tags the image as job.Target and pushes it. Credentials come from
+// the runtime's own login and credential helpers; the plugin never handles them.
+func (i *imagePlugin) pushImage(ctx context.Context, job queuedJob) (err error) {
+	imageID, target := job.ImageID, job.Target
+
+	i.pushProgress.Start(fmt.Sprintf("Pushing %s to %s", imageID, target))
+	defer func() {
+		i.pushProgress.Finish(err, fmt.Sprintf("Pushed %s to %s in %s", imageID, target, i.pushProgress.Elapsed()))
+		i.history.Add(pushRecord{Source: imageID, Target: target, Finished: time.Now(), Err: err})
+		if err == nil {
+			i.inspects.Reset()
+			i.details.Reset()
+			i.digests.Reset()
+		}
+	}()
+
+	// docker tag {{imageID}} {{target}}
+	var stderr bytes.Buffer
+	cmd := exec.Command(host.Name(), "tag", imageID, target)
+	cmd.Stderr = &stderr
+	if err := runContext(ctx, cmd); err != nil {
+		return fmt.Errorf("pushImage %s: %w: %s", imageID, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// docker push {{target}}
+	cmd = exec.Command(host.Name(), "push", target)
+	if err := streamCommand(ctx, cmd, i.pushProgress.Write); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("pushImage %s: %w", imageID, err)
+		}
+		// docker's own output is kept as is so auth failures read the same as on the command line.
+		output := strings.Join(i.pushProgress.Output(), "\n")
+		if strings.Contains(output, "denied") || strings.Contains(output, "unauthorized") {
+			output += fmt.Sprintf("\nCheck that you are logged in with %s login, or that a credential helper is configured for the registry.", host.Name())
+		}
+		return fmt.Errorf("pushImage %s: %w: %s", imageID, err, output)
+	}
+
+	return nil
+}
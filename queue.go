@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// queueSize is the number of jobs that may wait in a queue, set with
+// KIND_REGISTRY_QUEUE_SIZE.
+var queueSize = 10
+
+type queuedJob struct {
+	// ImageID is the image reference, or the archive path for archive loads.
+	ImageID string
+	// Target is the reference a push sends the image to. It is empty for loads.
+	Target string
+	// Archive is set when ImageID is the path of a docker save tarball.
+	Archive bool
+	// Creds is the user:password a pull authenticates with, if any.
+	Creds string
+	// Platform is the os/arch a load exports, when not the default.
+	Platform  string
+	QueuedAt  time.Time
+	StartedAt time.Time
+	// Auto is set for loads queued by auto-sync rather than by hand.
+	Auto bool
+	// Cluster is the cluster a job of the cluster queue creates, starts or deletes.
+	Cluster *clusterSpec
+	// Build is the buildx build a get job runs instead of pulling.
+	Build *buildxBuild
+	// Stray is the image a maintenance job moves or deletes.
+	Stray *strayImage
+	// Batch is the kind images a bulk delete removes.
+	Batch []string
+}
+
+func (j queuedJob) same(other queuedJob) bool {
+	return j.ImageID == other.ImageID && j.Target == other.Target && j.Archive == other.Archive && j.Platform == other.Platform
+}
+
+// jobQueue serializes operations such as kind loads through a single worker so
+// that requests made while another one is in flight are queued instead of rejected.
+type jobQueue struct {
+	mu      sync.Mutex
+	name    string
+	size    int
+	current *queuedJob
+	cancel  context.CancelFunc
+	pending []queuedJob
+	wake    chan struct{}
+
+	// Finished, when set, is called by the worker with the outcome of every job.
+	Finished func(job queuedJob, err error)
+}
+
+// newJobQueue creates a queue; name is the operation used in messages, e.g. "load".
+func newJobQueue(name string, size int) *jobQueue {
+	return &jobQueue{
+		name: name,
+		size: size,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Enqueue adds a job to the queue. Requests for a job that is already running
+// or queued are rejected with an error naming the image.
+func (q *jobQueue) Enqueue(job queuedJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.current != nil && q.current.same(job) {
+		return fmt.Errorf("%s of %s is already in progress", q.name, job.ImageID)
+	}
+	for _, pending := range q.pending {
+		if pending.same(job) {
+			return fmt.Errorf("%s of %s is already queued", q.name, job.ImageID)
+		}
+	}
+	if len(q.pending) >= q.size {
+		if q.current != nil {
+			return fmt.Errorf("%s queue is full (%d pending behind %s), please wait", q.name, len(q.pending), q.current.ImageID)
+		}
+		return fmt.Errorf("%s queue is full (%d pending), please wait", q.name, len(q.pending))
+	}
+
+	job.QueuedAt = time.Now()
+	q.pending = append(q.pending, job)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Size returns the number of jobs that may wait in the queue.
+func (q *jobQueue) Size() int {
+	return q.size
+}
+
+// Snapshot returns the job currently running, if any, and a copy of the pending jobs.
+func (q *jobQueue) Snapshot() (*queuedJob, []queuedJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var current *queuedJob
+	if q.current != nil {
+		job := *q.current
+		current = &job
+	}
+	pending := make([]queuedJob, len(q.pending))
+	copy(pending, q.pending)
+	return current, pending
+}
+
+// Cancel stops the operation for an image, either by cancelling the running one or
+// by removing it from the pending list. It reports whether anything was cancelled.
+func (q *jobQueue) Cancel(imageID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.current != nil && q.current.ImageID == imageID {
+		q.cancel()
+		return true
+	}
+	for i, job := range q.pending {
+		if job.ImageID == imageID {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Stop drops the pending jobs and cancels the running one, if any.
+func (q *jobQueue) Stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = nil
+	if q.current != nil {
+		q.cancel()
+	}
+}
+
+// Busy reports whether a job is still running.
+func (q *jobQueue) Busy() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.current != nil
+}
+
+func (q *jobQueue) next() (context.Context, queuedJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.cancel != nil {
+		q.cancel()
+		q.cancel = nil
+	}
+
+	if len(q.pending) == 0 {
+		q.current = nil
+		return nil, queuedJob{}, false
+	}
+
+	job := q.pending[0]
+	q.pending = q.pending[1:]
+	job.StartedAt = time.Now()
+	q.current = &job
+
+	ctx, cancel := context.WithCancel(withOperation(shutdownContext, q.name))
+	q.cancel = cancel
+	return ctx, job, true
+}
+
+// Run processes queued jobs one at a time. It never returns.
+func (q *jobQueue) Run(run func(ctx context.Context, job queuedJob) error) {
+	for range q.wake {
+		for {
+			ctx, job, ok := q.next()
+			if !ok {
+				break
+			}
+			inflight.Start(q.name, job)
+			err := run(ctx, job)
+			inflight.Finish(q.name)
+			if q.Finished != nil {
+				q.Finished(job, err)
+			}
+			if err != nil {
+				log.Printf("%s %s failed: %s", q.name, job.ImageID, err)
+				continue
+			}
+			log.Printf("%s %s finished in %s", q.name, job.ImageID, time.Since(job.StartedAt).Round(time.Second))
+		}
+	}
+}
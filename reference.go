@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+)
+
+var (
+	// referencePattern is a simplified form of the distribution reference
+	// grammar: [domain[:port]/]path[:tag][@digest].
+	referencePattern = regexp.MustCompile(`^` +
+		`(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+		`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?` +
+		`(?:@sha256:[a-f0-9]{64})?$`)
+
+	// imageIDPattern matches full and truncated image IDs.
+	imageIDPattern = regexp.MustCompile(`^(?:sha256:)?[a-f0-9]{12,64}$`)
+)
+
+// shellUnsafe are characters a shell interprets. No image reference contains
+// them, and paths with them are refused too: values end up in the commands
+// shown to copy into a terminal.
+const shellUnsafe = "`$;&|<>\\\"'(){}*?!#"
+
+// validatePayloadPath rejects a path submitted from a form that contains
+// control or shell characters. Spaces are allowed, commandLine quotes them.
+func validatePayloadPath(path string) error {
+	if i := strings.IndexAny(path, shellUnsafe); i >= 0 {
+		return fmt.Errorf("%q contains %q, which is not allowed in paths", path, path[i])
+	}
+	for _, r := range path {
+		if r < ' ' || r == 0x7f {
+			return fmt.Errorf("%q contains a control character, which is not allowed in paths", path)
+		}
+	}
+	return nil
+}
+
+// validateReference rejects anything that is not an image reference or image
+// ID before it is passed to a command.
+func validateReference(ref string) error {
+	if ref == "" {
+		return fmt.Errorf("no image given")
+	}
+	if imageIDPattern.MatchString(ref) || referencePattern.MatchString(ref) {
+		return nil
+	}
+	return fmt.Errorf("%q is not a valid image reference", ref)
+}
+
+// payloadImage reads the imageID of an action payload, trimmed of the
+// whitespace a pasted reference brings along, and validates it.
+func payloadImage(payload action.Payload) (string, error) {
+	imageID, err := payload.String("imageID")
+	if err != nil {
+		return "", err
+	}
+	imageID = strings.TrimSpace(imageID)
+	if err := validateReference(imageID); err != nil {
+		return "", err
+	}
+	return imageID, nil
+}
+
+// sameImageID reports whether two image IDs name the same image. docker
+// lists truncated IDs such as 3f57d9401f8d while crictl reports
+// sha256:3f57d9401f8d..., so the sha256: prefix is ignored and the shorter
+// ID only has to be a prefix of the longer one.
+func sameImageID(a, b string) bool {
+	if !imageIDPattern.MatchString(a) || !imageIDPattern.MatchString(b) {
+		return false
+	}
+	a, b = strings.TrimPrefix(a, "sha256:"), strings.TrimPrefix(b, "sha256:")
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return strings.HasPrefix(b, a)
+}
+
+// normalizeReference expands a short image reference the way the container
+// runtime does, e.g. "nginx" becomes "docker.io/library/nginx:latest", so
+// references from pod specs, docker and crictl can be compared.
+func normalizeReference(ref string) string {
+	name, suffix := ref, ""
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, suffix = name[:i], name[i:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, suffix = name[:i], name[i:]
+	} else {
+		suffix = ":latest"
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 1 || !(strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		if len(parts) == 1 {
+			name = "library/" + name
+		}
+		name = "docker.io/" + name
+	}
+	return name + suffix
+}
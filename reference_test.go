@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestSameImageID(t *testing.T) {
+	const full = "3f57d9401f8d42f986df300f0c69192fc41da28ccc8d797829467780db3dd741"
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{name: "same full ID", a: "sha256:" + full, b: "sha256:" + full, want: true},
+		{name: "short and full", a: full[:12], b: "sha256:" + full, want: true},
+		{name: "full and short", a: "sha256:" + full, b: full[:12], want: true},
+		{name: "both without sha256:", a: full[:12], b: full, want: true},
+		{name: "only one with sha256:", a: "sha256:" + full[:12], b: full, want: true},
+		{name: "prefixes differ", a: "3f57d9401f8e", b: "sha256:" + full, want: false},
+		{name: "different short IDs", a: full[:12], b: "4f57d9401f8d", want: false},
+		{name: "too short", a: full[:6], b: full, want: false},
+		{name: "reference", a: "nginx:1.25", b: full, want: false},
+		{name: "empty", a: "", b: full, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := sameImageID(test.a, test.b); got != test.want {
+				t.Errorf("sameImageID(%q, %q) = %t, want %t", test.a, test.b, got, test.want)
+			}
+		})
+	}
+}
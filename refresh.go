@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// refreshInterval is how soon after a refresh another one is ignored, so a
+// double click lists the images once.
+const refreshInterval = time.Second
+
+// refresher rate limits the Refresh button.
+type refresher struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Start reports whether a refresh may run now, and records it. It returns
+// false within refreshInterval of the previous refresh.
+func (r *refresher) Start() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.last) < refreshInterval {
+		return false
+	}
+	r.last = time.Now()
+	return true
+}
+
+// refreshNow drops the cached image listings and lists the kind nodes again
+// right away, for images built or tagged outside the plugin. Host images are
+// listed on every render and need no refreshing. Listings already running
+// when the caches are dropped finish without being cached, so they can't
+// replace the fresh ones.
+func (i *imagePlugin) refreshNow() {
+	if !i.refresher.Start() {
+		log.Printf("ignoring refresh, the last one was less than %s ago", refreshInterval)
+		return
+	}
+	i.nodeImages.Reset()
+	i.inspects.Reset()
+	i.health.Reset()
+	i.diskUsage.Reset()
+	i.strays.Reset()
+	i.buildx.Reset()
+	i.hostUsage.Reset()
+	i.pressure.Reset()
+	i.policies.Reset()
+	i.selfChecks.Reset()
+	i.registries.Reset()
+	i.digests.Reset()
+
+	if !i.clusters.Exists() {
+		return
+	}
+	nodes, err := listKindNodes()
+	if err != nil || len(nodes) == 0 {
+		nodes = []string{kindNode}
+	}
+	i.nodeImages.List(nodes)
+}
+
+// updatedAgo tells how old a listing is, for table titles.
+func updatedAgo(checked time.Time) string {
+	age := time.Since(checked).Round(time.Second)
+	if checked.IsZero() || age < time.Second {
+		return "updated just now"
+	}
+	return fmt.Sprintf("updated %s ago", age)
+}
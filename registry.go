@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/store"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// registryContainer is the name of the local registry container from kind's
+// local registry recipe, set with KIND_REGISTRY_CONTAINER.
+var registryContainer = envOrDefault("KIND_REGISTRY_CONTAINER", "kind-registry")
+
+// registryClient is used for the registry HTTP API. The registry is local, so
+// anything slower than this means it is not really there.
+var registryClient = &http.Client{Timeout: 5 * time.Second}
+
+// localRegistry is a running registry reachable from the host.
+type localRegistry struct {
+	// Container is empty when the registry was found through the
+	// local-registry-hosting ConfigMap rather than by container name.
+	Container string
+	Host      string
+}
+
+// registryDetector finds the local registry and remembers the last one found
+// so pushes, which have no dashboard client, can use it. Detection is reused
+// for healthTTL, since every render of the image pages asks for it.
+type registryDetector struct {
+	// detecting lets one Detect at a time run the lookup, so renders that
+	// arrive together share it.
+	detecting sync.Mutex
+	mu        sync.Mutex
+	last      *localRegistry
+	err       error
+	checked   time.Time
+}
+
+// Detect looks for the registry container by name and falls back to the
+// local-registry-hosting ConfigMap documented by KEP-1755. It returns nil when
+// there is no local registry. client may be nil to only check the container.
+// A lookup less than healthTTL old is returned as is.
+func (d *registryDetector) Detect(ctx context.Context, client service.Dashboard) (*localRegistry, error) {
+	d.detecting.Lock()
+	defer d.detecting.Unlock()
+	d.mu.Lock()
+	if time.Since(d.checked) <= healthTTL {
+		defer d.mu.Unlock()
+		return d.last, d.err
+	}
+	d.mu.Unlock()
+
+	registry, err := findLocalRegistry()
+	if err == nil && registry == nil && client != nil {
+		// Not every cluster Octant points at is a kind cluster, so a failed
+		// lookup just means there is no registry.
+		if registry, err = registryFromConfigMap(ctx, client); err != nil {
+			log.Printf("unable to read local registry ConfigMap: %s", err)
+			registry, err = nil, nil
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.checked, d.err = time.Now(), err
+	if err != nil {
+		return nil, err
+	}
+	d.last = registry
+	return registry, nil
+}
+
+// Last returns the registry found by the last Detect call, without looking.
+func (d *registryDetector) Last() *localRegistry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.last
+}
+
+// Reset makes the next Detect look again, e.g. when a refresh is asked for.
+func (d *registryDetector) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.checked = time.Time{}
+}
+
+type registryInspect struct {
+	State struct {
+		Running bool
+	}
+	NetworkSettings struct {
+		Ports map[string][]struct {
+			HostIp   string
+			HostPort string
+		}
+	}
+}
+
+// findLocalRegistry looks up the registry container and the host port it
+// publishes. It returns nil when the container does not exist or is not running.
+func findLocalRegistry() (*localRegistry, error) {
+	// docker container inspect {{registryContainer}}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(host.Name(), "container", "inspect", registryContainer)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		if strings.Contains(stderr.String(), "No such container") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("findLocalRegistry %s: %w: %s", registryContainer, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var inspects []registryInspect
+	if err := json.Unmarshal(stdout.Bytes(), &inspects); err != nil {
+		return nil, fmt.Errorf("findLocalRegistry %s: %w", registryContainer, err)
+	}
+	if len(inspects) == 0 || !inspects[0].State.Running {
+		return nil, nil
+	}
+
+	for _, binding := range inspects[0].NetworkSettings.Ports["5000/tcp"] {
+		if binding.HostPort != "" {
+			return &localRegistry{Container: registryContainer, Host: "localhost:" + binding.HostPort}, nil
+		}
+	}
+	return nil, fmt.Errorf("findLocalRegistry %s: port 5000 is not published to the host", registryContainer)
+}
+
+// registryFromConfigMap reads the registry host from the
+// kube-public/local-registry-hosting ConfigMap of the current cluster.
+func registryFromConfigMap(ctx context.Context, client service.Dashboard) (*localRegistry, error) {
+	key := store.Key{
+		Namespace:  "kube-public",
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       "local-registry-hosting",
+	}
+	object, err := client.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("registryFromConfigMap: %w", err)
+	}
+	if object == nil {
+		return nil, nil
+	}
+
+	hosting, _, err := unstructured.NestedString(object.Object, "data", "localRegistryHosting.v1")
+	if err != nil {
+		return nil, fmt.Errorf("registryFromConfigMap: %w", err)
+	}
+	// The value is a small YAML document; only the top level host is needed.
+	scanner := bufio.NewScanner(strings.NewReader(hosting))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "host:") {
+			host := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "host:")), `"'`)
+			return &localRegistry{Host: host}, nil
+		}
+	}
+	return nil, nil
+}
+
+// Name returns a description of the registry for messages.
+func (r localRegistry) Name() string {
+	if r.Container != "" {
+		return fmt.Sprintf("%s (%s)", r.Container, r.Host)
+	}
+	return r.Host
+}
+
+// Target returns the reference ref is pushed to, with any registry host in
+// ref replaced by the local registry, e.g. localhost:5000/library/nginx:latest.
+func (r localRegistry) Target(ref string) string {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref = parts[1]
+	}
+	return r.Host + "/" + ref
+}
+
+// get decodes the JSON response of a registry API request.
+func (r localRegistry) get(path string, v interface{}) error {
+	resp, err := registryClient.Get("http://" + r.Host + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Repositories lists the repositories in the registry.
+func (r localRegistry) Repositories() ([]string, error) {
+	var catalog struct {
+		Repositories []string `json:"repositories"`
+	}
+	// The registry pages the catalog at 100 repositories by default.
+	if err := r.get("/v2/_catalog?n=1000", &catalog); err != nil {
+		return nil, fmt.Errorf("Repositories %s: %w", r.Host, err)
+	}
+	sort.Strings(catalog.Repositories)
+	return catalog.Repositories, nil
+}
+
+// Tags lists the tags of a repository in the registry.
+func (r localRegistry) Tags(repository string) ([]string, error) {
+	var tags struct {
+		Tags []string `json:"tags"`
+	}
+	if err := r.get("/v2/"+repository+"/tags/list", &tags); err != nil {
+		return nil, fmt.Errorf("Tags %s: %w", repository, err)
+	}
+	sort.Strings(tags.Tags)
+	return tags.Tags, nil
+}
+
+// manifestAccept lists the manifest media types the registry may store, so a
+// HEAD returns the digest of the stored manifest rather than a converted one.
+var manifestAccept = strings.Join([]string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}, ", ")
+
+// errDeleteDisabled is returned for deletes on a registry that was started
+// without REGISTRY_STORAGE_DELETE_ENABLED=true, which is the default.
+var errDeleteDisabled = errors.New("the registry does not allow deletes. Restart its container with " +
+	"-e REGISTRY_STORAGE_DELETE_ENABLED=true to enable them")
+
+// Digest returns the manifest digest a tag points to.
+func (r localRegistry) Digest(repository, tag string) (string, error) {
+	path := "/v2/" + repository + "/manifests/" + tag
+	req, err := http.NewRequest(http.MethodHead, "http://"+r.Host+path, nil)
+	if err != nil {
+		return "", fmt.Errorf("Digest %s:%s: %w", repository, tag, err)
+	}
+	req.Header.Set("Accept", manifestAccept)
+	resp, err := registryClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Digest %s:%s: %w", repository, tag, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Digest %s:%s: HEAD %s: %s", repository, tag, path, resp.Status)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("Digest %s:%s: no Docker-Content-Digest header", repository, tag)
+	}
+	return digest, nil
+}
+
+// registryDigestCache keeps the manifest digest of every repository:tag in
+// the local registry, so rendering the registry page doesn't send a HEAD
+// request per tag. A tag only moves when it is pushed again, so entries are
+// kept until a push, a delete or a refresh resets the cache.
+type registryDigestCache struct {
+	mu      sync.Mutex
+	digests map[string]string
+}
+
+func newRegistryDigestCache() *registryDigestCache {
+	return &registryDigestCache{digests: map[string]string{}}
+}
+
+// Get returns the digest repository:tag points to in registry, asking the
+// registry the first time.
+func (c *registryDigestCache) Get(registry localRegistry, repository, tag string) (string, error) {
+	key := registry.Host + "/" + repository + ":" + tag
+	c.mu.Lock()
+	digest, ok := c.digests[key]
+	c.mu.Unlock()
+	if ok {
+		return digest, nil
+	}
+
+	digest, err := registry.Digest(repository, tag)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.digests[key] = digest
+	return digest, nil
+}
+
+// Reset drops every cached digest.
+func (c *registryDigestCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.digests = map[string]string{}
+}
+
+// DeleteManifest deletes a manifest, and with it every tag pointing to it.
+// The layers stay on disk until garbage collection runs.
+func (r localRegistry) DeleteManifest(repository, digest string) error {
+	path := "/v2/" + repository + "/manifests/" + digest
+	req, err := http.NewRequest(http.MethodDelete, "http://"+r.Host+path, nil)
+	if err != nil {
+		return fmt.Errorf("DeleteManifest %s@%s: %w", repository, digest, err)
+	}
+	resp, err := registryClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("DeleteManifest %s@%s: %w", repository, digest, err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusOK:
+		return nil
+	case http.StatusMethodNotAllowed:
+		return fmt.Errorf("DeleteManifest %s@%s: %w", repository, digest, errDeleteDisabled)
+	default:
+		return fmt.Errorf("DeleteManifest %s@%s: DELETE %s: %s", repository, digest, path, resp.Status)
+	}
+}
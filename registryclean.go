@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// registryConfig is where the registry:2 image keeps its configuration,
+// which garbage-collect needs to find the storage.
+const registryConfig = "/etc/docker/registry/config.yml"
+
+// deleteRegistryManifest deletes repository@digest from the local registry,
+// which untags every tag pointing to it.
+func (i *imagePlugin) deleteRegistryManifest(repository, digest string) (err error) {
+	ref := repository + "@" + digest
+	if err := validateReference(ref); err != nil {
+		return err
+	}
+	registry := i.registries.Last()
+	if registry == nil {
+		return fmt.Errorf("deleteRegistryManifest %s: no local registry found", ref)
+	}
+
+	i.cleanProgress.Start(fmt.Sprintf("Deleting %s from %s", ref, registry.Name()))
+	defer func() {
+		i.digests.Reset()
+		i.cleanProgress.Finish(err, fmt.Sprintf("Deleted %s. Run garbage collection to free its layers.", ref))
+	}()
+	if dryRun {
+		i.cleanProgress.Write(fmt.Sprintf("Dry run: would have sent DELETE /v2/%s/manifests/%s", repository, digest))
+		return nil
+	}
+	return registry.DeleteManifest(repository, digest)
+}
+
+// registryGCCommand runs the registry's garbage collector in its container.
+func registryGCCommand(container string) *exec.Cmd {
+	// docker exec {{registryContainer}} registry garbage-collect /etc/docker/registry/config.yml
+	return exec.Command(host.Name(), "exec", container, "registry", "garbage-collect", registryConfig)
+}
+
+// collectRegistryGarbage removes the layers no manifest references any more.
+// It runs on the registry queue since it walks the whole storage.
+func (i *imagePlugin) collectRegistryGarbage(ctx context.Context, job queuedJob) (err error) {
+	swept := 0
+	i.cleanProgress.Start(job.ImageID)
+	defer func() {
+		i.cleanProgress.Finish(err, fmt.Sprintf("Collected registry garbage in %s, %d blob(s) deleted", i.cleanProgress.Elapsed(), swept))
+	}()
+
+	registry := i.registries.Last()
+	if registry == nil || registry.Container == "" {
+		return fmt.Errorf("collectRegistryGarbage: garbage collection needs the registry container, only found %s", registryName(registry))
+	}
+	cmd := registryGCCommand(registry.Container)
+	if dryRun {
+		i.cleanProgress.Write(fmt.Sprintf("Dry run: would have run %s", commandLine(cmd)))
+		return nil
+	}
+	// The collector logs every manifest and blob it marks, which is too
+	// much to show, so only the rest of its output is kept.
+	err = streamCommand(ctx, cmd, func(line string) {
+		switch {
+		case strings.Contains(line, "blob eligible for deletion"):
+			swept++
+		case strings.Contains(line, "marking"), strings.Contains(line, "Deleting blob"):
+		default:
+			i.cleanProgress.Write(line)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("collectRegistryGarbage %s: %w", registry.Container, err)
+	}
+	return nil
+}
+
+// registryName describes a possibly missing registry for messages.
+func registryName(registry *localRegistry) string {
+	if registry == nil {
+		return "no registry"
+	}
+	return registry.Name()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+)
+
+// inUseErrors are what docker and podman print when a container still uses
+// the image being removed.
+var inUseErrors = []string{
+	"is using its referenced image",
+	"image is being used by",
+	"image is in use by a container",
+}
+
+// deleteHostImage removes an image from the host runtime with all of its
+// tags, so the row disappears from the table rather than losing one tag.
+func (i *imagePlugin) deleteHostImage(ref string) error {
+	if err := validateReference(ref); err != nil {
+		return fmt.Errorf("deleteHostImage: %w", err)
+	}
+	// Like deleteImage, only remove what the table lists.
+	images, err := listDockerImages()
+	if err != nil {
+		return fmt.Errorf("deleteHostImage %s: %w", ref, err)
+	}
+	grouped, tags := groupByID(images)
+	var refs []string
+	for _, image := range grouped {
+		if image.ID == ref || contains(tags[image.ID], ref) {
+			refs = tags[image.ID]
+			if len(refs) == 0 {
+				refs = []string{image.ID}
+			}
+			break
+		}
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("deleteHostImage %s: no such image in %s", ref, host.Name())
+	}
+
+	// docker image rm {{refs}}
+	cmd := exec.Command(host.Name(), append([]string{"image", "rm"}, refs...)...)
+	i.removeProgress.Start(fmt.Sprintf("Deleting %s from %s", ref, host.Name()))
+	if dryRun {
+		log.Printf("dry run: %s", commandLine(cmd))
+		i.removeProgress.Finish(nil, fmt.Sprintf("Dry run: would have run %s", commandLine(cmd)))
+		return nil
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		output := strings.TrimSpace(stderr.String())
+		for _, message := range inUseErrors {
+			if strings.Contains(output, message) {
+				err = fmt.Errorf("deleteHostImage %s: the image is used by a container, remove the container first: %s", ref, output)
+				i.removeProgress.Finish(err, "")
+				return err
+			}
+		}
+		err = fmt.Errorf("deleteHostImage %s: %w: %s", ref, err, output)
+		i.removeProgress.Finish(err, "")
+		return err
+	}
+	i.details.Reset()
+	i.removeProgress.Finish(nil, fmt.Sprintf("Deleted %s from %s", strings.Join(refs, ", "), host.Name()))
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
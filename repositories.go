@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const (
+	repositoriesPath = "repositories"
+	repositoryPath   = "repository"
+
+	// danglingRepository stands in for <none> in repository links. Repository
+	// names cannot start with an underscore, so it never clashes with one.
+	danglingRepository = "_dangling"
+)
+
+// repositoryGroup is the host images of one repository.
+type repositoryGroup struct {
+	// Name is the repository, or danglingRepository for untagged images.
+	Name   string
+	Images []dockerImage
+}
+
+// Title is the name shown for the group.
+func (g repositoryGroup) Title() string {
+	if g.Name == danglingRepository {
+		return "dangling"
+	}
+	return g.Name
+}
+
+// Newest returns the first image of the group in tag order and when it was
+// created, which is zero when unknown. Images are sorted by groupByRepository.
+func (g repositoryGroup) Newest() (dockerImage, time.Time) {
+	if len(g.Images) == 0 {
+		return dockerImage{}, time.Time{}
+	}
+	created, _ := g.Images[0].Created()
+	return g.Images[0], created
+}
+
+// Size returns the total size of the group, counting images tagged more than
+// once a single time.
+func (g repositoryGroup) Size() int64 {
+	var total int64
+	counted := map[string]bool{}
+	for _, image := range g.Images {
+		if counted[image.ID] {
+			continue
+		}
+		counted[image.ID] = true
+		if size, err := parseSize(image.Size); err == nil {
+			total += size
+		}
+	}
+	return total
+}
+
+// OldVersions returns how many distinct images of the group are not the
+// newest one, and their total size. Every dangling image counts as old.
+// Layers shared with the newest image are counted too, so the space pruning
+// them frees can be less.
+func (g repositoryGroup) OldVersions() (int, int64) {
+	var count int
+	var total int64
+	counted := map[string]bool{}
+	if g.Name != danglingRepository {
+		newest, _ := g.Newest()
+		counted[newest.ID] = true
+	}
+	for _, image := range g.Images {
+		if counted[image.ID] {
+			continue
+		}
+		counted[image.ID] = true
+		count++
+		if size, err := parseSize(image.Size); err == nil {
+			total += size
+		}
+	}
+	return count, total
+}
+
+// pruneCandidateVersions is how many old versions make a repository worth
+// pruning.
+const pruneCandidateVersions = 3
+
+// groupByRepository groups images by repository, sorted by name with the
+// dangling images last. The images of each group are in sortByTag order.
+func groupByRepository(images []dockerImage) []repositoryGroup {
+	index := map[string]int{}
+	var groups []repositoryGroup
+	for _, image := range images {
+		name := image.Repository
+		if name == "" || name == "<none>" {
+			name = danglingRepository
+		}
+		n, ok := index[name]
+		if !ok {
+			n = len(groups)
+			index[name] = n
+			groups = append(groups, repositoryGroup{Name: name})
+		}
+		groups[n].Images = append(groups[n].Images, image)
+	}
+	for _, group := range groups {
+		sortByTag(group.Images)
+	}
+	sort.SliceStable(groups, func(a, b int) bool {
+		if (groups[a].Name == danglingRepository) != (groups[b].Name == danglingRepository) {
+			return groups[b].Name == danglingRepository
+		}
+		return groups[a].Name < groups[b].Name
+	})
+	return groups
+}
+
+func (i *imagePlugin) handleRepositories(request service.Request) (component.ContentResponse, error) {
+	contentResponse := component.NewContentResponse(component.TitleFromString("Repositories"))
+
+	table := component.NewTable("Repositories", "No images found",
+		component.NewTableCols("Repository", "Tags", "Newest Tag", "Created", "Total Size", "Old Versions"))
+	layout := flexlayout.New()
+	i.addMissingToolsSection(layout)
+	if i.hasTool(host.Name()) {
+		images, err := listDockerImages()
+		if err != nil {
+			addErrorSection(layout, err)
+		}
+		var candidates []repositoryGroup
+		for _, group := range groupByRepository(images) {
+			oldCount, oldSize := group.OldVersions()
+			old := component.NewTextf("%d (%s)", oldCount, formatBytes(oldSize))
+			if oldCount >= pruneCandidateVersions || (group.Name == danglingRepository && oldCount > 0) {
+				old.SetStatus(component.TextStatusWarning)
+				candidates = append(candidates, group)
+			}
+			newest, newestAt := group.Newest()
+			created := component.Component(component.NewText(newest.CreatedSince))
+			if !newestAt.IsZero() {
+				created = component.NewTimestamp(newestAt)
+			}
+			newestTag := newest.Tag
+			if group.Name == danglingRepository {
+				newestTag = "—"
+			}
+			table.Add(component.TableRow{
+				"Repository":   component.NewLink("", group.Title(), pluginPath(repositoryPath, group.Name)),
+				"Tags":         component.NewText(strconv.Itoa(len(group.Images))),
+				"Newest Tag":   component.NewText(newestTag),
+				"Created":      created,
+				"Total Size":   component.NewText(formatBytes(group.Size())),
+				"Old Versions": old,
+			})
+		}
+		addPruneCandidatesSection(layout, candidates)
+	}
+
+	repositoriesSection := layout.AddSection()
+	repositoriesSection.Add(table, component.WidthFull)
+	view := layout.ToComponent("Repositories")
+	view.SetAccessor(repositoriesPath)
+	contentResponse.Add(view)
+	return *contentResponse, nil
+}
+
+// handleRepository lists the tags of one repository with the same actions as
+// the host images table.
+func (i *imagePlugin) handleRepository(request service.Request) (component.ContentResponse, error) {
+	// The name is only compared with the listed repositories.
+	name, err := routeValue(request, repositoryPath)
+	if err != nil {
+		return component.ContentResponse{}, err
+	}
+	group := repositoryGroup{Name: name}
+	contentResponse := component.NewContentResponse(component.TitleFromString(fmt.Sprintf("Repository %s", group.Title())))
+
+	columns := append(append([]string(nil), hostColumns...), labelColumns...)
+	table := component.NewTable(group.Title(), "No images in this repository", component.NewTableCols(columns...))
+	layout := flexlayout.New()
+	i.addMissingToolsSection(layout)
+	addDryRunSection(layout)
+	if err := i.feedback.Get(); err != nil {
+		addErrorSection(layout, err)
+	}
+
+	if i.hasTool(host.Name()) {
+		images, err := listDockerImages()
+		if err != nil {
+			addErrorSection(layout, err)
+		}
+		for _, g := range groupByRepository(images) {
+			if g.Name == name {
+				group = g
+			}
+		}
+
+		var ids []string
+		for _, image := range group.Images {
+			ids = append(ids, image.ID)
+		}
+		var inspects map[string]dockerInspect
+		if hostColumnsNeedInspect() {
+			if inspects, err = i.inspects.Get(ids); err != nil {
+				log.Printf("unable to inspect docker images: %s", err)
+			}
+		}
+
+		usage, usageErr := i.hostUsage.Get()
+		if usageErr != nil {
+			log.Printf("unable to list %s containers: %s", host.Name(), usageErr)
+		}
+		cluster := i.clusterInfo(request)
+		// The images are in tag order, which grouping by ID keeps.
+		grouped, tags := groupByID(group.Images)
+		busy := i.busyImages()
+		for _, image := range grouped {
+			row := rowPrinter(image, tags[image.ID], inspects[image.ID], cluster)
+			row["Last used"] = hostLastUsedCell(image, usage)
+			if status, ok := imageBusy(busy, append([]string{image.Reference(), image.ID}, tags[image.ID]...)...); ok {
+				markBusy(row, image.Reference(), status)
+			}
+			table.Add(row)
+		}
+	}
+
+	if current, pending := i.queue.Snapshot(); current != nil {
+		loadingSection := layout.AddSection()
+		loadingSection.Add(component.NewTextf("Loading %s into kind (%d queued)...", current.ImageID, len(pending)), component.WidthFull)
+	} else {
+		addStatusSection(layout, i.progress)
+	}
+	addStatusSection(layout, i.removeProgress)
+
+	backSection := layout.AddSection()
+	backSection.Add(component.NewLink("", "All repositories", pluginPath(repositoriesPath)), component.WidthFull)
+	if name != danglingRepository {
+		backSection.Add(component.NewLink("", "Sync status with kind", pluginPath(syncStatusPath, name)), component.WidthFull)
+	}
+	repositorySection := layout.AddSection()
+	repositorySection.Add(table, component.WidthFull)
+
+	contentResponse.Add(layout.ToComponent(group.Title()))
+	return *contentResponse, nil
+}
+
+// addPruneCandidatesSection sums up the repositories with many old versions,
+// and the dangling images, largest first.
+func addPruneCandidatesSection(layout *flexlayout.FlexLayout, candidates []repositoryGroup) {
+	if len(candidates) == 0 {
+		return
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		_, sizeA := candidates[a].OldVersions()
+		_, sizeB := candidates[b].OldVersions()
+		return sizeA > sizeB
+	})
+
+	var total int64
+	var lines []string
+	for _, group := range candidates {
+		count, size := group.OldVersions()
+		total += size
+		lines = append(lines, fmt.Sprintf("%s: %d old version(s), %s", group.Title(), count, formatBytes(size)))
+	}
+	text := component.NewTextf("Prune candidates, up to %s in old versions (shared layers free less):\n%s",
+		formatBytes(total), strings.Join(lines, "\n"))
+	text.SetStatus(component.TextStatusWarning)
+	section := layout.AddSection()
+	section.Add(text, component.WidthFull)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+const retryAttempts = 3
+
+// retryDelay is the wait before the first retry; it doubles for each one after that.
+var retryDelay = 500 * time.Millisecond
+
+// transientErrors are the messages docker and podman print while the daemon
+// is briefly unreachable, e.g. right after Docker Desktop wakes from sleep.
+var transientErrors = []string{
+	"Cannot connect to the Docker daemon",
+	"error during connect",
+	"connection refused",
+	"connection reset by peer",
+	"i/o timeout",
+	"unexpected EOF",
+}
+
+// loadRetries is how many times a kind load that failed with a transient
+// error is tried again, set with KIND_REGISTRY_LOAD_RETRIES.
+var loadRetries = 2
+
+// transientLoadErrors are kind load failures that go away when the load is
+// simply run again, typically right after the cluster booted.
+var transientLoadErrors = []string{
+	"connection reset",
+	"temporary failure",
+	"node not ready",
+	"NotReady",
+	"i/o timeout",
+	"unexpected EOF",
+}
+
+// permanentLoadErrors are never retried, even when they come with one of the
+// transient messages.
+var permanentLoadErrors = []string{
+	"not present locally",
+}
+
+// isTransientLoad reports whether a failed kind load is worth retrying.
+func isTransientLoad(err error) bool {
+	message := err.Error()
+	for _, permanent := range permanentLoadErrors {
+		if strings.Contains(message, permanent) {
+			return false
+		}
+	}
+	for _, transient := range transientLoadErrors {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// sleepContext waits for d, returning early with the context's error when
+// it is cancelled or times out.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isTransient reports whether err looks like a brief daemon hiccup worth
+// retrying. Partial parses and errors such as a missing kind node are not.
+func isTransient(err error) bool {
+	var parseErr *parseError
+	if errors.As(err, &parseErr) {
+		return false
+	}
+	for _, message := range transientErrors {
+		if strings.Contains(err.Error(), message) {
+			return true
+		}
+	}
+	return false
+}
+
+// withRetry runs op up to retryAttempts times with exponential backoff while
+// it fails with a transient error, and returns the last error.
+func withRetry(name string, op func() error) error {
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt == retryAttempts || !isTransient(err) {
+			return err
+		}
+		log.Printf("%s failed (attempt %d of %d), retrying in %s: %s", name, attempt, retryAttempts, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// hostRuntime is the container CLI that holds the host images and runs the
+// kind node containers.
+type hostRuntime interface {
+	// Name is the CLI executable, e.g. "docker".
+	Name() string
+	// Title is how the runtime is named in the UI, e.g. "Docker".
+	Title() string
+	ListImages() ([]dockerImage, error)
+	// LoadCommand describes the command Load runs, for logs and dry runs.
+	LoadCommand(imageID string) string
+	// Load copies a host image into the kind cluster, passing output lines to onLine.
+	Load(ctx context.Context, imageID string, onLine func(string)) error
+}
+
+// host is the runtime in use. It is docker, then podman, then nerdctl,
+// whichever is installed first, and can be set explicitly with --runtime or
+// KIND_REGISTRY_RUNTIME.
+var host = detectRuntime()
+
+func detectRuntime() hostRuntime {
+	name := os.Getenv("KIND_REGISTRY_RUNTIME")
+	if name == "" {
+		name = "docker"
+		for _, candidate := range []string{"docker", "podman", "nerdctl"} {
+			if _, err := exec.LookPath(candidate); err == nil {
+				name = candidate
+				break
+			}
+		}
+	}
+	return runtimeNamed(name)
+}
+
+// runtimeNamed returns the runtime for a CLI name. Names other than podman
+// and nerdctl are treated as docker compatible.
+func runtimeNamed(name string) hostRuntime {
+	switch name {
+	case "podman":
+		return podmanRuntime{}
+	case "nerdctl":
+		return nerdctlRuntime{}
+	default:
+		return dockerRuntime{name: name}
+	}
+}
+
+// kindLoadCommand is how docker and podman images get into kind.
+func kindLoadCommand(imageID string) *exec.Cmd {
+	// kind load docker-image {{imageID}} --name {{kindCluster}}
+	return exec.Command("kind", "load", "docker-image", imageID, "--name", kindCluster)
+}
+
+// kindLoad runs kindLoadCommand once the installed kind is known to have it.
+func kindLoad(ctx context.Context, imageID string, onLine func(string)) error {
+	if err := requireKind(kindLoadDockerImage); err != nil {
+		return err
+	}
+	return streamCommand(ctx, kindLoadCommand(imageID), onLine)
+}
+
+type dockerRuntime struct {
+	name string
+}
+
+func (r dockerRuntime) Name() string {
+	return r.name
+}
+
+func (r dockerRuntime) Title() string {
+	return strings.Title(r.name)
+}
+
+func (r dockerRuntime) ListImages() ([]dockerImage, error) {
+	return listImageLines(r.name, "image", "ls", "--format="+imageListFormat)
+}
+
+func (r dockerRuntime) LoadCommand(imageID string) string {
+	return commandLine(kindLoadCommand(imageID))
+}
+
+func (r dockerRuntime) Load(ctx context.Context, imageID string, onLine func(string)) error {
+	return kindLoad(ctx, imageID, onLine)
+}
+
+// podmanRuntime is docker compatible apart from the image listing. kind
+// loads from podman when KIND_EXPERIMENTAL_PROVIDER=podman is set.
+type podmanRuntime struct {
+	dockerRuntime
+}
+
+func (podmanRuntime) Name() string {
+	return "podman"
+}
+
+func (podmanRuntime) Title() string {
+	return "Podman"
+}
+
+func (podmanRuntime) ListImages() ([]dockerImage, error) {
+	return listPodmanImages()
+}
+
+// nerdctlRuntime lists images like docker does. kind cannot load from
+// nerdctl unless docker is an alias for it, so images are saved and imported
+// into each node's containerd directly instead.
+type nerdctlRuntime struct{}
+
+func (nerdctlRuntime) Name() string {
+	return "nerdctl"
+}
+
+func (nerdctlRuntime) Title() string {
+	return "nerdctl"
+}
+
+func (nerdctlRuntime) ListImages() ([]dockerImage, error) {
+	return listImageLines("nerdctl", "images", "--format="+imageListFormat)
+}
+
+func (nerdctlRuntime) LoadCommand(imageID string) string {
+	if _, err := exec.LookPath("docker"); err == nil {
+		return commandLine(kindLoadCommand(imageID))
+	}
+	return fmt.Sprintf("nerdctl save %s | %s exec -i <node> ctr -n %s images import --digests -", shellQuote(imageID), nodeRuntime(), containerdNamespace)
+}
+
+func (nerdctlRuntime) Load(ctx context.Context, imageID string, onLine func(string)) error {
+	if _, err := exec.LookPath("docker"); err == nil {
+		return kindLoad(ctx, imageID, onLine)
+	}
+
+	nodes, err := listKindNodes()
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		// The same wording as kind so loads are counted per node.
+		onLine(fmt.Sprintf("Image: %q not yet present on node %q, loading...", imageID, node))
+		if err := nerdctlImport(ctx, imageID, node); err != nil {
+			return fmt.Errorf("%s: %w", node, err)
+		}
+	}
+	return nil
+}
+
+// nerdctlImport pipes nerdctl save into ctr images import on the node.
+func nerdctlImport(ctx context.Context, imageID, node string) error {
+	var piped int64
+	return pipeImport(ctx, exec.Command("nerdctl", "save", imageID), node, &piped)
+}
+
+// pipeImport pipes the image archive save writes into ctr images import on
+// the node, counting the bytes piped into piped.
+func pipeImport(ctx context.Context, save *exec.Cmd, node string, piped *int64) error {
+	// {{runtime}} exec -i {{node}} ctr -n k8s.io images import --digests -
+	load := nodeInputCommand(node, "ctr", "-n", containerdNamespace, "images", "import", "--digests", "-")
+
+	pipe, err := save.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	load.Stdin = countingReader{r: pipe, n: piped}
+	var saveErr, loadErr bytes.Buffer
+	save.Stderr = &saveErr
+	load.Stderr = &loadErr
+
+	started := time.Now()
+	if err := save.Start(); err != nil {
+		return err
+	}
+	defer logCommand(save, started)
+	if err := runContext(ctx, load); err != nil {
+		save.Process.Kill()
+		save.Wait()
+		return fmt.Errorf("import: %w: %s", err, strings.TrimSpace(loadErr.String()))
+	}
+	if err := save.Wait(); err != nil {
+		return fmt.Errorf("%s save: %w: %s", save.Args[0], err, strings.TrimSpace(saveErr.String()))
+	}
+	return nil
+}
+
+// podmanImage is an entry of podman image ls --format json.
+type podmanImage struct {
+	ID         string   `json:"Id"`
+	Names      []string `json:"Names"`
+	Digest     string   `json:"Digest"`
+	Created    int64    `json:"Created"`
+	Size       int64    `json:"Size"`
+	Containers int      `json:"Containers"`
+}
+
+// listPodmanImages lists the local podman images in the same shape as docker
+// image ls, with one entry per name. Unlike docker, podman prints a single
+// JSON array.
+func listPodmanImages() ([]dockerImage, error) {
+	cmd := exec.Command("podman", "image", "ls", "--format", "json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, commandError("podman image ls", err, stderr.String())
+	}
+
+	var podmanImages []podmanImage
+	if err := json.Unmarshal(stdout.Bytes(), &podmanImages); err != nil {
+		return nil, &parseError{Command: "podman image ls", Failed: 1, Total: 1, Err: err}
+	}
+
+	var images []dockerImage
+	for _, p := range podmanImages {
+		created := time.Unix(p.Created, 0)
+		image := dockerImage{
+			Containers: fmt.Sprint(p.Containers),
+			CreatedAt:  created.Format(dockerTimeLayout),
+			Digest:     p.Digest,
+			ID:         shortID(p.ID),
+			Repository: "<none>",
+			Size:       formatBytes(p.Size),
+			Tag:        "<none>",
+		}
+		if len(p.Names) == 0 {
+			images = append(images, image)
+			continue
+		}
+		for _, name := range p.Names {
+			image.Repository, image.Tag = splitRepoTag(name)
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+// splitRepoTag splits repo:tag, taking care not to split on a registry port.
+func splitRepoTag(name string) (string, string) {
+	i := strings.LastIndex(name, ":")
+	if i < 0 || strings.Contains(name[i:], "/") {
+		return name, "latest"
+	}
+	return name[:i], name[i+1:]
+}
+
+// shortID truncates an image ID the way image ls does.
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return id
+}
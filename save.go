@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// defaultSavePath suggests a file in the home directory named after ref, e.g.
+// ~/nginx_latest.tar for nginx:latest.
+func defaultSavePath(ref string) string {
+	name := strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(ref)
+	return filepath.Join("~", name+".tar")
+}
+
+// checkSavePath validates the output path of a save before it is queued and
+// returns it made absolute.
+func checkSavePath(path string, overwrite bool) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("an output path is required")
+	}
+	path, err := expandPath(path)
+	if err != nil {
+		return "", fmt.Errorf("checkSavePath %s: %w", path, err)
+	}
+	if err := validatePayloadPath(path); err != nil {
+		return "", fmt.Errorf("checkSavePath: %w", err)
+	}
+	if filepath.Ext(path) != ".tar" {
+		return "", fmt.Errorf("checkSavePath %s: the output must be a .tar file", path)
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return "", fmt.Errorf("checkSavePath %s: is a directory", path)
+	case err == nil && !overwrite:
+		return "", fmt.Errorf("checkSavePath %s: file already exists, check overwrite to replace it", path)
+	case err != nil && !os.IsNotExist(err):
+		return "", fmt.Errorf("checkSavePath %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// saveImage writes the image to job.Target with docker save. The tarball is
+// written next to the target first so a failed save never leaves a partial
+// file, or clobbers the file being overwritten.
+func (i *imagePlugin) saveImage(ctx context.Context, job queuedJob) (err error) {
+	imageID, path := job.ImageID, job.Target
+
+	i.saveProgress.Start(fmt.Sprintf("Saving %s to %s", imageID, path))
+	var size int64
+	defer func() {
+		i.saveProgress.Finish(err, fmt.Sprintf("Saved %s to %s (%s) in %s", imageID, path, formatBytes(size), i.saveProgress.Elapsed()))
+	}()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("saveImage %s: %w", imageID, err)
+	}
+
+	partial := path + ".partial"
+	defer os.Remove(partial)
+
+	// docker save -o {{partial}} {{imageID}}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, host.Name(), "save", "-o", partial, imageID)
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("saveImage %s: %w", imageID, ctx.Err())
+		}
+		return fmt.Errorf("saveImage %s: %w: %s", imageID, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := os.Rename(partial, path); err != nil {
+		return fmt.Errorf("saveImage %s: %w", imageID, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("saveImage %s: %w", imageID, err)
+	}
+	size = info.Size()
+	return nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// scanImages turns on Trivy vulnerability scans of host images, set with
+// KIND_REGISTRY_SCAN_IMAGES. Scans are only offered when trivy is on the PATH.
+var scanImages bool
+
+// scanSeverities are the severities scans report.
+const scanSeverities = "HIGH,CRITICAL"
+
+// scanAvailable reports whether scans are turned on and trivy can be found.
+func scanAvailable() bool {
+	if !scanImages {
+		return false
+	}
+	_, err := exec.LookPath("trivy")
+	return err == nil
+}
+
+type trivyReport struct {
+	Results []struct {
+		Target          string               `json:"Target"`
+		Vulnerabilities []trivyVulnerability `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+type trivyVulnerability struct {
+	VulnerabilityID  string `json:"VulnerabilityID"`
+	PkgName          string `json:"PkgName"`
+	InstalledVersion string `json:"InstalledVersion"`
+	FixedVersion     string `json:"FixedVersion"`
+	Severity         string `json:"Severity"`
+	Title            string `json:"Title"`
+	// Target is the part of the image the finding is in, e.g. the OS packages.
+	Target string `json:"-"`
+}
+
+// scanResult is the outcome of scanning one image.
+type scanResult struct {
+	Scanned  time.Time
+	Findings []trivyVulnerability
+	Err      error
+}
+
+// Counts returns the number of critical and high findings.
+func (r scanResult) Counts() (int, int) {
+	var critical, high int
+	for _, finding := range r.Findings {
+		switch finding.Severity {
+		case "CRITICAL":
+			critical++
+		case "HIGH":
+			high++
+		}
+	}
+	return critical, high
+}
+
+// scanCache keeps scan results by image ID. A rebuilt image gets a new ID,
+// so results never need invalidating.
+type scanCache struct {
+	mu      sync.Mutex
+	results map[string]scanResult
+}
+
+func newScanCache() *scanCache {
+	return &scanCache{results: map[string]scanResult{}}
+}
+
+func (c *scanCache) Get(imageID string) (scanResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, ok := c.results[imageID]
+	return result, ok
+}
+
+func (c *scanCache) Set(imageID string, result scanResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results[imageID] = result
+}
+
+// scanImage runs trivy on the image and caches the findings under its ID.
+// job.ImageID is what trivy scans, a tag or the ID; job.Target is the ID.
+func (i *imagePlugin) scanImage(ctx context.Context, job queuedJob) error {
+	// trivy image --quiet --format json --severity HIGH,CRITICAL {{ref}}
+	cmd := exec.Command("trivy", "image", "--quiet", "--format", "json", "--severity", scanSeverities, job.ImageID)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runContext(ctx, cmd); err != nil {
+		// A cancelled scan is not cached, so it can simply be started again.
+		if ctx.Err() != nil {
+			return fmt.Errorf("scanImage %s: %w", job.ImageID, ctx.Err())
+		}
+		err = fmt.Errorf("scanImage %s: %w: %s", job.ImageID, err, strings.TrimSpace(stderr.String()))
+		i.scans.Set(job.Target, scanResult{Scanned: time.Now(), Err: err})
+		return err
+	}
+
+	var report trivyReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		err = fmt.Errorf("scanImage %s: could not parse trivy output: %w", job.ImageID, err)
+		i.scans.Set(job.Target, scanResult{Scanned: time.Now(), Err: err})
+		return err
+	}
+	result := scanResult{Scanned: time.Now()}
+	for _, target := range report.Results {
+		for _, finding := range target.Vulnerabilities {
+			finding.Target = target.Target
+			result.Findings = append(result.Findings, finding)
+		}
+	}
+	i.scans.Set(job.Target, result)
+	return nil
+}
+
+// addScanColumn fills the Vulnerabilities column of a host image row and
+// offers scanning the image, or scanning it again.
+func (i *imagePlugin) addScanColumn(row component.TableRow, image dockerImage) {
+	result, ok := i.scans.Get(image.ID)
+	current, pending := i.scanQueue.Snapshot()
+	queued := current != nil && current.Target == image.ID
+	for _, job := range pending {
+		queued = queued || job.Target == image.ID
+	}
+
+	switch {
+	case queued:
+		row["Vulnerabilities"] = component.NewText("Scanning…")
+	case !ok:
+		row["Vulnerabilities"] = component.NewText("Not scanned")
+	case result.Err != nil:
+		text := component.NewText("Scan failed")
+		text.SetStatus(component.TextStatusError)
+		row["Vulnerabilities"] = text
+	default:
+		critical, high := result.Counts()
+		text := component.NewTextf("%d CRITICAL / %d HIGH", critical, high)
+		switch {
+		case critical > 0:
+			text.SetStatus(component.TextStatusError)
+		case high > 0:
+			text.SetStatus(component.TextStatusWarning)
+		default:
+			text.SetStatus(component.TextStatusOK)
+		}
+		row["Vulnerabilities"] = text
+	}
+
+	if queued {
+		return
+	}
+	name := "Scan"
+	if ok {
+		name = "Scan again"
+	}
+	row.AddAction(component.GridAction{
+		Name:       name,
+		ActionPath: names.Scan,
+		Payload: action.Payload{
+			"action":  names.Scan,
+			"imageID": image.Reference(),
+			"id":      image.ID,
+		},
+		Type: component.GridActionPrimary,
+	})
+}
+
+// addScanSection shows the running scan with a button to cancel it.
+func (i *imagePlugin) addScanSection(layout *flexlayout.FlexLayout) {
+	current, pending := i.scanQueue.Snapshot()
+	if current == nil {
+		return
+	}
+	scanSection := layout.AddSection()
+	scanSection.Add(component.NewTextf("Scanning %s with trivy (%s elapsed, %d of %d queued)...",
+		current.ImageID, time.Since(current.StartedAt).Round(time.Second), len(pending), i.scanQueue.Size()), component.WidthFull)
+	layout.AddButton("Cancel scan", action.Payload{
+		"action":    names.Cancel,
+		"operation": "scan",
+		"imageID":   current.ImageID,
+	})
+}
+
+// scanFindingsView lists the findings of a scan for the image detail page.
+func scanFindingsView(result scanResult) *component.FlexLayout {
+	layout := flexlayout.New()
+	if result.Err != nil {
+		addErrorSection(layout, result.Err)
+	}
+
+	table := component.NewTable("Vulnerabilities", fmt.Sprintf("No %s vulnerabilities found", scanSeverities),
+		component.NewTableCols("Severity", "ID", "Package", "Installed", "Fixed", "Title", "Target"))
+	for _, finding := range result.Findings {
+		severity := component.NewText(finding.Severity)
+		if finding.Severity == "CRITICAL" {
+			severity.SetStatus(component.TextStatusError)
+		} else {
+			severity.SetStatus(component.TextStatusWarning)
+		}
+		table.Add(component.TableRow{
+			"Severity":  severity,
+			"ID":        component.NewText(finding.VulnerabilityID),
+			"Package":   component.NewText(finding.PkgName),
+			"Installed": component.NewText(finding.InstalledVersion),
+			"Fixed":     component.NewText(finding.FixedVersion),
+			"Title":     component.NewText(finding.Title),
+			"Target":    component.NewText(finding.Target),
+		})
+	}
+
+	summarySection := layout.AddSection()
+	summarySection.Add(component.NewTextf("Scanned %s ago with trivy.", time.Since(result.Scanned).Round(time.Second)), component.WidthFull)
+	findingsSection := layout.AddSection()
+	findingsSection.Add(table, component.WidthFull)
+	return layout.ToComponent("Vulnerabilities")
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// selfCheckTimeout bounds each command of the self-check, so a hung daemon
+// shows up as a failed check instead of a page that never renders.
+const selfCheckTimeout = 5 * time.Second
+
+// selfCheck is one prerequisite of the plugin and whether it is met.
+type selfCheck struct {
+	Name string
+	// Tool is the executable a PATH check looks for.
+	Tool string
+	// Err is why the check failed, nil when it passed.
+	Err error
+	// Skipped is set when a failed earlier check made this one pointless.
+	Skipped bool
+	Fix     string
+}
+
+// runSelfChecks checks what the plugin needs before it can list images:
+// the required tools on the PATH, the container runtime's daemon answering,
+// and the runtime being allowed to list containers.
+func runSelfChecks() []selfCheck {
+	var checks []selfCheck
+	runtimeFound := true
+	for _, tool := range requiredTools {
+		check := selfCheck{Name: tool + " on the PATH", Tool: tool, Fix: installHint(tool)}
+		if _, err := exec.LookPath(tool); err != nil {
+			check.Err = err
+			if tool == host.Name() {
+				runtimeFound = false
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	daemon := selfCheck{
+		Name: host.Name() + " daemon reachable",
+		Fix: fmt.Sprintf("Start the %s daemon, e.g. Docker Desktop or systemctl start docker, "+
+			"and check that DOCKER_HOST or the current context points at it.", host.Name()),
+		Skipped: !runtimeFound,
+	}
+	if !daemon.Skipped {
+		daemon.Err = runSelfCheck(host.Name(), "version", "--format", "{{.Server.Version}}")
+	}
+	checks = append(checks, daemon)
+
+	ps := selfCheck{
+		Name: host.Name() + " ps",
+		Fix: fmt.Sprintf("Make sure the user running Octant may use %s, e.g. is a member of the docker group, "+
+			"since the kind nodes are reached through %s exec.", host.Name(), host.Name()),
+		Skipped: !runtimeFound || daemon.Err != nil,
+	}
+	if !ps.Skipped {
+		ps.Err = runSelfCheck(host.Name(), "ps", "--quiet")
+	}
+	return append(checks, ps)
+}
+
+// installHint says where to get a required tool.
+func installHint(tool string) string {
+	switch tool {
+	case "kind":
+		return "Install kind, see https://kind.sigs.k8s.io/docs/user/quick-start/#installation, into a directory on the PATH Octant was started with."
+	case "docker":
+		return "Install docker, see https://docs.docker.com/get-docker/, into a directory on the PATH Octant was started with."
+	}
+	return fmt.Sprintf("Install %s into a directory on the PATH Octant was started with, or pick another runtime with --runtime.", tool)
+}
+
+// runSelfCheck runs a check command, failing it after selfCheckTimeout.
+func runSelfCheck(name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), selfCheckTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s %s did not answer within %s", name, args[0], selfCheckTimeout)
+		}
+		return commandError(name+" "+args[0], err, stderr.String())
+	}
+	return nil
+}
+
+// failedChecks returns the checks that failed.
+func failedChecks(checks []selfCheck) []selfCheck {
+	var failed []selfCheck
+	for _, check := range checks {
+		if check.Err != nil {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// selfCheckCache runs the self-check at most once per healthTTL, so the
+// navigation and every view can ask for it and the plugin recovers on its
+// own once the tools show up.
+type selfCheckCache struct {
+	// checking lets one run of the checks happen at a time.
+	checking   sync.Mutex
+	mu         sync.Mutex
+	checked    time.Time
+	checks     []selfCheck
+	refreshing bool
+}
+
+// Get returns the checks, running them first when the last run is more than
+// healthTTL old.
+func (c *selfCheckCache) Get() []selfCheck {
+	c.checking.Lock()
+	defer c.checking.Unlock()
+
+	c.mu.Lock()
+	if time.Since(c.checked) <= healthTTL {
+		defer c.mu.Unlock()
+		return c.checks
+	}
+	c.mu.Unlock()
+
+	checks := runSelfChecks()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks, c.checked = checks, time.Now()
+	return checks
+}
+
+// Last returns the result of the last run without waiting for one, for the
+// navigation. When it is more than healthTTL old the checks run again in
+// the background, and a later call sees their result. It is empty before
+// the first run.
+func (c *selfCheckCache) Last() []selfCheck {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked) > healthTTL && !c.refreshing {
+		c.refreshing = true
+		go func() {
+			c.Get()
+			c.mu.Lock()
+			c.refreshing = false
+			c.mu.Unlock()
+		}()
+	}
+	return c.checks
+}
+
+// Missing returns the required tools not found on the PATH.
+func (c *selfCheckCache) Missing() []string {
+	var missing []string
+	for _, check := range c.Get() {
+		if check.Tool != "" && check.Err != nil {
+			missing = append(missing, check.Tool)
+		}
+	}
+	return missing
+}
+
+// Reset makes the next Get check again, e.g. when a refresh is asked for.
+func (c *selfCheckCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checked = time.Time{}
+}
+
+// selfCheckView explains which prerequisite is missing and how to fix it.
+// It takes the place of the image views until every check passes.
+func selfCheckView(checks []selfCheck) *component.FlexLayout {
+	layout := flexlayout.New()
+	text := component.NewTextf("The plugin can't list images until the failed checks below pass. "+
+		"They run again every %s, so this page turns into the image views once they do, without restarting Octant.", healthTTL)
+	text.SetStatus(component.TextStatusError)
+	section := layout.AddSection()
+	section.Add(text, component.WidthFull)
+
+	table := component.NewTable("Prerequisites", "No checks run", component.NewTableCols("Check", "Status", "How to fix"))
+	for _, check := range checks {
+		status := component.NewText("OK")
+		status.SetStatus(component.TextStatusOK)
+		fix := "—"
+		switch {
+		case check.Skipped:
+			status = component.NewText("Not checked, an earlier check failed")
+		case check.Err != nil:
+			status = component.NewTextf("Failed: %s", check.Err)
+			status.SetStatus(component.TextStatusError)
+			fix = check.Fix
+		}
+		table.Add(component.TableRow{
+			"Check":      component.NewText(check.Name),
+			"Status":     status,
+			"How to fix": component.NewText(fix),
+		})
+	}
+	tableSection := layout.AddSection()
+	tableSection.Add(table, component.WidthFull)
+
+	view := layout.ToComponent("Prerequisites")
+	view.SetAccessor("prerequisites")
+	return view
+}
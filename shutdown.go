@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownContext is the parent of every queued operation's context. It is
+// cancelled when the plugin stops, so the commands they run are killed
+// rather than left running in their own process groups after Octant exits.
+var shutdownContext, cancelShutdown = context.WithCancel(context.Background())
+
+// shuttingDown reports whether the plugin is stopping, so an operation
+// cancelled now was cancelled by the shutdown rather than a Stop button.
+func shuttingDown() bool {
+	return shutdownContext.Err() != nil
+}
+
+// shutdownTimeout bounds how long stopping waits for cancelled operations.
+const shutdownTimeout = 5 * time.Second
+
+// jobQueues are all the queues with workers that run commands.
+func (i *imagePlugin) jobQueues() []*jobQueue {
+	return []*jobQueue{i.queue, i.pushes, i.saves, i.copies, i.pulls, i.gets, i.scanQueue, i.clusterOps, i.cleanups, i.maintenance}
+}
+
+// shutdown cancels the running operations, drops the queued ones and waits
+// up to shutdownTimeout for the workers to finish killing their commands.
+func (i *imagePlugin) shutdown() {
+	cancelShutdown()
+	i.api.Close()
+	queues := i.jobQueues()
+	for _, q := range queues {
+		q.Stop()
+	}
+
+	deadline := time.Now().Add(shutdownTimeout)
+	for _, q := range queues {
+		for q.Busy() {
+			if time.Now().After(deadline) {
+				log.Printf("%s still running after %s, exiting anyway", q.name, shutdownTimeout)
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// stopOnSignal shuts down and exits on SIGTERM. Octant stops plugins by
+// closing their connection, which ends Serve, but a plain kill would
+// otherwise skip the cleanup.
+func (i *imagePlugin) stopOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM)
+	<-signals
+	log.Printf("received SIGTERM, stopping running operations")
+	i.shutdown()
+	os.Exit(0)
+}
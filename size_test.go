@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{1<<20 - 1, "1.0 MiB"},
+		{1 << 20, "1.0 MiB"},
+		{1000 * 1000 * 1000, "953.7 MiB"},
+		{1<<30 - 1, "1.0 GiB"},
+		{1 << 30, "1.0 GiB"},
+		{3 << 29, "1.5 GiB"},
+		{1 << 40, "1.0 TiB"},
+		{5 << 40, "5.0 TiB"},
+	}
+	for _, test := range tests {
+		if got := formatBytes(test.n); got != test.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", test.n, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// staleDays is how old, in days, an image without containers must be to be
+// suggested for cleanup, set with KIND_REGISTRY_STALE_DAYS.
+var staleDays = 30
+
+// staleBefore is the creation time stale images are older than.
+func staleBefore() time.Time {
+	return time.Now().AddDate(0, 0, -staleDays)
+}
+
+// listHostContainerUsage returns when the newest container of each host
+// image was created, keyed by short image ID, as an estimate of when the
+// image was last used. Removed containers leave no trace.
+func listHostContainerUsage() (map[string]time.Time, error) {
+	// docker ps --all --quiet --no-trunc
+	cmd := exec.Command(host.Name(), "ps", "--all", "--quiet", "--no-trunc")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, commandError(host.Name()+" ps", err, stderr.String())
+	}
+	ids := strings.Fields(stdout.String())
+	usage := map[string]time.Time{}
+	if len(ids) == 0 {
+		return usage, nil
+	}
+
+	// docker container inspect --format '{{.Image}} {{.Created}}' {{ids}}
+	cmd = exec.Command(host.Name(), append([]string{"container", "inspect", "--format", "{{.Image}} {{.Created}}"}, ids...)...)
+	stdout.Reset()
+	stderr.Reset()
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(cmd); err != nil {
+		return nil, commandError(host.Name()+" container inspect", err, stderr.String())
+	}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		id := shortID(fields[0])
+		created, err := time.Parse(time.RFC3339Nano, fields[1])
+		if err != nil {
+			// A container counts as a use even when its time is unreadable.
+			if _, ok := usage[id]; !ok {
+				usage[id] = time.Time{}
+			}
+			continue
+		}
+		if created.After(usage[id]) {
+			usage[id] = created
+		}
+	}
+	return usage, nil
+}
+
+// hostUsageCache keeps the host container usage for healthTTL.
+type hostUsageCache struct {
+	mu      sync.Mutex
+	checked time.Time
+	usage   map[string]time.Time
+	err     error
+}
+
+// Get returns the cached container usage, listing it when it is stale.
+func (c *hostUsageCache) Get() (map[string]time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked) > healthTTL {
+		c.checked = time.Now()
+		c.usage, c.err = listHostContainerUsage()
+	}
+	return c.usage, c.err
+}
+
+// Reset drops the cached usage, e.g. after images or containers were removed.
+func (c *hostUsageCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checked = time.Time{}
+}
+
+// staleHostImages returns the host images created before the given time that
+// no container was created from. Images whose creation time is unknown are
+// never stale.
+func staleHostImages(images []dockerImage, usage map[string]time.Time, before time.Time) []dockerImage {
+	var stale []dockerImage
+	for _, image := range images {
+		created, err := image.Created()
+		if err != nil || !created.Before(before) {
+			continue
+		}
+		if _, used := usage[shortID(image.ID)]; used {
+			continue
+		}
+		stale = append(stale, image)
+	}
+	return stale
+}
+
+// kindLastUsed returns when the newest container of each kind image was
+// created, keyed like imageConsumers.
+func kindLastUsed(consumers map[string][]kindContainer) map[string]time.Time {
+	lastUsed := map[string]time.Time{}
+	for id, containers := range consumers {
+		for _, c := range containers {
+			if created := c.Created(); created.After(lastUsed[id]) {
+				lastUsed[id] = created
+			}
+		}
+	}
+	return lastUsed
+}
+
+// staleKindImages returns the kind images created before the given time that
+// no container references in any state, leaving out the ones
+// deleteUnusedImages keeps anyway.
+func staleKindImages(images []kindImage, specs map[string]imageSpec, consumers map[string][]kindContainer, before time.Time) []kindImage {
+	var stale []kindImage
+	for _, image := range images {
+		if image.Pinned || len(consumers[image.ID]) > 0 || usedByTag(image, consumers) || isSystem(image) {
+			continue
+		}
+		if created := specs[image.ID].Created; created.IsZero() || !created.Before(before) {
+			continue
+		}
+		stale = append(stale, image)
+	}
+	return stale
+}
+
+// kindImageLastUsed returns when a container last used image, by its ID or
+// one of its tags, and whether any container did.
+func kindImageLastUsed(image kindImage, consumers map[string][]kindContainer, lastUsed map[string]time.Time) (time.Time, bool) {
+	used := len(consumers[image.ID]) > 0 || usedByTag(image, consumers)
+	newest := lastUsed[image.ID]
+	for _, repoTag := range image.RepoTags {
+		if lastUsed[repoTag].After(newest) {
+			newest = lastUsed[repoTag]
+		}
+	}
+	return newest, used
+}
+
+// kindImagesSize sums the sizes crictl reports for images.
+func kindImagesSize(images []kindImage) int64 {
+	var total int64
+	for _, image := range images {
+		size, _ := strconv.ParseInt(image.Size, 10, 64)
+		total += size
+	}
+	return total
+}
+
+// lastUsedCell renders when an image was last used by a container.
+func lastUsedCell(lastUsed time.Time, used bool) component.Component {
+	switch {
+	case !used:
+		return component.NewText("no containers")
+	case lastUsed.IsZero():
+		return component.NewText("unknown")
+	default:
+		return component.NewTimestamp(lastUsed)
+	}
+}
+
+// hostLastUsedCell renders when a container was last created from a host
+// image, or "unknown" when the containers couldn't be listed.
+func hostLastUsedCell(image dockerImage, usage map[string]time.Time) component.Component {
+	if usage == nil {
+		return component.NewText("unknown")
+	}
+	lastUsed, used := usage[shortID(image.ID)]
+	return lastUsedCell(lastUsed, used)
+}
+
+// staleFilter remembers which tables show only their stale images.
+type staleFilter struct {
+	mu   sync.Mutex
+	only map[string]bool
+}
+
+// Toggle switches table between all and only stale images.
+func (f *staleFilter) Toggle(table string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.only == nil {
+		f.only = map[string]bool{}
+	}
+	f.only[table] = !f.only[table]
+}
+
+// Only reports whether table shows only stale images.
+func (f *staleFilter) Only(table string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.only[table]
+}
+
+// addStaleSection sums up the stale images of a table, with buttons to show
+// only them and to delete them all.
+func addStaleSection(layout *flexlayout.FlexLayout, table string, count int, size int64, only bool) {
+	if count == 0 && !only {
+		return
+	}
+	section := layout.AddSection()
+	section.Add(component.NewTextf("%d stale image(s), %s reclaimable: created more than %d days ago and used by no container.",
+		count, formatBytes(size), staleDays), component.WidthFull)
+
+	label := "Show only stale images"
+	if only {
+		label = "Show all images"
+	}
+	layout.AddButton(label, action.Payload{
+		"action": names.StaleOnly,
+		"table":  table,
+	})
+	if count > 0 {
+		layout.AddButton("Delete stale images", action.Payload{
+			"action": names.PruneStale,
+			"table":  table,
+		}, component.WithButtonConfirmation("Delete stale images?",
+			fmt.Sprintf("This deletes the %d image(s) created more than %d days ago that no container uses, reclaiming up to %s. "+
+				"Do you want to continue?", count, staleDays, formatBytes(size))))
+	}
+}
+
+// deleteStaleHostImages removes the stale host images with all their tags.
+func (i *imagePlugin) deleteStaleHostImages(ctx context.Context) (err error) {
+	i.maintProgress.Start(fmt.Sprintf("Deleting stale %s images", host.Name()))
+	var deleted int
+	var reclaimed int64
+	defer func() {
+		success := fmt.Sprintf("Deleted %d stale image(s), reclaiming %s", deleted, formatBytes(reclaimed))
+		if dryRun {
+			success = fmt.Sprintf("Dry run: would have deleted %d stale image(s), reclaiming %s", deleted, formatBytes(reclaimed))
+		}
+		i.maintProgress.Finish(err, success)
+		i.hostUsage.Reset()
+		i.inspects.Reset()
+	}()
+
+	images, err := listDockerImages()
+	if err != nil {
+		return fmt.Errorf("deleteStaleHostImages: %w", err)
+	}
+	// Checked again, since containers may have been created since the
+	// images were listed for the page.
+	i.hostUsage.Reset()
+	usage, err := i.hostUsage.Get()
+	if err != nil {
+		return fmt.Errorf("deleteStaleHostImages: %w", err)
+	}
+	grouped, tags := groupByID(images)
+	var failed []string
+	for _, image := range staleHostImages(grouped, usage, staleBefore()) {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("deleteStaleHostImages: stopped after deleting %d image(s): %w", deleted, err)
+		}
+		refs := tags[image.ID]
+		if len(refs) == 0 {
+			refs = []string{image.ID}
+		}
+		// docker image rm {{refs}}
+		cmd := exec.Command(host.Name(), append([]string{"image", "rm"}, refs...)...)
+		if dryRun {
+			log.Printf("dry run: %s", commandLine(cmd))
+			deleted++
+			reclaimed += sizeOf(image.Size)
+			continue
+		}
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := runContext(ctx, cmd); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("deleteStaleHostImages: stopped after deleting %d image(s): %w", deleted, ctx.Err())
+			}
+			log.Printf("unable to delete %s: %s", image.ID, strings.TrimSpace(stderr.String()))
+			failed = append(failed, image.ID)
+			continue
+		}
+		i.maintProgress.Write(fmt.Sprintf("Deleted %s", strings.Join(refs, ", ")))
+		deleted++
+		reclaimed += sizeOf(image.Size)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("deleteStaleHostImages: deleted %d image(s), reclaiming %s, but could not delete %s",
+			deleted, formatBytes(reclaimed), strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	// stateFile is where the UI state is kept between Octant sessions, set
+	// with KIND_REGISTRY_STATE_FILE. Empty disables saving it.
+	stateFile = defaultStateFile()
+
+	// persistState turns the state file off entirely with
+	// KIND_REGISTRY_PERSIST_STATE=false, so no file is read or written.
+	persistState = true
+)
+
+// stateSaveDelay is how long saves wait for further changes, so clicking
+// through toggles writes the file once.
+const stateSaveDelay = time.Second
+
+// statePath is the state file in use, or empty when state is not persisted.
+func statePath() string {
+	if !persistState {
+		return ""
+	}
+	return stateFile
+}
+
+func defaultStateFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "octant-kind-registry", "state.json")
+}
+
+// uiState is the interactive state remembered across sessions. Fields are
+// pointers so a state file written by an older version leaves new settings
+// at their configured defaults.
+type uiState struct {
+	HideSystemImages *bool   `json:"hideSystemImages,omitempty"`
+	LabelFilter      *string `json:"labelFilter,omitempty"`
+	ComposeProject   *string `json:"composeProject,omitempty"`
+	ShowCommands     *bool   `json:"showCommands,omitempty"`
+	AutoSync         *bool   `json:"autoSync,omitempty"`
+	// SortOrders maps table names to the order they are sorted in.
+	SortOrders map[string]string `json:"sortOrders,omitempty"`
+	// LoadTimes are the most recent load timings.
+	LoadTimes []loadSample `json:"loadTimes,omitempty"`
+}
+
+// loadState restores the state saved by the last session. A missing or
+// unreadable file leaves the configured defaults in place.
+func (i *imagePlugin) loadState() {
+	stateFile := statePath()
+	if stateFile == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(stateFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("unable to read UI state: %s", err)
+		}
+		return
+	}
+
+	var state uiState
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Printf("unable to parse UI state %s: %s", stateFile, err)
+		return
+	}
+	if state.HideSystemImages != nil && *state.HideSystemImages != i.systemImages.Hidden() {
+		i.systemImages.Toggle()
+	}
+	if state.ShowCommands != nil && *state.ShowCommands != i.commands.Shown() {
+		i.commands.Toggle()
+	}
+	if enabled, _, _ := i.autoSync.Status(); state.AutoSync != nil && *state.AutoSync != enabled {
+		i.autoSync.Toggle()
+	}
+	i.loadTimes.Set(state.LoadTimes)
+	for table, order := range state.SortOrders {
+		if err := i.sorts.Set(table, order); err != nil {
+			log.Printf("ignoring saved sort order of %s: %s", table, err)
+		}
+	}
+	if state.LabelFilter != nil {
+		if err := i.labels.Set(*state.LabelFilter); err != nil {
+			log.Printf("ignoring saved label filter: %s", err)
+		}
+	}
+	if state.ComposeProject != nil {
+		i.projects.Set(*state.ComposeProject)
+	}
+}
+
+// saveState writes the current state, once no further change followed it
+// for stateSaveDelay, so the next session starts with it.
+func (i *imagePlugin) saveState() {
+	if statePath() == "" {
+		return
+	}
+	i.stateSaver.Schedule(i.writeState)
+}
+
+// writeState writes the current state file. Failures are only logged and
+// shown on the Environment tab; the plugin works the same without the file.
+func (i *imagePlugin) writeState() error {
+	stateFile := statePath()
+	hidden := i.systemImages.Hidden()
+	selector := i.labels.Get()
+	project := i.projects.Get()
+	commands := i.commands.Shown()
+	syncing, _, _ := i.autoSync.Status()
+	state := uiState{HideSystemImages: &hidden, LabelFilter: &selector, ComposeProject: &project, ShowCommands: &commands, AutoSync: &syncing, SortOrders: i.sorts.All(), LoadTimes: i.loadTimes.List()}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode UI state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(stateFile), 0755); err != nil {
+		return fmt.Errorf("save UI state: %w", err)
+	}
+	// Written to a temporary file first so a crash never leaves half a file.
+	tmp := stateFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("save UI state: %w", err)
+	}
+	if err := os.Rename(tmp, stateFile); err != nil {
+		return fmt.Errorf("save UI state: %w", err)
+	}
+	return nil
+}
+
+// stateSaver debounces state writes and remembers how the last one went.
+type stateSaver struct {
+	mu    sync.Mutex
+	timer *time.Timer
+	saved time.Time
+	err   error
+}
+
+// Schedule runs write after stateSaveDelay, restarting the delay when a
+// write is already waiting.
+func (s *stateSaver) Schedule(write func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(stateSaveDelay, func() {
+		err := write()
+		if err != nil {
+			log.Printf("unable to save UI state: %s", err)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.saved, s.err = time.Now(), err
+	})
+}
+
+// Status describes the state file for the Environment tab.
+func (s *stateSaver) Status() string {
+	path := statePath()
+	if path == "" {
+		return "Not persisted"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.err != nil:
+		return fmt.Sprintf("%s (last save failed: %s)", path, s.err)
+	case s.saved.IsZero():
+		return path
+	default:
+		return fmt.Sprintf("%s (saved %s)", path, s.saved.Format(time.Kitchen))
+	}
+}
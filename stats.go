@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+// sessionStats counts what the plugin did since Octant started. The counters
+// are updated from the queue workers and action handlers concurrently.
+type sessionStats struct {
+	loads          int64
+	loadFailures   int64
+	deletes        int64
+	deleteFailures int64
+	bytesLoaded    int64
+	autoLoads      int64
+}
+
+// RecordLoad is a jobQueue Finished hook counting kind loads.
+func (s *sessionStats) RecordLoad(job queuedJob, err error) {
+	if err != nil {
+		atomic.AddInt64(&s.loadFailures, 1)
+		return
+	}
+	atomic.AddInt64(&s.loads, 1)
+	if job.Auto {
+		atomic.AddInt64(&s.autoLoads, 1)
+	}
+}
+
+// AddBytes counts bytes copied into kind nodes.
+func (s *sessionStats) AddBytes(n int64) {
+	atomic.AddInt64(&s.bytesLoaded, n)
+}
+
+// RecordDelete counts a delete of a kind or host image.
+func (s *sessionStats) RecordDelete(err error) {
+	if err != nil {
+		atomic.AddInt64(&s.deleteFailures, 1)
+		return
+	}
+	atomic.AddInt64(&s.deletes, 1)
+}
+
+// Summary renders the counters.
+func (s *sessionStats) Summary() *component.Summary {
+	count := func(n *int64) component.Component {
+		return component.NewText(strconv.FormatInt(atomic.LoadInt64(n), 10))
+	}
+	failures := func(n *int64) component.Component {
+		text := component.NewText(strconv.FormatInt(atomic.LoadInt64(n), 10))
+		if atomic.LoadInt64(n) > 0 {
+			text.SetStatus(component.TextStatusError)
+		}
+		return text
+	}
+
+	summary := component.NewSummary("This Session")
+	summary.AddSection("Loads", count(&s.loads))
+	summary.AddSection("Auto-sync loads", count(&s.autoLoads))
+	summary.AddSection("Failed loads", failures(&s.loadFailures))
+	summary.AddSection("Loaded into nodes", component.NewText(formatBytes(atomic.LoadInt64(&s.bytesLoaded))))
+	summary.AddSection("Deletes", count(&s.deletes))
+	summary.AddSection("Failed deletes", failures(&s.deleteFailures))
+	return summary
+}
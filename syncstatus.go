@@ -0,0 +1,342 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const syncStatusPath = "sync-status"
+
+// navCounts puts the number of images pending sync in the navigation titles.
+// KIND_REGISTRY_NAV_COUNTS=false leaves the titles plain for those who find
+// the changing counts noisy.
+var navCounts = true
+
+// The image sets of the sync status page its bulk actions work on.
+const (
+	syncMissing  = "missing"
+	syncOrphaned = "orphaned"
+	syncStale    = "stale"
+)
+
+// kindTag is one tag of a kind image.
+type kindTag struct {
+	Image   kindImage
+	RepoTag string
+}
+
+// staleTag is a tag both sides have, but as different images.
+type staleTag struct {
+	Host dockerImage
+	Kind kindImage
+}
+
+// syncDiff is what it takes to make kind match the host images.
+type syncDiff struct {
+	// OnlyHost are the tagged host images kind doesn't have.
+	OnlyHost []dockerImage
+	// OnlyKind are the kind tags the host doesn't have.
+	OnlyKind []kindTag
+	// Stale are the tags whose kind image isn't the host's, so the host
+	// image was rebuilt since it was loaded.
+	Stale []staleTag
+}
+
+// referenceRepository returns the normalized repository of a reference,
+// without its tag or digest, e.g. docker.io/library/nginx for nginx:1.25.
+func referenceRepository(ref string) string {
+	normalized := normalizeReference(ref)
+	if i := strings.Index(normalized, "@"); i >= 0 {
+		return normalized[:i]
+	}
+	if i := strings.LastIndex(normalized, ":"); i > strings.LastIndex(normalized, "/") {
+		return normalized[:i]
+	}
+	return normalized
+}
+
+// inRepository reports whether ref is in repository, or true when no
+// repository is given. nginx and docker.io/library/nginx are the same.
+func inRepository(ref, repository string) bool {
+	return repository == "" || referenceRepository(ref) == referenceRepository(repository)
+}
+
+// sameImage reports whether a host image and a kind image with the same tag
+// are the same image, and false for known when that can't be told. The
+// digest docker lists matches the kind image's repo digest, or its ID when
+// kind lists manifest digests with ctr; otherwise the image IDs, which may
+// be truncated, are compared. ctr IDs can't be compared with docker IDs.
+func sameImage(host dockerImage, kind kindImage, backend string) (same, known bool) {
+	digest := host.Digest
+	if digest == "<none>" {
+		digest = ""
+	}
+	if digest != "" {
+		if sameImageID(kind.ID, digest) {
+			return true, true
+		}
+		for _, repoDigest := range kind.RepoDigests {
+			if strings.HasSuffix(repoDigest, "@"+digest) {
+				return true, true
+			}
+		}
+	}
+	if backend == backendCtr {
+		return false, digest != ""
+	}
+	return sameImageID(kind.ID, host.ID), true
+}
+
+// diffImageSets compares the host and kind images of repository, or of
+// every repository when it is empty, by normalized repo:tag. Untagged
+// images on either side have nothing to match on and are left out, as are
+// system images while they are hidden. Tags whose images can't be compared
+// count as in sync.
+func diffImageSets(images []dockerImage, loaded kindImages, repository string, hideSystem bool) syncDiff {
+	shown := func(ref string) bool {
+		return inRepository(ref, repository) && !(hideSystem && isSystemImage(ref))
+	}
+
+	kindTags := map[string]kindImage{}
+	var kindOrder []kindTag
+	for _, image := range loaded.Images {
+		for _, repoTag := range image.RepoTags {
+			if !shown(repoTag) {
+				continue
+			}
+			ref := normalizeReference(repoTag)
+			if _, ok := kindTags[ref]; !ok {
+				kindOrder = append(kindOrder, kindTag{Image: image, RepoTag: repoTag})
+			}
+			kindTags[ref] = image
+		}
+	}
+
+	var diff syncDiff
+	hostTags := map[string]bool{}
+	for _, image := range images {
+		ref := image.Reference()
+		if ref == image.ID || !shown(ref) || hostTags[normalizeReference(ref)] {
+			continue
+		}
+		hostTags[normalizeReference(ref)] = true
+		loadedImage, ok := kindTags[normalizeReference(ref)]
+		if !ok {
+			diff.OnlyHost = append(diff.OnlyHost, image)
+			continue
+		}
+		if same, known := sameImage(image, loadedImage, loaded.Backend); known && !same {
+			diff.Stale = append(diff.Stale, staleTag{Host: image, Kind: loadedImage})
+		}
+	}
+	for _, tag := range kindOrder {
+		if !hostTags[normalizeReference(tag.RepoTag)] {
+			diff.OnlyKind = append(diff.OnlyKind, tag)
+		}
+	}
+	return diff
+}
+
+// syncDiff lists both sides and compares them, with the system images
+// hidden like in the tables.
+func (i *imagePlugin) syncDiff(repository string) (syncDiff, error) {
+	images, err := listDockerImages()
+	if err != nil {
+		return syncDiff{}, err
+	}
+	nodes, err := listKindNodes()
+	if err != nil || len(nodes) == 0 {
+		nodes = []string{kindNode}
+	}
+	loaded := i.nodeImages.List(nodes)
+	// Without the kind listing every image would look missing.
+	if len(loaded.Failed) == len(nodes) {
+		return syncDiff{}, fmt.Errorf("unable to list kind images: %s", strings.Join(loaded.FailedNodes(), "; "))
+	}
+	return diffImageSets(images, loaded.kindImages, repository, i.systemImages.Hidden()), nil
+}
+
+// pendingSync counts the tagged host images kind doesn't have and the tags
+// whose host image was rebuilt since it was loaded, from the listings the
+// last render or refresh cached. It never lists images itself, since Octant
+// asks for the navigation on every page load; false means either side was
+// not listed yet.
+func (i *imagePlugin) pendingSync() (missing, stale int, ok bool) {
+	images, checked := lastHostListing.Get()
+	loaded := i.nodeImages.Cached()
+	if checked.IsZero() || loaded.Backend == "" {
+		return 0, 0, false
+	}
+	diff := diffImageSets(images, loaded.kindImages, "", i.systemImages.Hidden())
+	return len(diff.OnlyHost), len(diff.Stale), true
+}
+
+// syncImages runs the bulk action of one set of the sync status page. The
+// sets are computed again rather than taken from the page, which may be
+// stale by now.
+func (i *imagePlugin) syncImages(set, repository string) error {
+	diff, err := i.syncDiff(repository)
+	if err != nil {
+		return fmt.Errorf("syncImages: %w", err)
+	}
+	var refs []string
+	switch set {
+	case syncMissing:
+		for _, image := range diff.OnlyHost {
+			refs = append(refs, image.Reference())
+		}
+	case syncStale:
+		for _, tag := range diff.Stale {
+			refs = append(refs, tag.Host.Reference())
+		}
+	case syncOrphaned:
+		for _, tag := range diff.OnlyKind {
+			refs = append(refs, deleteTarget(tag.Image, tag.RepoTag))
+		}
+		if len(refs) == 0 {
+			return fmt.Errorf("kind has no images the host doesn't")
+		}
+		// Pinned and in-use images are skipped by the bulk delete.
+		return i.queueMaintenance(queuedJob{
+			ImageID: fmt.Sprintf("Deleting %d kind image(s) missing from %s", len(refs), host.Name()),
+			Target:  bulkDeleteJob,
+			Batch:   refs,
+		})
+	default:
+		return fmt.Errorf("unknown image set %q", set)
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("no %s images to load", set)
+	}
+	queued := 0
+	for _, ref := range refs {
+		if err := i.queue.Enqueue(queuedJob{ImageID: ref}); err != nil {
+			log.Printf("not queueing %s: %s", ref, err)
+			continue
+		}
+		queued++
+	}
+	log.Printf("queued %d of %d %s image(s) for loading into kind", queued, len(refs), set)
+	return nil
+}
+
+func (i *imagePlugin) handleSyncStatus(request service.Request) (component.ContentResponse, error) {
+	// The repository is only compared with listed references.
+	repository := ""
+	if strings.HasPrefix(strings.TrimPrefix(request.Path(), "/"), syncStatusPath+"/") {
+		var err error
+		if repository, err = routeValue(request, syncStatusPath); err != nil {
+			return component.ContentResponse{}, err
+		}
+	}
+	title := "Sync Status"
+	if repository != "" {
+		title = fmt.Sprintf("Sync Status of %s", repository)
+	}
+	contentResponse := component.NewContentResponse(component.TitleFromString(title))
+	contentResponse.Add(i.syncStatusView(repository))
+	return *contentResponse, nil
+}
+
+// syncStatusView shows what loading and deleting it takes to make kind match
+// the host images.
+func (i *imagePlugin) syncStatusView(repository string) *component.FlexLayout {
+	layout := flexlayout.New()
+	i.addMissingToolsSection(layout)
+	addDryRunSection(layout)
+	if err := i.feedback.Get(); err != nil {
+		addErrorSection(layout, err)
+	}
+
+	onlyHost := component.NewTable("Only in "+host.Title(), "Every tagged image is loaded into kind",
+		component.NewTableCols("Reference", "Image ID", "Created", "Size"))
+	onlyKind := component.NewTable("Only in Kind", "Kind has no images the host doesn't",
+		component.NewTableCols("Image", "Image ID", "Size"))
+	stale := component.NewTable("Different Image", "Every loaded tag is the host's image",
+		component.NewTableCols("Reference", host.Title()+" Image ID", "Kind Image ID"))
+
+	text := fmt.Sprintf("Comparing the tagged images of %s and kind cluster %s by repo:tag.", host.Name(), kindCluster)
+	if i.systemImages.Hidden() {
+		text += " System images are hidden, like in the kind table."
+	}
+	infoSection := layout.AddSection()
+	infoSection.Add(component.NewText(text), component.WidthFull)
+	if repository != "" {
+		infoSection.Add(component.NewLink("", "All repositories", pluginPath(syncStatusPath)), component.WidthFull)
+	}
+
+	if i.hasTool(host.Name()) {
+		diff, err := i.syncDiff(repository)
+		if err != nil {
+			addErrorSection(layout, err)
+		}
+		for _, image := range diff.OnlyHost {
+			row := component.TableRow{
+				"Reference": component.NewText(image.Reference()),
+				"Image ID":  imageIDLink(dockerImagePath, image.ID),
+				"Size":      component.NewText(displaySize(image.Size)),
+			}
+			if created, err := image.Created(); err == nil {
+				row["Created"] = component.NewTimestamp(created)
+			} else {
+				row["Created"] = component.NewText(image.CreatedSince)
+			}
+			onlyHost.Add(row)
+		}
+		for _, tag := range diff.OnlyKind {
+			name := tag.RepoTag
+			if tag.Image.Pinned {
+				name += " (pinned)"
+			}
+			onlyKind.Add(component.TableRow{
+				"Image":    component.NewText(name),
+				"Image ID": imageIDLink(kindImagePath, tag.Image.ID),
+				"Size":     component.NewText(displaySize(tag.Image.Size)),
+			})
+		}
+		for _, tag := range diff.Stale {
+			stale.Add(component.TableRow{
+				"Reference":                component.NewText(tag.Host.Reference()),
+				host.Title() + " Image ID": imageIDLink(dockerImagePath, tag.Host.ID),
+				"Kind Image ID":            imageIDLink(kindImagePath, tag.Kind.ID),
+			})
+		}
+		addSyncButton(layout, syncMissing, repository, len(diff.OnlyHost), fmt.Sprintf("Load all %d missing", len(diff.OnlyHost)),
+			fmt.Sprintf("This queues the %d image(s) only in %s for loading into kind. Do you want to continue?", len(diff.OnlyHost), host.Name()))
+		addSyncButton(layout, syncOrphaned, repository, len(diff.OnlyKind), fmt.Sprintf("Delete all %d orphaned", len(diff.OnlyKind)),
+			fmt.Sprintf("This deletes the %d kind image(s) %s doesn't have from every node. Pinned and in-use images are skipped. "+
+				"Do you want to continue?", len(diff.OnlyKind), host.Name()))
+		addSyncButton(layout, syncStale, repository, len(diff.Stale), fmt.Sprintf("Reload all %d stale", len(diff.Stale)),
+			fmt.Sprintf("This queues the %d tag(s) whose kind image differs from %s's for loading again. Do you want to continue?", len(diff.Stale), host.Name()))
+	}
+
+	addStatusSection(layout, i.progress)
+	i.addMaintenanceSection(layout)
+	for _, table := range []*component.Table{onlyHost, onlyKind, stale} {
+		section := layout.AddSection()
+		section.Add(table, component.WidthFull)
+	}
+	addBulkDeleteReportSection(layout, i.bulkDeletes.Get())
+
+	view := layout.ToComponent("Sync Status")
+	view.SetAccessor(syncStatusPath)
+	return view
+}
+
+// addSyncButton offers the bulk action of one image set, when it has images.
+func addSyncButton(layout *flexlayout.FlexLayout, set, repository string, count int, label, confirmation string) {
+	if count == 0 {
+		return
+	}
+	layout.AddButton(label, action.Payload{
+		"action":     names.Sync,
+		"set":        set,
+		"repository": repository,
+	}, component.WithButtonConfirmation("Sync kind?", confirmation))
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testID returns a full sha256 image ID made of c.
+func testID(c string) string {
+	return "sha256:" + strings.Repeat(c, 64)
+}
+
+func TestSameImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    dockerImage
+		kind    kindImage
+		backend string
+		same    bool
+		known   bool
+	}{
+		{
+			name:    "same full ID",
+			host:    dockerImage{ID: testID("a")},
+			kind:    kindImage{ID: testID("a")},
+			backend: backendCrictl,
+			same:    true, known: true,
+		},
+		{
+			name:    "truncated host ID",
+			host:    dockerImage{ID: "aaaaaaaaaaaa"},
+			kind:    kindImage{ID: testID("a")},
+			backend: backendCrictl,
+			same:    true, known: true,
+		},
+		{
+			name:    "different ID",
+			host:    dockerImage{ID: "bbbbbbbbbbbb"},
+			kind:    kindImage{ID: testID("a")},
+			backend: backendCrictl,
+			same:    false, known: true,
+		},
+		{
+			name:    "digest matches a repo digest",
+			host:    dockerImage{ID: testID("b"), Digest: testID("d")},
+			kind:    kindImage{ID: testID("a"), RepoDigests: []string{"docker.io/library/db@" + testID("d")}},
+			backend: backendCrictl,
+			same:    true, known: true,
+		},
+		{
+			name:    "<none> digest falls back to the ID",
+			host:    dockerImage{ID: testID("a"), Digest: "<none>"},
+			kind:    kindImage{ID: testID("a"), RepoDigests: []string{"docker.io/library/db@" + testID("d")}},
+			backend: backendCrictl,
+			same:    true, known: true,
+		},
+		{
+			name:    "ctr manifest digest",
+			host:    dockerImage{ID: testID("b"), Digest: testID("d")},
+			kind:    kindImage{ID: testID("d")},
+			backend: backendCtr,
+			same:    true, known: true,
+		},
+		{
+			name:    "ctr other manifest digest",
+			host:    dockerImage{ID: testID("b"), Digest: testID("d")},
+			kind:    kindImage{ID: testID("e")},
+			backend: backendCtr,
+			same:    false, known: true,
+		},
+		{
+			name:    "ctr without a host digest",
+			host:    dockerImage{ID: testID("d")},
+			kind:    kindImage{ID: testID("d")},
+			backend: backendCtr,
+			same:    false, known: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			same, known := sameImage(test.host, test.kind, test.backend)
+			if same != test.same || known != test.known {
+				t.Errorf("sameImage = %t, %t, want %t, %t", same, known, test.same, test.known)
+			}
+		})
+	}
+}
+
+// syncFixture is a host and a kind listing with one tag of every kind.
+func syncFixture() ([]dockerImage, kindImages) {
+	images := []dockerImage{
+		{Repository: "app", Tag: "v1", ID: "aaaaaaaaaaaa"},
+		{Repository: "web", Tag: "latest", ID: "bbbbbbbbbbbb"},
+		{Repository: "db", Tag: "15", ID: "cccccccccccc", Digest: testID("d")},
+		{Repository: "only", Tag: "1", ID: "eeeeeeeeeeee"},
+		{Repository: "<none>", Tag: "<none>", ID: "ffffffffffff"},
+		{Repository: "registry.k8s.io/pause", Tag: "3.9", ID: "111111111111"},
+	}
+	loaded := kindImages{
+		Backend: backendCrictl,
+		Images: []kindImage{
+			{ID: testID("a"), RepoTags: []string{"docker.io/library/app:v1"}},
+			{ID: testID("9"), RepoTags: []string{"docker.io/library/web:latest"}},
+			{ID: testID("8"), RepoTags: []string{"docker.io/library/db:15"},
+				RepoDigests: []string{"docker.io/library/db@" + testID("d")}},
+			{ID: testID("7"), RepoTags: []string{"docker.io/library/orphan:2"}},
+			{ID: testID("6")},
+			{ID: testID("5"), RepoTags: []string{"registry.k8s.io/coredns/coredns:v1.10.1"}},
+		},
+	}
+	return images, loaded
+}
+
+// diffRefs reduces a diff to the references in each of its sets.
+func diffRefs(diff syncDiff) (onlyHost, onlyKind, stale []string) {
+	for _, image := range diff.OnlyHost {
+		onlyHost = append(onlyHost, image.Reference())
+	}
+	for _, tag := range diff.OnlyKind {
+		onlyKind = append(onlyKind, tag.RepoTag)
+	}
+	for _, tag := range diff.Stale {
+		stale = append(stale, tag.Host.Reference())
+	}
+	return onlyHost, onlyKind, stale
+}
+
+func TestDiffImageSets(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		hideSystem bool
+		backend    string
+		onlyHost   []string
+		onlyKind   []string
+		stale      []string
+	}{
+		{
+			name:     "every repository",
+			onlyHost: []string{"only:1", "registry.k8s.io/pause:3.9"},
+			onlyKind: []string{"docker.io/library/orphan:2", "registry.k8s.io/coredns/coredns:v1.10.1"},
+			stale:    []string{"web:latest"},
+		},
+		{
+			name:       "system images hidden",
+			hideSystem: true,
+			onlyHost:   []string{"only:1"},
+			onlyKind:   []string{"docker.io/library/orphan:2"},
+			stale:      []string{"web:latest"},
+		},
+		{
+			name:       "one repository",
+			repository: "docker.io/library/web",
+			stale:      []string{"web:latest"},
+		},
+		{
+			name:       "short repository",
+			repository: "orphan",
+			onlyKind:   []string{"docker.io/library/orphan:2"},
+		},
+		{
+			// IDs are not compared with ctr, only the digest db has.
+			name:       "ctr",
+			hideSystem: true,
+			backend:    backendCtr,
+			onlyHost:   []string{"only:1"},
+			onlyKind:   []string{"docker.io/library/orphan:2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			images, loaded := syncFixture()
+			if test.backend != "" {
+				loaded.Backend = test.backend
+			}
+			onlyHost, onlyKind, stale := diffRefs(diffImageSets(images, loaded, test.repository, test.hideSystem))
+			if !reflect.DeepEqual(onlyHost, test.onlyHost) {
+				t.Errorf("OnlyHost = %q, want %q", onlyHost, test.onlyHost)
+			}
+			if !reflect.DeepEqual(onlyKind, test.onlyKind) {
+				t.Errorf("OnlyKind = %q, want %q", onlyKind, test.onlyKind)
+			}
+			if !reflect.DeepEqual(stale, test.stale) {
+				t.Errorf("Stale = %q, want %q", stale, test.stale)
+			}
+		})
+	}
+}
+
+func TestDiffImageSetsSkipsDuplicateTags(t *testing.T) {
+	images := []dockerImage{
+		{Repository: "nginx", Tag: "latest", ID: "aaaaaaaaaaaa"},
+		{Repository: "docker.io/library/nginx", Tag: "latest", ID: "aaaaaaaaaaaa"},
+	}
+	diff := diffImageSets(images, kindImages{Backend: backendCrictl}, "", false)
+	if len(diff.OnlyHost) != 1 {
+		t.Errorf("OnlyHost has %d images, want the tag once", len(diff.OnlyHost))
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+// The orders the image tables can be sorted in. Newest first is the default,
+// so a freshly built image is at the top.
+const (
+	sortNewest = "newest"
+	sortName   = "name"
+	sortSize   = "size"
+)
+
+var sortOrders = []string{sortNewest, sortName, sortSize}
+
+// tableSorts tracks the order each table is sorted in. Tables are paged, so
+// rows are sorted here rather than in the browser, which would only reorder
+// the current page.
+type tableSorts struct {
+	mu     sync.Mutex
+	orders map[string]string
+}
+
+func newTableSorts() *tableSorts {
+	return &tableSorts{orders: map[string]string{}}
+}
+
+// Get returns the order of a table.
+func (t *tableSorts) Get(table string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if order, ok := t.orders[table]; ok {
+		return order
+	}
+	return sortNewest
+}
+
+// Set changes the order of a table.
+func (t *tableSorts) Set(table, order string) error {
+	if table != dockerTableName && table != kindTableName {
+		return fmt.Errorf("unknown table %q", table)
+	}
+	if !validSortOrder(order) {
+		return fmt.Errorf("unknown sort order %q, must be one of %s", order, strings.Join(sortOrders, ", "))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.orders[table] = order
+	return nil
+}
+
+// All returns a copy of the orders that were changed from the default.
+func (t *tableSorts) All() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	orders := map[string]string{}
+	for table, order := range t.orders {
+		orders[table] = order
+	}
+	return orders
+}
+
+func validSortOrder(order string) bool {
+	for _, o := range sortOrders {
+		if o == order {
+			return true
+		}
+	}
+	return false
+}
+
+// sortDockerImages reorders images already sorted newest first. Name sorts
+// by repository then tag, with untagged images last.
+func sortDockerImages(images []dockerImage, order string) {
+	switch order {
+	case sortName:
+		sort.SliceStable(images, func(a, b int) bool {
+			return nameLess(images[a].Repository, images[a].Tag, images[b].Repository, images[b].Tag)
+		})
+	case sortSize:
+		sort.SliceStable(images, func(a, b int) bool {
+			return sizeOf(images[a].Size) > sizeOf(images[b].Size)
+		})
+	}
+}
+
+// sortKindImages reorders kind images already sorted newest first, by their
+// first repo tag for name.
+func sortKindImages(images []kindImage, order string) {
+	switch order {
+	case sortName:
+		sort.SliceStable(images, func(a, b int) bool {
+			repoA, tagA := splitFirstTag(images[a].RepoTags)
+			repoB, tagB := splitFirstTag(images[b].RepoTags)
+			return nameLess(repoA, tagA, repoB, tagB)
+		})
+	case sortSize:
+		sort.SliceStable(images, func(a, b int) bool {
+			return sizeOf(images[a].Size) > sizeOf(images[b].Size)
+		})
+	}
+}
+
+// nameLess orders by repository, then tag. Untagged images sort last.
+func nameLess(repoA, tagA, repoB, tagB string) bool {
+	noneA, noneB := repoA == "" || repoA == "<none>", repoB == "" || repoB == "<none>"
+	if noneA != noneB {
+		return noneB
+	}
+	if repoA != repoB {
+		return repoA < repoB
+	}
+	return tagA < tagB
+}
+
+func splitFirstTag(repoTags []string) (string, string) {
+	if len(repoTags) == 0 {
+		return "", ""
+	}
+	repoTag := repoTags[0]
+	// The tag follows the last colon, unless that colon is part of a registry port.
+	if i := strings.LastIndex(repoTag, ":"); i > strings.LastIndex(repoTag, "/") {
+		return repoTag[:i], repoTag[i+1:]
+	}
+	return repoTag, ""
+}
+
+// sizeOf parses a listed size, treating unparseable sizes as zero.
+func sizeOf(s string) int64 {
+	size, err := parseSize(s)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// addSortButtons adds a button for each order the table is not sorted in.
+func addSortButtons(layout *flexlayout.FlexLayout, table, current string) {
+	for _, order := range sortOrders {
+		if order == current {
+			continue
+		}
+		label := fmt.Sprintf("Sort %s images by %s", table, order)
+		if order == sortNewest {
+			label = fmt.Sprintf("Sort %s images newest first", table)
+		}
+		layout.AddButton(label, action.Payload{
+			"action": names.Sort,
+			"table":  table,
+			"order":  order,
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// semver is a version tag such as 1.2.10, v1.10 or 2.0.0-rc.1. Missing minor
+// and patch numbers are zero, since image tags often leave them out.
+type semver struct {
+	Numbers    [3]int
+	Prerelease []string
+}
+
+// parseSemver parses a tag as a version, allowing a v prefix and build
+// metadata, which is ignored.
+func parseSemver(tag string) (semver, bool) {
+	tag = strings.TrimPrefix(tag, "v")
+	if i := strings.Index(tag, "+"); i >= 0 {
+		tag = tag[:i]
+	}
+	var v semver
+	if i := strings.Index(tag, "-"); i >= 0 {
+		v.Prerelease = strings.Split(tag[i+1:], ".")
+		tag = tag[:i]
+	}
+	parts := strings.Split(tag, ".")
+	if len(parts) > 3 {
+		return semver{}, false
+	}
+	for n, part := range parts {
+		number, err := strconv.Atoi(part)
+		if err != nil || number < 0 || part == "" {
+			return semver{}, false
+		}
+		v.Numbers[n] = number
+	}
+	return v, true
+}
+
+// Less reports whether v is an older version than other. A prerelease is
+// older than its release and prereleases compare field by field, numbers
+// numerically and below names, as in semver.
+func (v semver) Less(other semver) bool {
+	for n := range v.Numbers {
+		if v.Numbers[n] != other.Numbers[n] {
+			return v.Numbers[n] < other.Numbers[n]
+		}
+	}
+	if len(v.Prerelease) == 0 || len(other.Prerelease) == 0 {
+		return len(v.Prerelease) > 0 && len(other.Prerelease) == 0
+	}
+	for n := 0; n < len(v.Prerelease) && n < len(other.Prerelease); n++ {
+		a, b := v.Prerelease[n], other.Prerelease[n]
+		if a == b {
+			continue
+		}
+		numberA, errA := strconv.Atoi(a)
+		numberB, errB := strconv.Atoi(b)
+		switch {
+		case errA == nil && errB == nil:
+			return numberA < numberB
+		case errA == nil:
+			return true
+		case errB == nil:
+			return false
+		default:
+			return a < b
+		}
+	}
+	return len(v.Prerelease) < len(other.Prerelease)
+}
+
+// sortByTag orders the images of one repository the way people read tags:
+// latest first, then version tags from newest to oldest, then everything
+// else, such as git SHAs, by creation time, newest first.
+func sortByTag(images []dockerImage) {
+	rank := func(image dockerImage) int {
+		if image.Tag == "latest" {
+			return 0
+		}
+		if _, ok := parseSemver(image.Tag); ok {
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(images, func(a, b int) bool {
+		rankA, rankB := rank(images[a]), rank(images[b])
+		if rankA != rankB {
+			return rankA < rankB
+		}
+		if rankA == 1 {
+			versionA, _ := parseSemver(images[a].Tag)
+			versionB, _ := parseSemver(images[b].Tag)
+			if versionA.Less(versionB) || versionB.Less(versionA) {
+				return versionB.Less(versionA)
+			}
+		}
+		createdA, _ := images[a].Created()
+		createdB, _ := images[b].Created()
+		return createdA.After(createdB)
+	})
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortByTag(t *testing.T) {
+	tags := []string{"1.2.9", "abc123", "2.0.0-alpha", "v2.0.0", "1.10.0", "latest", "def456", "v2.0.0-rc.1", "7", "1.2.10", "2.0.0-rc.2"}
+	created := map[string]string{
+		"abc123": "2024-08-01 17:20:05 +0000 UTC",
+		"def456": "2024-08-14 21:31:12 +0000 UTC",
+	}
+	var images []dockerImage
+	for _, tag := range tags {
+		images = append(images, dockerImage{Repository: "app", Tag: tag, CreatedAt: created[tag]})
+	}
+
+	sortByTag(images)
+	var got []string
+	for _, image := range images {
+		got = append(got, image.Tag)
+	}
+	want := []string{"latest", "7", "v2.0.0", "2.0.0-rc.2", "v2.0.0-rc.1", "2.0.0-alpha", "1.10.0", "1.2.10", "1.2.9", "def456", "abc123"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sorted tags = %v, want %v", got, want)
+	}
+}
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want semver
+		ok   bool
+	}{
+		{tag: "1.2.10", want: semver{Numbers: [3]int{1, 2, 10}}, ok: true},
+		{tag: "v1.10", want: semver{Numbers: [3]int{1, 10, 0}}, ok: true},
+		{tag: "7", want: semver{Numbers: [3]int{7, 0, 0}}, ok: true},
+		{tag: "2.0.0-rc.1", want: semver{Numbers: [3]int{2, 0, 0}, Prerelease: []string{"rc", "1"}}, ok: true},
+		{tag: "v1.0.0+build.5", want: semver{Numbers: [3]int{1, 0, 0}}, ok: true},
+		{tag: "latest"},
+		{tag: "abc123"},
+		{tag: "1.2.3.4"},
+		{tag: "1..2"},
+	}
+	for _, test := range tests {
+		got, ok := parseSemver(test.tag)
+		if ok != test.ok || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseSemver(%q) = %+v, %t, want %+v, %t", test.tag, got, ok, test.want, test.ok)
+		}
+	}
+}
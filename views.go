@@ -0,0 +1,992 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware-tanzu/octant/pkg/action"
+	"github.com/vmware-tanzu/octant/pkg/navigation"
+	"github.com/vmware-tanzu/octant/pkg/plugin/service"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+	"github.com/vmware-tanzu/octant/pkg/view/flexlayout"
+)
+
+const (
+	dockerPath   = "docker"
+	kindPath     = "kind"
+	registryPath = "registry"
+)
+
+func (i *imagePlugin) handleNav(request *service.NavigationRequest) (navigation.Navigation, error) {
+	// The pages below can't list anything without the prerequisites, so the
+	// entry only leads to the page explaining what is missing.
+	if len(failedChecks(i.selfChecks.Last())) > 0 {
+		return navigation.Navigation{
+			Title:    "Local Images (unavailable)",
+			Path:     request.GeneratePath(""),
+			IconName: "storage",
+		}, nil
+	}
+	children := []navigation.Navigation{
+		{
+			Title:    host.Title() + " Images",
+			Path:     request.GeneratePath(dockerPath),
+			IconName: "storage",
+		},
+		{
+			Title:    "Kind Images",
+			Path:     request.GeneratePath(kindPath),
+			IconName: "storage",
+		},
+	}
+	// Follows clusters being deleted and created while Octant runs.
+	if !i.clusters.Exists() {
+		children[1].Title = fmt.Sprintf("Kind Images (%s not found)", kindCluster)
+	}
+	// The registry the pages last detected, since the navigation never
+	// runs commands.
+	if registry := i.registries.Last(); registry != nil {
+		children = append(children, navigation.Navigation{
+			Title:    "Local Registry",
+			Path:     request.GeneratePath(registryPath),
+			IconName: "storage",
+		})
+	}
+	children = append(children, navigation.Navigation{
+		Title:    "Kind Clusters",
+		Path:     request.GeneratePath(clustersPath),
+		IconName: "storage",
+	})
+	children = append(children, navigation.Navigation{
+		Title:    "Repositories",
+		Path:     request.GeneratePath(repositoriesPath),
+		IconName: "storage",
+	})
+	// Octant's navigation has no badge, so the counts of images pending
+	// sync go in the titles: the stale ones on the plugin's entry, the ones
+	// still to load on Not in Kind.
+	title, notInKindTitle, syncTitle := "Local Images", "Not in Kind", "Sync Status"
+	if missing, stale, ok := i.pendingSync(); navCounts && ok {
+		if stale > 0 {
+			title = fmt.Sprintf("Local Images (%d)", stale)
+			syncTitle = fmt.Sprintf("Sync Status (%d stale)", stale)
+		}
+		if missing > 0 {
+			notInKindTitle = fmt.Sprintf("Not in Kind (%d)", missing)
+		}
+	}
+	children = append(children, navigation.Navigation{
+		Title:    notInKindTitle,
+		Path:     request.GeneratePath(notInKindPath),
+		IconName: "storage",
+	})
+	children = append(children, navigation.Navigation{
+		Title:    syncTitle,
+		Path:     request.GeneratePath(syncStatusPath),
+		IconName: "storage",
+	})
+	children = append(children, navigation.Navigation{
+		Title:    "Inventory (JSON)",
+		Path:     request.GeneratePath(inventoryPath),
+		IconName: "storage",
+	})
+
+	return navigation.Navigation{
+		Title:    title,
+		Path:     request.GeneratePath(""),
+		IconName: "storage",
+		Children: children,
+	}, nil
+}
+
+func (i *imagePlugin) initRoutes(router *service.Router) {
+	router.HandleFunc("/"+dockerPath, i.handleDockerImages)
+	router.HandleFunc("/"+kindPath, i.handleKindImages)
+	router.HandleFunc("/"+registryPath, i.handleRegistryImages)
+	router.HandleFunc("/"+clustersPath, i.handleClusters)
+	router.HandleFunc("/"+notInKindPath, i.handleNotInKind)
+	router.HandleFunc("/"+syncStatusPath, i.handleSyncStatus)
+	router.HandleFunc("/"+syncStatusPath+"/*", i.handleSyncStatus)
+	router.HandleFunc("/"+repositoriesPath, i.handleRepositories)
+	router.HandleFunc("/"+repositoryPath+"/*", i.handleRepository)
+	router.HandleFunc("/"+inventoryPath, i.handleInventory)
+	router.HandleFunc("/"+dockerImagePath+"/*", i.handleDockerImage)
+	router.HandleFunc("/"+kindImagePath+"/*", i.handleKindImage)
+	router.HandleFunc("/"+imageHistoryPath+"/*", i.handleImageHistory)
+	router.HandleFunc("*", i.handleOverview)
+}
+
+// handleOverview renders the docker and kind views as tabs of a single page.
+func (i *imagePlugin) handleOverview(request service.Request) (component.ContentResponse, error) {
+	// Each component in the content response is rendered as its own tab.
+	contentResponse := component.NewContentResponse(component.TitleFromString("Local Images"))
+	// Until the prerequisites are met nothing else can work, so only the
+	// diagnostics are shown.
+	if checks := i.selfChecks.Get(); len(failedChecks(checks)) > 0 {
+		contentResponse.Add(selfCheckView(checks))
+		contentResponse.Add(environmentView(i.environment.Versions(), i.selfChecks.Missing(), nodeVersionWarnings(i.clusters), i.stateSaver.Status(), i.api.URL()))
+		return *contentResponse, nil
+	}
+
+	// Renders of other tabs running at the same time share the listing.
+	views, err := i.overviews.Do(request.Context(), kindCluster, func() overviewViews {
+		shared := detachedRequest{Request: request, ctx: context.Background()}
+		// The two views list images with separate slow commands, so they are
+		// built concurrently. Each reports its own errors, so one failing does
+		// not blank the other.
+		var views overviewViews
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			views.Docker = i.dockerView(shared)
+		}()
+		go func() {
+			defer wg.Done()
+			views.Kind = i.kindView(shared)
+		}()
+		wg.Wait()
+		return views
+	})
+	if err != nil {
+		return component.ContentResponse{}, err
+	}
+	contentResponse.Add(views.Docker, views.Kind, i.clustersView())
+	// The registry tab is left out entirely when there is no local registry.
+	if registry, err := i.registries.Detect(request.Context(), request.DashboardClient()); err == nil && registry != nil {
+		contentResponse.Add(i.registryView(*registry))
+	}
+	contentResponse.Add(environmentView(i.environment.Versions(), i.selfChecks.Missing(), nodeVersionWarnings(i.clusters), i.stateSaver.Status(), i.api.URL()))
+	contentResponse.Add(activityView(i.activity.List(), i.stats))
+	return *contentResponse, nil
+}
+
+func (i *imagePlugin) handleDockerImages(request service.Request) (component.ContentResponse, error) {
+	contentResponse := component.NewContentResponse(component.TitleFromString(host.Title() + " Images"))
+	contentResponse.Add(i.dockerView(request))
+	return *contentResponse, nil
+}
+
+func (i *imagePlugin) handleKindImages(request service.Request) (component.ContentResponse, error) {
+	contentResponse := component.NewContentResponse(component.TitleFromString("Kind Images"))
+	contentResponse.Add(i.kindView(request))
+	return *contentResponse, nil
+}
+
+func (i *imagePlugin) handleClusters(request service.Request) (component.ContentResponse, error) {
+	contentResponse := component.NewContentResponse(component.TitleFromString("Kind Clusters"))
+	contentResponse.Add(i.clustersView())
+	return *contentResponse, nil
+}
+
+func (i *imagePlugin) handleRegistryImages(request service.Request) (component.ContentResponse, error) {
+	contentResponse := component.NewContentResponse(component.TitleFromString("Local Registry"))
+
+	registry, err := i.registries.Detect(request.Context(), request.DashboardClient())
+	if err != nil || registry == nil {
+		layout := flexlayout.New()
+		if err != nil {
+			addErrorSection(layout, err)
+		} else {
+			section := layout.AddSection()
+			section.Add(component.NewTextf("No local registry found. Start a registry container named %s "+
+				"as described in https://kind.sigs.k8s.io/docs/user/local-registry/.", registryContainer), component.WidthFull)
+		}
+		view := layout.ToComponent("Local Registry")
+		view.SetAccessor(registryPath)
+		contentResponse.Add(view)
+		return *contentResponse, nil
+	}
+
+	contentResponse.Add(i.registryView(*registry))
+	return *contentResponse, nil
+}
+
+func (i *imagePlugin) dockerView(request service.Request) *component.FlexLayout {
+	columns := append(append([]string(nil), hostColumns...), labelColumns...)
+	scanning := scanAvailable()
+	if scanning {
+		columns = append(columns, "Vulnerabilities")
+	}
+	showCommands := i.commands.Shown()
+	if showCommands {
+		columns = append(columns, "Command")
+	}
+	table := component.NewTable(host.Title()+" Images", "No images found", component.NewTableCols(columns...))
+
+	layout := flexlayout.New()
+	i.addMissingToolsSection(layout)
+	if i.hasTool(host.Name()) {
+		addHealthSection(layout, i.health.Get(), i.environment.Versions())
+	}
+	addDryRunSection(layout)
+	addEndpointSection(layout)
+	if err := i.feedback.Get(); err != nil {
+		addErrorSection(layout, err)
+	}
+
+	var rows []component.TableRow
+	var cluster clusterInfo
+	// The compose projects are only known when the images were inspected.
+	var projects []string
+	var skipped *parseError
+	if i.hasTool(host.Name()) {
+		images, err := listDockerImages()
+		// Lines that couldn't be parsed are noted under the table, next to
+		// where their images are missing.
+		if !errors.As(err, &skipped) && err != nil {
+			addErrorSection(layout, err)
+		} else {
+			// Host images are listed on every render, unlike kind's.
+			table.Metadata.SetTitleText(fmt.Sprintf("%s Images (%s)", host.Title(), updatedAgo(time.Now())))
+		}
+		cluster = i.clusterInfo(request)
+
+		var ids []string
+		for _, image := range images {
+			ids = append(ids, image.ID)
+		}
+		// Inspecting is skipped when no shown column or filter needs it.
+		var inspects map[string]dockerInspect
+		if hostColumnsNeedInspect() || i.labels.Get() != "" || i.projects.Get() != "" {
+			if inspects, err = i.inspects.Get(ids); err != nil {
+				log.Printf("unable to inspect docker images: %s", err)
+			}
+		}
+
+		grouped, tags := groupByID(images)
+		addImageTotalsSection(layout, images, tags)
+		project := i.projects.Get()
+		if inspects != nil {
+			projects = composeProjects(grouped, inspects)
+			addProjectButton(layout, project, len(projectImages(project, grouped, inspects)))
+		}
+
+		usage, usageErr := i.hostUsage.Get()
+		if usageErr != nil {
+			log.Printf("unable to list %s containers: %s", host.Name(), usageErr)
+		}
+		staleOnly := i.staleOnly.Only(dockerTableName)
+		staleIDs := map[string]bool{}
+		if usageErr == nil {
+			stale := staleHostImages(grouped, usage, staleBefore())
+			for _, image := range stale {
+				staleIDs[image.ID] = true
+			}
+			addStaleSection(layout, dockerTableName, len(stale), uniqueSize(stale), staleOnly)
+		}
+
+		if !collapseTags {
+			grouped = append([]dockerImage(nil), images...)
+		}
+		if imageID, err := i.tagPrompt.Get(); imageID != "" {
+			addLoadTagPromptSection(layout, imageID, tags[imageID], err)
+		}
+		// Newest first; images whose created time can't be parsed go last.
+		sort.SliceStable(grouped, func(a, b int) bool {
+			createdA, _ := grouped[a].Created()
+			createdB, _ := grouped[b].Created()
+			return createdA.After(createdB)
+		})
+		order := i.sorts.Get(dockerTableName)
+		sortDockerImages(grouped, order)
+		addSortButtons(layout, dockerTableName, order)
+		selector := i.labels.Get()
+		busy := i.busyImages()
+		for _, image := range grouped {
+			labels := inspects[image.ID].Config.Labels
+			if !matchLabels(selector, labels) || !matchProject(project, labels) || (staleOnly && !staleIDs[image.ID]) {
+				continue
+			}
+			imageTags := rowTags(image, tags)
+			row := rowPrinter(image, imageTags, inspects[image.ID], cluster)
+			row["Last used"] = hostLastUsedCell(image, usage)
+			if status, ok := imageBusy(busy, append([]string{image.Reference(), image.ID}, imageTags...)...); ok {
+				markBusy(row, image.Reference(), status)
+			}
+			if scanning {
+				i.addScanColumn(row, image)
+			}
+			if showCommands {
+				row["Command"] = commandCell(host.LoadCommand(image.Reference()))
+			}
+			rows = append(rows, row)
+		}
+
+		if len(images) == 0 && err == nil {
+			card := component.NewCard(component.TitleFromString("No Images Yet"))
+			card.SetBody(component.NewTextf("%s has no images. Build one with %s build -t my-app . or pull one with %s pull nginx, "+
+				"and it shows up here ready to load into kind. To skip %s, pull straight into kind instead.",
+				host.Title(), host.Name(), host.Name(), host.Name()))
+			emptySection := layout.AddSection()
+			emptySection.Add(card, component.WidthHalf)
+			emptySection.Add(pullCard(i.pullError.Get()), component.WidthHalf)
+		}
+	}
+
+	current, pending := i.pushes.Snapshot()
+	if current != nil {
+		pushSection := layout.AddSection()
+		pushSection.Add(component.NewTextf("Pushing %s to %s (%s elapsed, %d of %d queued)...",
+			current.ImageID, current.Target, time.Since(current.StartedAt).Round(time.Second), len(pending), i.pushes.Size()), component.WidthFull)
+		if output := i.pushProgress.Output(); len(output) > 0 {
+			pushSection.Add(component.NewCodeBlock(strings.Join(output, "\n")), component.WidthFull)
+		}
+		if len(pending) > 0 {
+			pushSection.Add(queuePrinter("Queued Pushes", pending), component.WidthFull)
+		}
+	} else {
+		addStatusSection(layout, i.pushProgress)
+	}
+
+	addStatusSection(layout, i.removeProgress)
+	i.addMaintenanceSection(layout)
+	i.addScanSection(layout)
+
+	if source, err := i.loadPrompt.Get(); source != "" {
+		addLoadPromptSection(layout, source, cluster.Architecture, err)
+	}
+	if source, err := i.pushPrompt.Get(); source != "" {
+		addPushPromptSection(layout, source, err)
+	}
+
+	if current, pending := i.saves.Snapshot(); current != nil {
+		saveSection := layout.AddSection()
+		saveSection.Add(component.NewTextf("Saving %s to %s (%s elapsed, %d of %d queued)...",
+			current.ImageID, current.Target, time.Since(current.StartedAt).Round(time.Second), len(pending), i.saves.Size()), component.WidthFull)
+		if len(pending) > 0 {
+			saveSection.Add(queuePrinter("Queued Saves", pending), component.WidthFull)
+		}
+	} else {
+		addStatusSection(layout, i.saveProgress)
+	}
+	if source, err := i.savePrompt.Get(); source != "" {
+		addSavePromptSection(layout, source, err)
+	}
+	if history := i.history.List(); len(history) > 0 {
+		historySection := layout.AddSection()
+		historySection.Add(pushHistoryPrinter(history), component.WidthFull)
+	}
+
+	if builders, err := i.buildx.Get(); err == nil {
+		addBuildxSection(layout, builders, i.buildError.Get())
+	}
+
+	filterSection := layout.AddSection()
+	filterSection.Add(labelFilterCard(i.labels.Get()), component.WidthHalf)
+	if projects != nil || i.projects.Get() != "" {
+		filterSection.Add(projectFilterCard(i.projects.Get(), projects), component.WidthHalf)
+	}
+	layout.AddButton(commandToggleLabel(showCommands), action.Payload{
+		"action": names.Commands,
+	})
+	layout.AddButton("Refresh", action.Payload{
+		"action": names.RefreshNow,
+	})
+
+	i.addPagedRows(layout, table, dockerTableName, rows)
+	dockerSection := layout.AddSection()
+	dockerSection.Add(table, component.WidthFull)
+	addSkippedSection(layout, skipped)
+
+	view := layout.ToComponent(host.Title() + " Images")
+	view.SetAccessor(dockerPath)
+	return view
+}
+
+func (i *imagePlugin) kindView(request service.Request) *component.FlexLayout {
+	columns := append([]string(nil), kindColumns...)
+
+	layout := flexlayout.New()
+	i.addMissingToolsSection(layout)
+	if i.hasTool(host.Name()) {
+		addHealthSection(layout, i.health.Get(), i.environment.Versions())
+	}
+	addDryRunSection(layout)
+	addEndpointSection(layout)
+	if err := i.feedback.Get(); err != nil {
+		addErrorSection(layout, err)
+	}
+
+	var rows []component.TableRow
+	title := "Kind Images"
+	// The kind node is reached through docker exec, so there is nothing to
+	// list without docker or while the cluster is missing or stopped.
+	if i.hasTool(host.Name()) && i.addClusterStateSection(layout, i.health.Get()) {
+		pressured, err := i.pressure.Get(request.Context(), request.DashboardClient())
+		addPressureSection(layout, pressured, err)
+		nodes, err := listKindNodes()
+		if err != nil || len(nodes) == 0 {
+			nodes = []string{kindNode}
+		}
+		images := i.nodeImages.List(nodes)
+		if !images.Checked.IsZero() {
+			title = fmt.Sprintf("Kind Images (%s)", updatedAgo(images.Checked))
+		}
+		if len(images.Failed) == len(nodes) {
+			addErrorSection(layout, fmt.Errorf("unable to list kind images: %s", strings.Join(images.FailedNodes(), "; ")))
+		} else if len(images.Failed) > 0 {
+			text := component.NewTextf("Images of some nodes are missing: %s", strings.Join(images.FailedNodes(), "; "))
+			text.SetStatus(component.TextStatusWarning)
+			failedSection := layout.AddSection()
+			failedSection.Add(text, component.WidthFull)
+		}
+		// Which nodes have an image only matters with more than one.
+		if len(nodes) > 1 {
+			columns = append(columns, "Nodes")
+		}
+		showCommands := i.commands.Shown()
+		if showCommands {
+			columns = append(columns, "Command")
+		}
+		if images.Backend == backendCtr {
+			backendSection := layout.AddSection()
+			backendSection.Add(component.NewTextf("crictl is not installed on %s, so images were listed with ctr. "+
+				"Image IDs are manifest digests and pod usage is unknown.", kindNode), component.WidthFull)
+		}
+		addLoadTimesSection(layout, i.loadTimes)
+		addDiskUsageSection(layout, i.diskUsage.Get())
+		strays, err := i.strays.Get()
+		addStrayImagesSection(layout, strays, err)
+
+		var consumers map[string][]kindContainer
+		if containers, err := listKindContainers(); err == nil {
+			consumers = imageConsumers(containers)
+		} else {
+			log.Printf("unable to list kind containers: %s", err)
+		}
+
+		var ids []string
+		for _, image := range images.Images {
+			ids = append(ids, image.ID)
+		}
+		specs, err := i.specs.Get(ids)
+		if err != nil {
+			log.Printf("unable to inspect kind images: %s", err)
+		}
+
+		// Stopped containers count as uses too, so stale images are only the
+		// ones deleting unused images would remove.
+		var allConsumers map[string][]kindContainer
+		if containers, err := listAllKindContainers(); err == nil {
+			allConsumers = imageConsumers(containers)
+		} else {
+			log.Printf("unable to list kind containers: %s", err)
+		}
+		lastUsed := kindLastUsed(allConsumers)
+		staleOnly := i.staleOnly.Only(kindTableName)
+		staleIDs := map[string]bool{}
+		if allConsumers != nil && images.Backend != backendCtr {
+			stale := staleKindImages(images.Images, specs, allConsumers, staleBefore())
+			for _, image := range stale {
+				staleIDs[image.ID] = true
+			}
+			addStaleSection(layout, kindTableName, len(stale), kindImagesSize(stale), staleOnly)
+		}
+
+		var nodeArch string
+		if contains(kindColumns, "Architecture") {
+			if nodeArch, err = nodeArchitecture(kindNode); err != nil {
+				log.Printf("unable to determine kind node architecture: %s", err)
+			}
+		}
+
+		// Newest first, like the docker table; created comes from the cached crictl inspecti.
+		sort.SliceStable(images.Images, func(a, b int) bool {
+			return specs[images.Images[a].ID].Created.After(specs[images.Images[b].ID].Created)
+		})
+		order := i.sorts.Get(kindTableName)
+		sortKindImages(images.Images, order)
+		addSortButtons(layout, kindTableName, order)
+
+		// Failing to reach the API server only leaves the warnings out.
+		pulls, _ := i.policies.Get(request.Context(), request.DashboardClient())
+		hideSystem := i.systemImages.Hidden()
+		busy := i.busyKindImages()
+		selected := i.selection.Get()
+		var choices []component.InputChoice
+		hidden := 0
+		for _, image := range images.Images {
+			if staleOnly && !staleIDs[image.ID] {
+				continue
+			}
+			for _, repoTag := range image.RepoTags {
+				if hideSystem && isSystemImage(repoTag) {
+					hidden++
+					continue
+				}
+				row := kindPrinter(image, repoTag, specs[image.ID], nodeArch, consumers[image.ID])
+				choices = append(choices, selectionChoice(image, repoTag, selected))
+				if p := pulls[normalizeReference(repoTag)]; len(p) > 0 {
+					row["Used by"] = pullPolicyCell(row["Used by"], p)
+				}
+				if allConsumers != nil {
+					row["Last used"] = lastUsedCell(kindImageLastUsed(image, allConsumers, lastUsed))
+				} else {
+					row["Last used"] = component.NewText("unknown")
+				}
+				if status, ok := busy[normalizeReference(repoTag)]; ok {
+					markBusy(row, image.Reference(repoTag), status)
+				}
+				if len(nodes) > 1 {
+					row["Nodes"] = component.NewText(strings.Join(images.Nodes[image.ID], ", "))
+				}
+				if showCommands {
+					// Pinned images have no delete to show.
+					command := "—"
+					if !image.Pinned {
+						command = commandLine(kindDeleteCommand(deleteTarget(image, repoTag), image, images.Backend))
+					}
+					row["Command"] = commandCell(command)
+				}
+				rows = append(rows, row)
+			}
+		}
+
+		label := "Hide system images"
+		if hideSystem {
+			label = "Show system images"
+			filterSection := layout.AddSection()
+			filterSection.Add(component.NewTextf("Hiding %d image(s) from %s", hidden, strings.Join(systemRepositories, ", ")), component.WidthFull)
+		}
+		layout.AddButton(label, action.Payload{
+			"action": names.ToggleSystem,
+		})
+		layout.AddButton(commandToggleLabel(showCommands), action.Payload{
+			"action": names.Commands,
+		})
+		i.addAutoSyncSection(layout)
+		layout.AddButton("Refresh", action.Payload{
+			"action": names.RefreshNow,
+		})
+		layout.AddButton("Refresh kind images", action.Payload{
+			"action": names.Refresh,
+		})
+		layout.AddButton("Delete all unused kind images", action.Payload{
+			"action": names.PruneKind,
+		}, component.WithButtonConfirmation("Delete unused images?",
+			"This deletes every image that no container, running or exited, references on any node of the cluster. "+
+				"Pinned and system images are kept. Pods scheduled later must pull deleted images again. Do you want to continue?"))
+		addSelectionSection(layout, selected, choices, images, allConsumers)
+	}
+	table := component.NewTable(title, "No images found", component.NewTableCols(columns...))
+
+	current, pending := i.queue.Snapshot()
+	if current != nil {
+		loadingSection := layout.AddSection()
+		loadingSection.Add(component.NewTextf("Loading %s into kind (%s elapsed, %d of %d queued)...",
+			current.ImageID, time.Since(current.StartedAt).Round(time.Second), len(pending), i.queue.Size()), component.WidthFull)
+		if output := i.progress.Output(); len(output) > 0 {
+			loadingSection.Add(component.NewCodeBlock(strings.Join(output, "\n")), component.WidthFull)
+		}
+		if len(pending) > 0 {
+			loadingSection.Add(queuePrinter("Queued Loads", pending), component.WidthFull)
+		}
+		layout.AddButton("Cancel load", action.Payload{
+			"action":  names.Cancel,
+			"imageID": current.ImageID,
+		}, component.WithButtonConfirmation("Cancel load",
+			fmt.Sprintf("Do you want to stop loading %s? Nodes it has already been copied to may keep a partial image.", current.ImageID)))
+	}
+
+	if current == nil {
+		addStatusSection(layout, i.progress)
+	}
+	addOrphanSection(layout, inflight.Orphans())
+	addStatusSection(layout, i.deleteProgress)
+	i.addMaintenanceSection(layout)
+	addBulkDeleteReportSection(layout, i.bulkDeletes.Get())
+	if imageID, _ := i.forcePrompt.Get(); imageID != "" {
+		addForceDeletePromptSection(layout, imageID)
+	}
+
+	if current, pending := i.copies.Snapshot(); current != nil {
+		copySection := layout.AddSection()
+		copySection.Add(component.NewTextf("Copying %s to cluster %s (%s elapsed, %d of %d queued)...",
+			current.ImageID, current.Target, time.Since(current.StartedAt).Round(time.Second), len(pending), i.copies.Size()), component.WidthFull)
+		if output := i.copyProgress.Output(); len(output) > 0 {
+			copySection.Add(component.NewCodeBlock(strings.Join(output, "\n")), component.WidthFull)
+		}
+		if len(pending) > 0 {
+			copySection.Add(queuePrinter("Queued Copies", pending), component.WidthFull)
+		}
+	} else {
+		addStatusSection(layout, i.copyProgress)
+	}
+	if source, err := i.copyPrompt.Get(); source != "" {
+		addCopyPromptSection(layout, source, err)
+	}
+
+	if current, pending := i.pulls.Snapshot(); current != nil {
+		pullSection := layout.AddSection()
+		pullSection.Add(component.NewTextf("Pulling %s into kind (%s elapsed, %d of %d queued)...",
+			current.ImageID, time.Since(current.StartedAt).Round(time.Second), len(pending), i.pulls.Size()), component.WidthFull)
+		if output := i.pullProgress.Output(); len(output) > 0 {
+			pullSection.Add(component.NewCodeBlock(strings.Join(output, "\n")), component.WidthFull)
+		}
+		if len(pending) > 0 {
+			pullSection.Add(queuePrinter("Queued Pulls", pending), component.WidthFull)
+		}
+	} else {
+		addStatusSection(layout, i.pullProgress)
+	}
+
+	if current, pending := i.gets.Snapshot(); current != nil {
+		phase, n := i.getProgress.CurrentPhase()
+		getSection := layout.AddSection()
+		getSection.Add(component.NewTextf("Getting %s into kind: %s (phase %d of 2, %s elapsed, %d of %d queued)...",
+			current.ImageID, phase, n, time.Since(current.StartedAt).Round(time.Second), len(pending), i.gets.Size()), component.WidthFull)
+		if output := i.getProgress.Output(); len(output) > 0 {
+			getSection.Add(component.NewCodeBlock(strings.Join(output, "\n")), component.WidthFull)
+		}
+		if len(pending) > 0 {
+			getSection.Add(queuePrinter("Queued Gets", pending), component.WidthFull)
+		}
+	} else {
+		addStatusSection(layout, i.getProgress)
+	}
+
+	formSection := layout.AddSection()
+	formSection.Add(archiveCard(i.archiveError.Get()), component.WidthHalf)
+	formSection.Add(pullCard(i.pullError.Get()), component.WidthHalf)
+	formSection.Add(getCard(i.getError.Get()), component.WidthHalf)
+
+	i.addPagedRows(layout, table, kindTableName, rows)
+	kindSection := layout.AddSection()
+	kindSection.Add(table, component.WidthFull)
+
+	view := layout.ToComponent("Kind Images")
+	view.SetAccessor(kindPath)
+	return view
+}
+
+func (i *imagePlugin) registryView(registry localRegistry) *component.FlexLayout {
+	table := component.NewTable("Registry Images", "No images pushed yet",
+		component.NewTableCols("Repository", "Tag", "Reference", "Digest"))
+
+	layout := flexlayout.New()
+	infoSection := layout.AddSection()
+	infoSection.Add(component.NewTextf("Images in %s. Pods in kind pull them by the reference shown.", registry.Name()), component.WidthFull)
+	addStatusSection(layout, i.cleanProgress)
+	if current, _ := i.cleanups.Snapshot(); current != nil {
+		table.SetIsLoading(true)
+	}
+
+	repositories, err := registry.Repositories()
+	if err != nil {
+		addErrorSection(layout, err)
+	}
+	for _, repository := range repositories {
+		tags, err := registry.Tags(repository)
+		if err != nil {
+			log.Printf("unable to list tags: %s", err)
+			continue
+		}
+		// Deleting a digest untags every tag pointing to it, so the
+		// confirmation names them all.
+		digests := map[string]string{}
+		tagged := map[string][]string{}
+		for _, tag := range tags {
+			digest, err := i.digests.Get(registry, repository, tag)
+			if err != nil {
+				log.Printf("unable to get manifest digest: %s", err)
+				continue
+			}
+			digests[tag] = digest
+			tagged[digest] = append(tagged[digest], tag)
+		}
+		for _, tag := range tags {
+			row := component.TableRow{
+				"Repository": component.NewText(repository),
+				"Tag":        component.NewText(tag),
+				"Reference":  component.NewText(registry.Host + "/" + repository + ":" + tag),
+				"Digest":     component.NewText("—"),
+			}
+			if digest := digests[tag]; digest != "" {
+				row["Digest"] = component.NewText(shortDigest("@" + digest))
+				row.AddAction(component.GridAction{
+					Name:       "Delete",
+					ActionPath: names.DeleteTag,
+					Payload: action.Payload{
+						"action":     names.DeleteTag,
+						"repository": repository,
+						"digest":     digest,
+					},
+					Confirmation: &component.Confirmation{
+						Title: "Delete from registry?",
+						Body: fmt.Sprintf("This deletes the manifest %s from %s, removing the tag(s) %s. "+
+							"Do you want to continue?", shortDigest("@"+digest), repository, strings.Join(tagged[digest], ", ")),
+					},
+					Type: component.GridActionDanger,
+				})
+			}
+			table.Add(row)
+		}
+	}
+
+	// garbage-collect runs inside the registry container, which isn't
+	// known when the registry was found through the ConfigMap.
+	if registry.Container != "" {
+		layout.AddButton("Run garbage collection", action.Payload{
+			"action": names.RegistryGC,
+		}, component.WithButtonConfirmation("Run garbage collection?",
+			fmt.Sprintf("This runs registry garbage-collect in %s to delete the layers of deleted manifests. "+
+				"Pushes during the collection can be corrupted. Do you want to continue?", registry.Container)))
+	}
+
+	registrySection := layout.AddSection()
+	registrySection.Add(table, component.WidthFull)
+
+	view := layout.ToComponent("Local Registry")
+	view.SetAccessor(registryPath)
+	return view
+}
+
+// archiveCard renders the form to import a docker save tarball.
+func archiveCard(err error) *component.Card {
+	card := component.NewCard(component.TitleFromString("Import Image Archive"))
+	card.SetBody(component.NewText("Load a docker save tarball from this machine straight into kind, without going through docker."))
+	card.AddAction(component.Action{
+		Name:  "Import",
+		Title: "Import image archive",
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.LoadArchive),
+				component.NewFormFieldText("Archive path", "path", ""),
+			},
+		},
+		Modal: true,
+	})
+	if err != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, err.Error()))
+	}
+	return card
+}
+
+// pullCard renders the form to pull an image on the kind nodes themselves.
+func pullCard(err error) *component.Card {
+	card := component.NewCard(component.TitleFromString("Pull Into Kind"))
+	card.SetBody(component.NewText("Pull an image on every kind node with crictl, without using docker. " +
+		"Credentials are only needed for private registries: type them in, or reuse the login of your docker config. " +
+		"They are only passed to crictl pull and are never logged or shown."))
+	card.AddAction(component.Action{
+		Name:  "Pull",
+		Title: "Pull image into kind",
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.Pull),
+				component.NewFormFieldText("Image", "image", ""),
+				component.NewFormFieldText("Username", "username", ""),
+				component.NewFormFieldPassword("Password", "password", ""),
+				component.NewFormFieldCheckBox("", "dockerCreds", []component.InputChoice{
+					{Label: "Use my docker credentials (~/.docker/config.json and its credential helpers)", Value: "docker"},
+				}),
+				component.NewFormFieldText("Docker config path (instead of ~/.docker/config.json)", "dockerConfig", ""),
+				component.NewFormFieldCheckBox("", "via", []component.InputChoice{
+					{Label: "Pull with " + host.Name() + " and load instead (ignores credentials)", Value: "host"},
+				}),
+			},
+		},
+		Modal: true,
+	})
+	if err != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, err.Error()))
+	}
+	return card
+}
+
+// addPushPromptSection asks where to push source, pre-filled with the source
+// reference so only the registry part usually needs changing.
+func addPushPromptSection(layout *flexlayout.FlexLayout, source string, err error) {
+	card := component.NewCard(component.TitleFromString(fmt.Sprintf("Push %s", source)))
+	card.SetBody(component.NewTextf("Enter the destination reference, e.g. registry.example.com/team/app:v1. "+
+		"The push uses your %s login and credential helpers.", host.Name()))
+	card.AddAction(component.Action{
+		Name:  "Push",
+		Title: fmt.Sprintf("Push %s", source),
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.PushTo),
+				component.NewFormFieldHidden("source", source),
+				component.NewFormFieldText("Destination", "destination", source),
+			},
+		},
+		Modal: true,
+	})
+	if err != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, err.Error()))
+	}
+
+	promptSection := layout.AddSection()
+	promptSection.Add(card, component.WidthFull)
+	layout.AddButton("Dismiss push", action.Payload{
+		"action":  names.PushPrompt,
+		"imageID": "",
+	})
+}
+
+// addSavePromptSection asks where to save source, suggesting a file named
+// after the image in the home directory.
+func addSavePromptSection(layout *flexlayout.FlexLayout, source string, err error) {
+	card := component.NewCard(component.TitleFromString(fmt.Sprintf("Save %s", source)))
+	card.SetBody(component.NewText("Enter the path of the .tar file to write with docker save. " +
+		"Missing directories are created."))
+	card.AddAction(component.Action{
+		Name:  "Save",
+		Title: fmt.Sprintf("Save %s", source),
+		Form: component.Form{
+			Fields: []component.FormField{
+				component.NewFormFieldHidden("action", names.Save),
+				component.NewFormFieldHidden("source", source),
+				component.NewFormFieldText("Output path", "path", defaultSavePath(source)),
+				component.NewFormFieldCheckBox("", "overwrite", []component.InputChoice{
+					{Label: "Overwrite an existing file", Value: "overwrite"},
+				}),
+			},
+		},
+		Modal: true,
+	})
+	if err != nil {
+		card.SetAlert(component.NewAlert(component.AlertTypeError, err.Error()))
+	}
+
+	promptSection := layout.AddSection()
+	promptSection.Add(card, component.WidthFull)
+	layout.AddButton("Dismiss save", action.Payload{
+		"action":  names.SavePrompt,
+		"imageID": "",
+	})
+}
+
+func pushHistoryPrinter(history []pushRecord) *component.Table {
+	table := component.NewTable("Recent Pushes", "No pushes yet",
+		component.NewTableCols("Image", "Destination", "Finished", "Result"))
+	for _, record := range history {
+		result := component.NewText("Pushed")
+		result.SetStatus(component.TextStatusOK)
+		if record.Err != nil {
+			result = component.NewText(record.Err.Error())
+			result.SetStatus(component.TextStatusError)
+		}
+		table.Add(component.TableRow{
+			"Image":       component.NewText(record.Source),
+			"Destination": component.NewText(record.Target),
+			"Finished":    component.NewTimestamp(record.Finished),
+			"Result":      result,
+		})
+	}
+	return table
+}
+
+// addPagedRows adds the table's current page of rows and, when there is more
+// than one page, the totals and buttons to move between pages. rows must
+// already be filtered and sorted so pages are taken from the full listing.
+func (i *imagePlugin) addPagedRows(layout *flexlayout.FlexLayout, table *component.Table, name string, rows []component.TableRow) {
+	pages := pageCount(len(rows))
+	if pages == 1 {
+		table.Add(rows...)
+		return
+	}
+
+	page := i.pages.Get(name, len(rows))
+	start := page * maxRows
+	end := start + maxRows
+	if end > len(rows) {
+		end = len(rows)
+	}
+	table.Add(rows[start:end]...)
+
+	pageSection := layout.AddSection()
+	pageSection.Add(component.NewTextf("Showing %d–%d of %d %s images (page %d of %d)",
+		start+1, end, len(rows), name, page+1, pages), component.WidthFull)
+	if page > 0 {
+		layout.AddButton(fmt.Sprintf("Previous %s page", name), action.Payload{
+			"action":    names.Page,
+			"table":     name,
+			"direction": "previous",
+		})
+	}
+	if page < pages-1 {
+		layout.AddButton(fmt.Sprintf("Next %s page", name), action.Payload{
+			"action":    names.Page,
+			"table":     name,
+			"direction": "next",
+		})
+	}
+}
+
+func (i *imagePlugin) addMissingToolsSection(layout *flexlayout.FlexLayout) {
+	missing := i.selfChecks.Missing()
+	if len(missing) == 0 {
+		return
+	}
+
+	text := component.NewTextf("Required tools not found on the PATH Octant was started from: %s. Install them; the page updates once they are found.",
+		strings.Join(missing, ", "))
+	text.SetStatus(component.TextStatusError)
+	missingSection := layout.AddSection()
+	missingSection.Add(text, component.WidthFull)
+}
+
+// addStatusSection shows the outcome of the last operation tracked by progress
+// while it is recent.
+func addStatusSection(layout *flexlayout.FlexLayout, progress *operationProgress) {
+	status, failed, ok := progress.Status()
+	if !ok {
+		return
+	}
+
+	text := component.NewText(status)
+	if failed {
+		text.SetStatus(component.TextStatusError)
+	} else {
+		text.SetStatus(component.TextStatusOK)
+	}
+	statusSection := layout.AddSection()
+	statusSection.Add(text, component.WidthFull)
+}
+
+func addDryRunSection(layout *flexlayout.FlexLayout) {
+	if !dryRun {
+		return
+	}
+
+	text := component.NewText("Dry run mode is on (KIND_REGISTRY_DRY_RUN): loads and deletes only report the command they would run.")
+	text.SetStatus(component.TextStatusWarning)
+	dryRunSection := layout.AddSection()
+	dryRunSection.Add(text, component.WidthFull)
+}
+
+// addSkippedSection notes under a table how many listing lines could not be
+// parsed, so images missing from it aren't taken for deleted.
+func addSkippedSection(layout *flexlayout.FlexLayout, skipped *parseError) {
+	if skipped == nil {
+		return
+	}
+	log.Printf("warning: %s", skipped)
+	text := component.NewTextf("%d of %d %s entries could not be parsed and are missing from the table, see the plugin logs.",
+		skipped.Failed, skipped.Total, host.Name())
+	text.SetStatus(component.TextStatusWarning)
+	skippedSection := layout.AddSection()
+	skippedSection.Add(text, component.WidthFull)
+}
+
+// addErrorSection shows err above the view. Parse errors come with partial
+// results, so they are shown as warnings rather than failures.
+func addErrorSection(layout *flexlayout.FlexLayout, err error) {
+	text := component.NewText(err.Error())
+	text.SetStatus(component.TextStatusError)
+	var parseErr *parseError
+	if errors.As(err, &parseErr) {
+		log.Printf("warning: %s", err)
+		text.SetStatus(component.TextStatusWarning)
+	}
+	errorSection := layout.AddSection()
+	errorSection.Add(text, component.WidthFull)
+}